@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/devblac/docker-lint/lint"
+)
+
+// ruleExplanation is the JSON representation of a rule in explain output.
+type ruleExplanation struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Severity        string          `json:"severity"`
+	Category        string          `json:"category,omitempty"`
+	Description     string          `json:"description"`
+	LongDescription string          `json:"long_description,omitempty"`
+	BadExample      string          `json:"bad_example,omitempty"`
+	GoodExample     string          `json:"good_example,omitempty"`
+	References      []string        `json:"references,omitempty"`
+	DocURL          string          `json:"doc_url,omitempty"`
+	Options         []ruleOptionDoc `json:"options,omitempty"`
+}
+
+// ruleOptionDoc is the JSON representation of a rule option in explain output.
+type ruleOptionDoc struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+// explainRules writes the documentation of the given rules to w. JSON output
+// is a single object for one rule and an array for several, like the lint
+// output for one or several files.
+func explainRules(w io.Writer, ids []string, opts lint.Options, jsonOutput bool) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("explain requires at least one rule ID")
+	}
+
+	explanations := make([]ruleExplanation, 0, len(ids))
+	for _, id := range ids {
+		rule := lint.DefaultRegistry().Get(strings.ToUpper(id))
+		if rule == nil {
+			return fmt.Errorf("unknown rule: %s", id)
+		}
+		explanations = append(explanations, explainRule(rule, opts))
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if len(explanations) == 1 {
+			return encoder.Encode(explanations[0])
+		}
+		return encoder.Encode(explanations)
+	}
+
+	for i, e := range explanations {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		writeExplanation(w, e)
+	}
+	return nil
+}
+
+// writeRuleCatalog writes the documentation of every default rule to w as a
+// JSON array, for generating the rule reference of the website.
+func writeRuleCatalog(w io.Writer, opts lint.Options) error {
+	all := lint.DefaultRegistry().All()
+	explanations := make([]ruleExplanation, 0, len(all))
+	for _, rule := range all {
+		explanations = append(explanations, explainRule(rule, opts))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(explanations)
+}
+
+// explainRule collects the documentation of a rule. Rules that do not
+// implement lint.Explainer only have their description; options are listed
+// for rules that implement lint.Configurable.
+func explainRule(rule lint.Rule, opts lint.Options) ruleExplanation {
+	e := ruleExplanation{
+		ID:          rule.ID(),
+		Name:        rule.Name(),
+		Severity:    opts.SeverityOf(rule).String(),
+		Category:    lint.CategoryOf(rule),
+		Description: rule.Description(),
+	}
+	if explainer, ok := rule.(lint.Explainer); ok {
+		e.LongDescription = explainer.LongDescription()
+		e.BadExample = explainer.BadExample()
+		e.GoodExample = explainer.GoodExample()
+		e.References = explainer.References()
+	}
+	if documented, ok := rule.(lint.DocumentedRule); ok {
+		e.DocURL = documented.DocURL()
+	}
+	if configurable, ok := rule.(lint.Configurable); ok {
+		for _, option := range configurable.Options() {
+			e.Options = append(e.Options, ruleOptionDoc{Key: option.Key, Description: option.Description})
+		}
+	}
+	return e
+}
+
+// writeExplanation writes the text form of a rule explanation.
+func writeExplanation(w io.Writer, e ruleExplanation) {
+	fmt.Fprintf(w, "%s: %s\n", e.ID, e.Name)
+	fmt.Fprintf(w, "Severity: %s\n", e.Severity)
+	if e.Category != "" {
+		fmt.Fprintf(w, "Category: %s\n", e.Category)
+	}
+	fmt.Fprintf(w, "\n%s\n", e.Description)
+	if e.LongDescription != "" {
+		fmt.Fprintf(w, "\n%s\n", e.LongDescription)
+	}
+	if e.BadExample != "" {
+		fmt.Fprintf(w, "\nBad:\n%s\n", indent(e.BadExample))
+	}
+	if e.GoodExample != "" {
+		fmt.Fprintf(w, "\nGood:\n%s\n", indent(e.GoodExample))
+	}
+	if len(e.References) > 0 {
+		fmt.Fprintf(w, "\nReferences:\n%s\n", indent(strings.Join(e.References, "\n")))
+	}
+	if e.DocURL != "" {
+		fmt.Fprintf(w, "\nDocumentation: %s\n", e.DocURL)
+	}
+	if len(e.Options) > 0 {
+		fmt.Fprintf(w, "\nOptions (--rule-option %s.<key>=<value>):\n", e.ID)
+		for _, option := range e.Options {
+			fmt.Fprintf(w, "    %s: %s\n", option.Key, option.Description)
+		}
+	}
+}
+
+// indent prefixes each non-empty line of s with four spaces.
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "    " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -2,17 +2,20 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"sort"
 	"strings"
 
-	"github.com/devblac/docker-lint/internal/analyzer"
-	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/baseline"
 	"github.com/devblac/docker-lint/internal/formatter"
 	"github.com/devblac/docker-lint/internal/parser"
-	"github.com/devblac/docker-lint/internal/rules"
+	"github.com/devblac/docker-lint/lint"
 )
 
 // version is set at build time using -ldflags. Defaults to "dev" when not set.
@@ -20,20 +23,45 @@ var version = "dev"
 
 func main() {
 	var (
-		jsonOutput bool
-		quiet      bool
-		strict     bool
-		versionFlg bool
-		rulesFlag  bool
-		ignoreCSV  string
+		jsonOutput    bool
+		quiet         bool
+		strict        bool
+		versionFlg    bool
+		rulesFlag     bool
+		verbose       bool
+		rulesJSON     bool
+		ignoreCSV     string
+		selectCSV     string
+		categoryCSV   string
+		severityCSV   string
+		format        string
+		recursive     bool
+		excludeDirs   stringList
+		fix           bool
+		showStage     bool
+		snippets      bool
+		color         bool
+		noColor       bool
+		summarize     bool
+		ruleOpts      = ruleOptions{}
+		minSevName    string
+		baselineFile  string
+		writeBaseline bool
+		outputFile    string
 	)
 
 	flag.BoolVar(&jsonOutput, "json", false, "Output findings as JSON")
 	flag.BoolVar(&jsonOutput, "j", false, "Output findings as JSON")
 
+	flag.StringVar(&format, "format", "text", "Output format: text, json, github, checkstyle, junit, codeclimate")
+	flag.StringVar(&format, "f", "text", "Output format: text, json, github, checkstyle, junit, codeclimate")
+
 	flag.BoolVar(&quiet, "quiet", false, "Suppress informational messages (show only warnings and errors)")
 	flag.BoolVar(&quiet, "q", false, "Suppress informational messages (show only warnings and errors)")
 
+	flag.StringVar(&minSevName, "min-severity", "info", "Only report findings at or above this severity: info, warning, error")
+	flag.StringVar(&minSevName, "m", "info", "Only report findings at or above this severity: info, warning, error")
+
 	flag.BoolVar(&strict, "strict", false, "Treat warnings as errors")
 	flag.BoolVar(&strict, "s", false, "Treat warnings as errors")
 
@@ -41,11 +69,45 @@ func main() {
 	flag.BoolVar(&versionFlg, "v", false, "Show version information")
 
 	flag.BoolVar(&rulesFlag, "rules", false, "List all available rules with descriptions")
+	flag.BoolVar(&verbose, "verbose", false, "Show long rule descriptions with --rules")
+	flag.BoolVar(&rulesJSON, "rules-json", false, "Print the documentation of all rules as JSON")
 
 	flag.StringVar(&ignoreCSV, "ignore", "", "Comma-separated list of rule IDs to ignore")
 
+	flag.StringVar(&selectCSV, "select", "", "Comma-separated list of rule IDs to run exclusively")
+	flag.StringVar(&selectCSV, "S", "", "Comma-separated list of rule IDs to run exclusively")
+
+	flag.StringVar(&categoryCSV, "category", "", "Comma-separated list of rule categories to run exclusively: "+strings.Join(lint.Categories, ", "))
+
+	flag.StringVar(&severityCSV, "severity", "", "Comma-separated list of RULE=severity overrides (e.g. DL5000=info)")
+
+	flag.Var(ruleOpts, "rule-option", "RULE.key=value option for a configurable rule (repeatable; see explain RULE)")
+
+	flag.BoolVar(&summarize, "summarize-unpinned", false, "Report unpinned base images (DL3006, DL3007) as one finding per file")
+
+	flag.StringVar(&baselineFile, "baseline", "", "Baseline file of known findings; only findings not in it are reported")
+	flag.BoolVar(&writeBaseline, "write-baseline", false, "Write the current findings to the --baseline file instead of reporting them")
+
+	flag.BoolVar(&fix, "fix", false, "Fix auto-fixable findings in place before reporting the remaining ones")
+
+	flag.BoolVar(&showStage, "show-stage", false, "Show the build stage of each finding in text output")
+
+	flag.BoolVar(&color, "color", false, "Always color text output, even when not writing to a terminal")
+	flag.BoolVar(&noColor, "no-color", false, "Never color text output (overrides --color)")
+
+	flag.BoolVar(&snippets, "snippets", false, "Include the source lines around each finding in JSON output")
+
+	flag.StringVar(&outputFile, "output", "", "Also write the findings as JSON to this file")
+
+	flag.BoolVar(&recursive, "recursive", false, "Search directories for Dockerfile, Dockerfile.* and *.dockerfile files")
+	flag.BoolVar(&recursive, "r", false, "Search directories for Dockerfile, Dockerfile.* and *.dockerfile files")
+	flag.BoolVar(&recursive, "R", false, "Search directories for Dockerfile, Dockerfile.* and *.dockerfile files")
+
+	flag.Var(&excludeDirs, "exclude-dir", "Directory name to skip in recursive mode (repeatable; .git, node_modules and vendor are always skipped)")
+
 	flag.Usage = func() {
-		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file|dir]...\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s [flags] explain RULE...\n", os.Args[0])
 		flag.PrintDefaults()
 	}
 
@@ -56,72 +118,501 @@ func main() {
 		return
 	}
 
+	minSeverity, err := lint.ParseSeverity(minSevName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	// --quiet is shorthand for --min-severity warning
+	if quiet && minSeverity < lint.SeverityWarning {
+		minSeverity = lint.SeverityWarning
+	}
+
+	categories := parseRuleList(categoryCSV)
+	for _, category := range categories {
+		if !isCategory(category) {
+			fmt.Fprintf(os.Stderr, "unknown category: %s\n", category)
+			os.Exit(2)
+		}
+	}
+
+	overrides, err := parseSeverityOverrides(severityCSV)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	opts := lint.Options{
+		IgnoreRules:       parseRuleList(ignoreCSV),
+		SelectRules:       parseRuleList(selectCSV),
+		SelectCategories:  categories,
+		MinSeverity:       minSeverity,
+		SeverityOverrides: overrides,
+		SummarizeUnpinned: summarize,
+		RuleOptions:       ruleOpts,
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	if rulesFlag {
-		listRules()
+		listRules(os.Stdout, opts, ignoreCSV != "" || selectCSV != "" || categoryCSV != "", verbose)
 		return
 	}
 
-	args := flag.Args()
-	if len(args) > 1 {
-		fmt.Fprintln(os.Stderr, "too many arguments: only one Dockerfile path is supported")
+	if rulesJSON {
+		if err := writeRuleCatalog(os.Stdout, opts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "explain" {
+		if err := explainRules(os.Stdout, flag.Args()[1:], opts, jsonOutput || strings.EqualFold(format, "json")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if writeBaseline && baselineFile == "" {
+		fmt.Fprintln(os.Stderr, "--write-baseline requires --baseline <file>")
+		os.Exit(2)
+	}
+
+	targets, err := collectPaths(flag.Args(), recursive, excludeDirs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
 
+	// A single path (or stdin) keeps the single-file output format; several
+	// paths, or any recursive search, produce one section per file.
+	multi := recursive || len(targets) > 1
+
 	filename := "stdin"
-	var reader io.Reader = os.Stdin
+	if len(targets) == 1 {
+		filename = targets[0].Path
+	}
 
-	if len(args) == 1 {
-		filename = args[0]
-		file, err := os.Open(filename)
+	if jsonOutput {
+		format = "json"
+	} else if !isFlagSet("format", "f") && isGitHubActions() {
+		format = "github"
+	}
+
+	outputFormatter, err := newFormatter(format, filename, quiet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if junit, ok := outputFormatter.(*formatter.JUnitFormatter); ok {
+		junit.RuleIDs = enabledRuleIDs(opts)
+	}
+	if codeClimate, ok := outputFormatter.(*formatter.CodeClimateFormatter); ok {
+		codeClimate.RuleCategories = ruleCategories()
+	}
+	if text, ok := outputFormatter.(*formatter.TextFormatter); ok {
+		text.ShowStage = showStage
+		text.Color = useColor(color, noColor, os.Stdout)
+	}
+
+	var results []formatter.FileResult
+	fatal := false
+
+	if fix && len(targets) == 0 && !recursive {
+		fmt.Fprintln(os.Stderr, "--fix requires file arguments")
+		os.Exit(2)
+	}
+
+	if len(targets) == 0 && !recursive {
+		content, err := io.ReadAll(os.Stdin)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to open file: %v\n", err)
+			fmt.Fprintf(os.Stderr, "failed to read stdin: %v\n", err)
 			os.Exit(2)
 		}
-		defer file.Close()
-		reader = file
+		findings, err := lint.Run(bytes.NewReader(content), opts)
+		if err != nil {
+			printError(os.Stderr, "", fmt.Errorf("failed to parse Dockerfile: %w", err))
+			os.Exit(2)
+		}
+		result := formatter.FileResult{Filename: filename, Findings: findings}
+		if snippets {
+			result.Source = sourceLines(content)
+		}
+		results = append(results, result)
 	}
 
-	dockerfile, err := parser.ParseReader(reader)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to parse Dockerfile: %v\n", err)
-		os.Exit(2)
+	for _, target := range targets {
+		path := target.Path
+		if fix {
+			result, err := fixFile(path, opts)
+			if err != nil {
+				printError(os.Stderr, path+": ", err)
+				fatal = true
+				continue
+			}
+			if result.Fixed > 0 {
+				fmt.Fprintf(os.Stderr, "%s: fixed %d finding(s)\n", path, result.Fixed)
+			}
+			for _, unfixable := range result.Unfixable {
+				fmt.Fprintf(os.Stderr, "%s: cannot fix %v\n", path, unfixable)
+			}
+		}
+
+		findings, err := lintTarget(target, fix, opts)
+		if err != nil {
+			prefix := ""
+			if multi {
+				prefix = path + ": "
+			}
+			printError(os.Stderr, prefix, err)
+			fatal = true
+			continue
+		}
+		result := formatter.FileResult{Filename: path, Findings: findings}
+		if snippets {
+			if content, err := os.ReadFile(path); err == nil {
+				result.Source = sourceLines(content)
+			}
+		}
+		results = append(results, result)
 	}
 
-	ignoreRules := parseIgnoreList(ignoreCSV)
+	if !multi && fatal {
+		os.Exit(2)
+	}
 
-	anlzr := analyzer.NewWithDefaults(analyzer.Config{IgnoreRules: ignoreRules})
-	findings := anlzr.Analyze(dockerfile)
+	if baselineFile != "" {
+		if err := applyBaseline(results, baselineFile, writeBaseline); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
 
 	var errorsCount, warningsCount int
-	for _, finding := range findings {
-		switch finding.Severity {
-		case ast.SeverityError:
-			errorsCount++
-		case ast.SeverityWarning:
-			warningsCount++
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			switch finding.Severity {
+			case lint.SeverityError:
+				errorsCount++
+			case lint.SeverityWarning:
+				warningsCount++
+			}
 		}
 	}
 
-	if jsonOutput {
-		jsonFormatter := formatter.NewJSONFormatter(filename, quiet)
-		if err := jsonFormatter.Format(findings, os.Stdout); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to format JSON output: %v\n", err)
+	// With --output, a JSON report is written to the file next to the
+	// usual output on stdout
+	outputs := []formatter.FormatterTarget{{Formatter: outputFormatter, Writer: os.Stdout}}
+	var report *os.File
+	if outputFile != "" {
+		report, err = os.Create(outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create output file: %v\n", err)
 			os.Exit(2)
 		}
+		outputs = append(outputs, formatter.FormatterTarget{Formatter: formatter.NewJSONFormatter(filename, quiet), Writer: report})
+	}
+	tee := formatter.NewTeeFormatterWithTargets(outputs)
+
+	if multi {
+		err = tee.FormatFiles(results, os.Stdout)
 	} else {
-		textFormatter := formatter.NewTextFormatter(filename, quiet)
-		if err := textFormatter.Format(findings, os.Stdout); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to format text output: %v\n", err)
-			os.Exit(2)
+		for _, output := range outputs {
+			if jf, ok := output.Formatter.(*formatter.JSONFormatter); ok {
+				jf.SetSource(results[0].Source)
+			}
 		}
+		err = tee.Format(results[0].Findings, os.Stdout)
+	}
+	if report != nil {
+		if closeErr := report.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to format %s output: %v\n", format, err)
+		os.Exit(2)
 	}
 
+	if fatal {
+		os.Exit(2)
+	}
 	if errorsCount > 0 || (strict && warningsCount > 0) {
 		os.Exit(1)
 	}
 }
 
-func parseIgnoreList(csv string) []string {
+// applyBaseline removes the findings recorded in the baseline file from
+// results. With write set, it first records the current findings in the file,
+// so none of them are reported.
+func applyBaseline(results []formatter.FileResult, path string, write bool) error {
+	var known *baseline.Baseline
+	if write {
+		known = baseline.New()
+		count := 0
+		for _, result := range results {
+			known.Add(result.Filename, result.Findings)
+			count += len(result.Findings)
+		}
+		if err := known.Write(path); err != nil {
+			return fmt.Errorf("failed to write baseline: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "wrote %d finding(s) to baseline %s\n", count, path)
+	} else {
+		var err error
+		known, err = baseline.Load(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("baseline %s does not exist; create it with --write-baseline", path)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load baseline: %w", err)
+		}
+	}
+
+	for i := range results {
+		results[i].Findings = known.Filter(results[i].Filename, results[i].Findings)
+	}
+	return nil
+}
+
+// printError writes an error to w after the given prefix. Each error of a
+// *parser.ParseErrors is written on its own line, after the same prefix and
+// the context the parse errors were wrapped in.
+func printError(w io.Writer, prefix string, err error) {
+	var parseErrs *parser.ParseErrors
+	if !errors.As(err, &parseErrs) {
+		fmt.Fprintf(w, "%s%v\n", prefix, err)
+		return
+	}
+	context := strings.TrimSuffix(err.Error(), parseErrs.Error())
+	for i := range parseErrs.Errors {
+		fmt.Fprintf(w, "%s%s%v\n", prefix, context, &parseErrs.Errors[i])
+	}
+}
+
+// lintFile opens and analyzes a single Dockerfile.
+func lintFile(path string, opts lint.Options) ([]lint.Finding, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	findings, err := lint.Run(file, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Dockerfile: %w", err)
+	}
+	return findings, nil
+}
+
+// lintTarget analyzes a Dockerfile found by collectPaths. Files parsed during
+// a directory search are not read again, unless --fix may have rewritten them.
+func lintTarget(target *parser.ParseResult, fixed bool, opts lint.Options) ([]lint.Finding, error) {
+	var parseErr *parser.ParseError
+	switch {
+	case fixed || (target.Dockerfile == nil && target.Error == nil):
+		return lintFile(target.Path, opts)
+	case errors.As(target.Error, &parseErr):
+		return nil, fmt.Errorf("failed to parse Dockerfile: %w", target.Error)
+	case target.Error != nil:
+		return nil, fmt.Errorf("failed to open file: %w", target.Error)
+	}
+	return lint.Analyze(target.Dockerfile, opts), nil
+}
+
+// fixFile applies the available fixes to a Dockerfile and rewrites it when
+// anything was fixed.
+func fixFile(path string, opts lint.Options) (*lint.FixResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	result, err := lint.Fix(bytes.NewReader(content), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Dockerfile: %w", err)
+	}
+	if result.Fixed == 0 {
+		return result, nil
+	}
+
+	if err := os.WriteFile(path, result.Source, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write fixed file: %w", err)
+	}
+	return result, nil
+}
+
+// collectPaths expands the command-line arguments into the Dockerfiles to
+// analyze. Files named on the command line are returned unparsed. Directories
+// are only accepted in recursive mode, where parser.ParseDirectory finds and
+// parses the Dockerfiles in them. With no arguments, recursive mode searches
+// the current directory. Directories named in excludeDirs are skipped in
+// addition to parser.DefaultIgnoreDirs.
+func collectPaths(args []string, recursive bool, excludeDirs []string) ([]*parser.ParseResult, error) {
+	if recursive && len(args) == 0 {
+		args = []string{"."}
+	}
+
+	dirOpts := parser.DirectoryOptions{
+		Recursive:  true,
+		IgnoreDirs: append(append([]string{}, parser.DefaultIgnoreDirs...), excludeDirs...),
+	}
+
+	var targets []*parser.ParseResult
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+
+		if !info.IsDir() {
+			targets = append(targets, &parser.ParseResult{Path: arg})
+			continue
+		}
+
+		if !recursive {
+			return nil, fmt.Errorf("%s is a directory (use --recursive to search it)", arg)
+		}
+
+		results, err := parser.ParseDirectory(arg, dirOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", arg, err)
+		}
+		targets = append(targets, results...)
+	}
+
+	if recursive && len(targets) == 0 {
+		return nil, fmt.Errorf("no Dockerfiles found")
+	}
+
+	return targets, nil
+}
+
+// sourceLines splits file content into lines for source snippets.
+func sourceLines(content []byte) []string {
+	text := strings.TrimSuffix(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	return strings.Split(text, "\n")
+}
+
+// stringList is a flag.Value that collects the values of a repeatable flag.
+// Each value may also be a comma-separated list.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, parseRuleList(value)...)
+	return nil
+}
+
+// ruleOptions is a flag.Value that collects RULE.key=value rule options.
+// Values are kept whole, so they may contain commas.
+type ruleOptions map[string]map[string]string
+
+func (o ruleOptions) String() string {
+	var options []string
+	for id, values := range o {
+		for key, value := range values {
+			options = append(options, id+"."+key+"="+value)
+		}
+	}
+	sort.Strings(options)
+	return strings.Join(options, " ")
+}
+
+func (o ruleOptions) Set(option string) error {
+	name, value, ok := strings.Cut(option, "=")
+	id, key, hasKey := strings.Cut(name, ".")
+	if !ok || !hasKey || id == "" || key == "" {
+		return fmt.Errorf("invalid rule option %q (expected RULE.key=value)", option)
+	}
+
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if o[id] == nil {
+		o[id] = make(map[string]string)
+	}
+	o[id][strings.TrimSpace(key)] = value
+	return nil
+}
+
+// newFormatter creates the output formatter for the given format name.
+func newFormatter(format, filename string, quiet bool) (formatter.Formatter, error) {
+	switch strings.ToLower(format) {
+	case "text":
+		return formatter.NewTextFormatter(filename, quiet), nil
+	case "json":
+		return formatter.NewJSONFormatter(filename, quiet), nil
+	case "github":
+		return formatter.NewGitHubActionsFormatter(filename, quiet), nil
+	case "checkstyle":
+		return formatter.NewCheckstyleFormatter(filename, quiet), nil
+	case "junit":
+		return formatter.NewJUnitFormatter(filename, quiet), nil
+	case "codeclimate":
+		return formatter.NewCodeClimateFormatter(filename, quiet), nil
+	default:
+		return nil, fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+// ruleCategories maps the IDs of the default rules to their categories.
+func ruleCategories() map[string]string {
+	categories := make(map[string]string)
+	for _, rule := range lint.DefaultRegistry().All() {
+		categories[rule.ID()] = lint.CategoryOf(rule)
+	}
+	return categories
+}
+
+// enabledRuleIDs returns the IDs of the default rules that run under opts.
+func enabledRuleIDs(opts lint.Options) []string {
+	var ids []string
+	for _, rule := range lint.DefaultRegistry().All() {
+		if opts.IsEnabled(rule.ID()) {
+			ids = append(ids, rule.ID())
+		}
+	}
+	return ids
+}
+
+// useColor decides whether text output to w is colored: --no-color always
+// disables color, --color forces it, and otherwise color is used only when w
+// is a terminal.
+func useColor(color, noColor bool, w io.Writer) bool {
+	if noColor {
+		return false
+	}
+	return color || formatter.IsTerminal(w)
+}
+
+// isFlagSet reports whether any of the named flags was given on the command line.
+func isFlagSet(names ...string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		for _, name := range names {
+			if f.Name == name {
+				set = true
+			}
+		}
+	})
+	return set
+}
+
+// isGitHubActions reports whether docker-lint is running inside GitHub Actions.
+func isGitHubActions() bool {
+	return os.Getenv("CI") == "true" && os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+func parseRuleList(csv string) []string {
 	if csv == "" {
 		return nil
 	}
@@ -137,8 +628,55 @@ func parseIgnoreList(csv string) []string {
 	return result
 }
 
-func listRules() {
-	for _, rule := range rules.DefaultRegistry.All() {
-		fmt.Printf("%s\t[%s]\t%s - %s\n", rule.ID(), rule.Severity().String(), rule.Name(), rule.Description())
+// listRules prints all available rules. When a select or ignore filter is in
+// effect, rules that would not run are marked as disabled. In verbose mode the
+// long description of each rule follows its line.
+func listRules(w io.Writer, opts lint.Options, filtered, verbose bool) {
+	for _, rule := range lint.DefaultRegistry().All() {
+		status := ""
+		if filtered && !opts.IsEnabled(rule.ID()) {
+			status = " (disabled)"
+		}
+		category := lint.CategoryOf(rule)
+		if category == "" {
+			category = "-"
+		}
+		fmt.Fprintf(w, "%s\t[%s]\t%s\t%s - %s%s\n", rule.ID(), opts.SeverityOf(rule).String(), category, rule.Name(), rule.Description(), status)
+		if explainer, ok := rule.(lint.Explainer); ok && verbose {
+			fmt.Fprintf(w, "%s\n\n", indent(explainer.LongDescription()))
+		}
+	}
+}
+
+// parseSeverityOverrides parses a comma-separated list of RULE=severity pairs.
+func parseSeverityOverrides(csv string) (map[string]lint.Severity, error) {
+	entries := parseRuleList(csv)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[string]lint.Severity, len(entries))
+	for _, entry := range entries {
+		ruleID, name, ok := strings.Cut(entry, "=")
+		ruleID = strings.TrimSpace(ruleID)
+		if !ok || ruleID == "" {
+			return nil, fmt.Errorf("invalid severity override %q: expected RULE=severity", entry)
+		}
+		severity, err := lint.ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity override %q: %w", entry, err)
+		}
+		overrides[ruleID] = severity
+	}
+	return overrides, nil
+}
+
+// isCategory reports whether name is a known rule category.
+func isCategory(name string) bool {
+	for _, category := range lint.Categories {
+		if category == name {
+			return true
+		}
 	}
+	return false
 }
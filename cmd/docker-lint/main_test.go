@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devblac/docker-lint/internal/formatter"
+	"github.com/devblac/docker-lint/lint"
+)
+
+// writeTree creates the given files, relative to root, with their contents.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCollectPaths_Recursive(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"Dockerfile":                  "FROM alpine:3.18\nUSER app\n",
+		"notes.txt":                   "not a Dockerfile\n",
+		"services/api/Dockerfile":     "FROM alpine:3.18\nCOPY --from=missing /app /app\n",
+		"services/legacy/Dockerfile":  "FROM alpine:3.18\n",
+		"services/web/Dockerfile.dev": "FROM node:20-alpine\n",
+		"services/web/app.dockerfile": "FROM node:20-alpine\n",
+		"node_modules/pkg/Dockerfile": "FROM alpine:3.18\n",
+		"services/broken/Dockerfile":  "FROM alpine:3.18\nINVALID instruction\n",
+	})
+
+	tests := []struct {
+		name        string
+		excludeDirs []string
+		want        []string
+	}{
+		{
+			name: "default ignore dirs",
+			want: []string{
+				"Dockerfile",
+				"services/api/Dockerfile",
+				"services/broken/Dockerfile",
+				"services/legacy/Dockerfile",
+				"services/web/Dockerfile.dev",
+				"services/web/app.dockerfile",
+			},
+		},
+		{
+			name:        "exclude dirs",
+			excludeDirs: []string{"legacy", "broken"},
+			want: []string{
+				"Dockerfile",
+				"services/api/Dockerfile",
+				"services/web/Dockerfile.dev",
+				"services/web/app.dockerfile",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := collectPaths([]string{root}, true, tt.excludeDirs)
+			if err != nil {
+				t.Fatalf("collectPaths() error = %v", err)
+			}
+
+			var got []string
+			for _, target := range targets {
+				rel, err := filepath.Rel(root, target.Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("options apply to every file", func(t *testing.T) {
+		targets, err := collectPaths([]string{root}, true, []string{"legacy"})
+		if err != nil {
+			t.Fatalf("collectPaths() error = %v", err)
+		}
+
+		opts := lint.Options{MinSeverity: lint.SeverityError}
+		errorFiles := 0
+		for _, target := range targets {
+			findings, err := lintTarget(target, false, opts)
+			if strings.HasSuffix(filepath.ToSlash(target.Path), "services/broken/Dockerfile") {
+				if err == nil || !strings.Contains(err.Error(), "failed to parse Dockerfile") {
+					t.Errorf("%s: error = %v, want a parse error", target.Path, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: lintTarget() error = %v", target.Path, err)
+				continue
+			}
+			for _, f := range findings {
+				if f.Severity < lint.SeverityError {
+					t.Errorf("%s: finding %s below --min-severity", target.Path, f.RuleID)
+				}
+			}
+			if len(findings) > 0 {
+				errorFiles++
+			}
+		}
+		// Only services/api/Dockerfile has an error (DL3027)
+		if errorFiles != 1 {
+			t.Errorf("files with errors = %d, want 1", errorFiles)
+		}
+	})
+}
+
+func TestCollectPaths_Errors(t *testing.T) {
+	root := t.TempDir()
+
+	if _, err := collectPaths([]string{root}, false, nil); err == nil || !strings.Contains(err.Error(), "--recursive") {
+		t.Errorf("directory without --recursive: error = %v", err)
+	}
+	if _, err := collectPaths([]string{root}, true, nil); err == nil || !strings.Contains(err.Error(), "no Dockerfiles found") {
+		t.Errorf("empty directory: error = %v", err)
+	}
+	if _, err := collectPaths([]string{filepath.Join(root, "missing")}, false, nil); err == nil {
+		t.Error("missing file: expected error")
+	}
+
+	path := filepath.Join(root, "Dockerfile")
+	writeTree(t, root, map[string]string{"Dockerfile": "FROM alpine:3.18\n"})
+	targets, err := collectPaths([]string{path}, false, nil)
+	if err != nil {
+		t.Fatalf("collectPaths() error = %v", err)
+	}
+	if len(targets) != 1 || targets[0].Path != path || targets[0].Dockerfile != nil {
+		t.Errorf("file argument: targets = %+v, want one unparsed target for %s", targets, path)
+	}
+}
+
+func TestStringList(t *testing.T) {
+	var list stringList
+	for _, value := range []string{"legacy", "build, tmp", ""} {
+		if err := list.Set(value); err != nil {
+			t.Fatalf("Set(%q) error = %v", value, err)
+		}
+	}
+
+	want := stringList{"legacy", "build", "tmp"}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("list = %v, want %v", list, want)
+	}
+	if got := list.String(); got != "legacy,build,tmp" {
+		t.Errorf("String() = %q, want %q", got, "legacy,build,tmp")
+	}
+}
+
+func TestExplainRules(t *testing.T) {
+	opts := lint.Options{}
+
+	t.Run("text", func(t *testing.T) {
+		var buf strings.Builder
+		if err := explainRules(&buf, []string{"dl3009", "DL4008"}, opts, false); err != nil {
+			t.Fatalf("explainRules() error = %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{"DL3009: Package manager cache not cleaned", "DL4008: ", "Bad:\n    FROM", "Good:\n    FROM", "References:\n    https://"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output does not contain %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("json single rule", func(t *testing.T) {
+		var buf strings.Builder
+		if err := explainRules(&buf, []string{"DL3009"}, opts, true); err != nil {
+			t.Fatalf("explainRules() error = %v", err)
+		}
+		var got ruleExplanation
+		if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+			t.Fatalf("invalid JSON object: %v", err)
+		}
+		if got.ID != "DL3009" || got.Category != lint.CategoryPerformance || got.BadExample == "" || len(got.References) == 0 {
+			t.Errorf("unexpected explanation: %+v", got)
+		}
+	})
+
+	t.Run("json several rules", func(t *testing.T) {
+		var buf strings.Builder
+		if err := explainRules(&buf, []string{"DL3009", "DL3010"}, lint.Options{SeverityOverrides: map[string]lint.Severity{"DL3010": lint.SeverityError}}, true); err != nil {
+			t.Fatalf("explainRules() error = %v", err)
+		}
+		var got []ruleExplanation
+		if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+			t.Fatalf("invalid JSON array: %v", err)
+		}
+		if len(got) != 2 || got[1].ID != "DL3010" || got[1].Severity != "error" {
+			t.Errorf("unexpected explanations: %+v", got)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		var buf strings.Builder
+		if err := explainRules(&buf, []string{"DL3008"}, opts, false); err != nil {
+			t.Fatalf("explainRules() error = %v", err)
+		}
+		if want := "Options (--rule-option DL3008.<key>=<value>):\n    large_images: "; !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, buf.String())
+		}
+
+		buf.Reset()
+		if err := explainRules(&buf, []string{"DL3008"}, opts, true); err != nil {
+			t.Fatalf("explainRules() error = %v", err)
+		}
+		var got ruleExplanation
+		if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+			t.Fatalf("invalid JSON object: %v", err)
+		}
+		if len(got.Options) != 1 || got.Options[0].Key != "large_images" || got.Options[0].Description == "" {
+			t.Errorf("unexpected options: %+v", got.Options)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := explainRules(io.Discard, nil, opts, false); err == nil {
+			t.Error("no rule IDs: expected error")
+		}
+		if err := explainRules(io.Discard, []string{"DL3009", "XX9999"}, opts, false); err == nil || !strings.Contains(err.Error(), "XX9999") {
+			t.Errorf("unknown rule: error = %v", err)
+		}
+	})
+}
+
+func TestWriteRuleCatalog(t *testing.T) {
+	var buf strings.Builder
+	if err := writeRuleCatalog(&buf, lint.Options{}); err != nil {
+		t.Fatalf("writeRuleCatalog() error = %v", err)
+	}
+	var catalog []ruleExplanation
+	if err := json.Unmarshal([]byte(buf.String()), &catalog); err != nil {
+		t.Fatalf("invalid JSON array: %v", err)
+	}
+
+	byID := make(map[string]ruleExplanation, len(catalog))
+	for _, e := range catalog {
+		byID[e.ID] = e
+	}
+	for _, rule := range lint.DefaultRegistry().All() {
+		if _, ok := byID[rule.ID()]; !ok {
+			t.Errorf("catalog is missing %s", rule.ID())
+		}
+	}
+	if len(catalog) != len(lint.DefaultRegistry().All()) {
+		t.Errorf("catalog has %d rules, want %d", len(catalog), len(lint.DefaultRegistry().All()))
+	}
+
+	if e := byID["DL3006"]; e.DocURL != "https://github.com/hadolint/hadolint/wiki/DL3006" || e.LongDescription == "" || e.GoodExample == "" {
+		t.Errorf("unexpected DL3006 entry: %+v", e)
+	}
+	if e := byID["DL3010"]; e.DocURL != "" {
+		t.Errorf("DL3010 doc_url = %q, want none", e.DocURL)
+	}
+}
+
+func TestListRules_Verbose(t *testing.T) {
+	var plain, verbose strings.Builder
+	listRules(&plain, lint.Options{}, false, false)
+	listRules(&verbose, lint.Options{}, false, true)
+
+	rule := lint.DefaultRegistry().Get("DL3009").(lint.Explainer)
+	if strings.Contains(plain.String(), rule.LongDescription()[:40]) {
+		t.Error("long description shown without --verbose")
+	}
+	if !strings.Contains(verbose.String(), "    "+rule.LongDescription()[:40]) {
+		t.Error("long description missing with --verbose")
+	}
+}
+
+func TestSourceLines(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"FROM alpine:3.18\nUSER app\n", []string{"FROM alpine:3.18", "USER app"}},
+		{"FROM alpine:3.18\r\nUSER app", []string{"FROM alpine:3.18", "USER app"}},
+		{"FROM alpine:3.18\n\nUSER app\n", []string{"FROM alpine:3.18", "", "USER app"}},
+	}
+
+	for _, tt := range tests {
+		if got := sourceLines([]byte(tt.content)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sourceLines(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+	writeTree(t, filepath.Dir(path), map[string]string{"Dockerfile": "FROM alpine:3.18\nINVALID x\nRUN echo hi\nCOPY .\n"})
+
+	_, err := lintFile(path, lint.Options{})
+	if err == nil {
+		t.Fatal("lintFile() error = nil, want parse errors")
+	}
+
+	var out bytes.Buffer
+	printError(&out, "Dockerfile: ", err)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("printError() wrote %q, want one line per parse error", out.String())
+	}
+	for i, want := range []string{"Dockerfile: failed to parse Dockerfile: line 2: ", "Dockerfile: failed to parse Dockerfile: line 4: "} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d = %q, want prefix %q", i+1, lines[i], want)
+		}
+	}
+
+	out.Reset()
+	printError(&out, "", os.ErrNotExist)
+	if out.String() != "file does not exist\n" {
+		t.Errorf("printError() = %q, want the error on one line", out.String())
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		color   bool
+		noColor bool
+		want    bool
+	}{
+		{"not a terminal", false, false, false},
+		{"--color", true, false, true},
+		{"--no-color", false, true, false},
+		{"--no-color overrides --color", true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := useColor(tt.color, tt.noColor, &bytes.Buffer{}); got != tt.want {
+				t.Errorf("useColor(%v, %v) = %v, want %v", tt.color, tt.noColor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleOptions(t *testing.T) {
+	options := ruleOptions{}
+	for _, value := range []string{"dl3008.large_images=ubuntu,corp-base", "DL4000.additional_patterns=(?i)x{1,2}=y"} {
+		if err := options.Set(value); err != nil {
+			t.Fatalf("Set(%q) error = %v", value, err)
+		}
+	}
+
+	want := ruleOptions{
+		"DL3008": {"large_images": "ubuntu,corp-base"},
+		"DL4000": {"additional_patterns": "(?i)x{1,2}=y"},
+	}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("options = %v, want %v", options, want)
+	}
+
+	for _, value := range []string{"DL3008", "DL3008=ubuntu", ".large_images=ubuntu", "DL3008.=ubuntu"} {
+		if err := (ruleOptions{}).Set(value); err == nil {
+			t.Errorf("Set(%q): expected error", value)
+		}
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	results := func(findings ...lint.Finding) []formatter.FileResult {
+		return []formatter.FileResult{{Filename: "Dockerfile", Findings: findings}}
+	}
+	latest := lint.Finding{RuleID: "DL3007", Severity: lint.SeverityWarning, Line: 1, Column: 13, Message: "Using 'latest' tag for image 'ubuntu' is not recommended"}
+	sudo := lint.Finding{RuleID: "DL4005", Severity: lint.SeverityWarning, Line: 3, Column: 1, Message: "sudo used in RUN instruction, which already runs as root"}
+
+	if err := applyBaseline(results(latest), path, false); err == nil || !strings.Contains(err.Error(), "--write-baseline") {
+		t.Errorf("applyBaseline() with a missing file error = %v, want a hint to use --write-baseline", err)
+	}
+
+	written := results(latest)
+	if err := applyBaseline(written, path, true); err != nil {
+		t.Fatalf("applyBaseline(write) error = %v", err)
+	}
+	if len(written[0].Findings) != 0 {
+		t.Errorf("findings after writing the baseline = %v, want none", written[0].Findings)
+	}
+
+	// The known finding moved down a line and a new one appeared
+	moved := latest
+	moved.Line = 2
+	later := results(moved, sudo)
+	if err := applyBaseline(later, path, false); err != nil {
+		t.Fatalf("applyBaseline() error = %v", err)
+	}
+	if !reflect.DeepEqual(later[0].Findings, []lint.Finding{sudo}) {
+		t.Errorf("findings = %v, want only the new DL4005 finding", later[0].Findings)
+	}
+}
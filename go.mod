@@ -2,4 +2,4 @@ module github.com/devblac/docker-lint
 
 go 1.22.0
 
-require github.com/leanovate/gopter v0.2.11 // indirect
+require github.com/leanovate/gopter v0.2.11
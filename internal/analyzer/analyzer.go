@@ -2,7 +2,11 @@
 package analyzer
 
 import (
+	"runtime"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/devblac/docker-lint/internal/ast"
 	"github.com/devblac/docker-lint/internal/rules"
@@ -12,16 +16,66 @@ import (
 type Config struct {
 	// IgnoreRules is a list of rule IDs to skip during analysis.
 	IgnoreRules []string
+
+	// SelectRules is a list of rule IDs to run exclusively. When set, all other
+	// rules are skipped and IgnoreRules is applied within this subset.
+	SelectRules []string
+
+	// SelectCategories is a list of rule categories (see rules.Categories) to
+	// run exclusively. It combines with SelectRules: a rule must satisfy both.
+	SelectCategories []string
+
+	// MinSeverity is the lowest severity reported. Findings below it are
+	// dropped; the zero value (SeverityInfo) reports everything.
+	MinSeverity ast.Severity
+
+	// SeverityOverrides maps rule IDs to the severity their findings are
+	// reported with, replacing the rule's default Severity(). Overrides are
+	// applied before MinSeverity filtering.
+	SeverityOverrides map[string]ast.Severity
+
+	// Workers is the number of rules run concurrently. Zero uses runtime.NumCPU();
+	// one runs the rules sequentially.
+	Workers int
+
+	// SummarizeUnpinned replaces the DL3006 and DL3007 findings of a
+	// Dockerfile with a single finding listing the lines of all unpinned base
+	// images, when there is more than one.
+	SummarizeUnpinned bool
+
+	// RuleOptions configures rules that implement rules.Configurable, keyed
+	// by rule ID and then option key. Options that Validate rejects are
+	// ignored by the analyzer.
+	RuleOptions map[string]map[string]string
+}
+
+// Validate checks RuleOptions against the rules in registry. It reports
+// options for unknown rules, for rules without options and invalid values.
+func (c Config) Validate(registry *rules.RuleRegistry) error {
+	if len(c.RuleOptions) == 0 {
+		return nil
+	}
+	_, err := registry.Configure(c.RuleOptions)
+	return err
 }
 
 // Analyzer orchestrates the execution of lint rules against a Dockerfile AST.
 type Analyzer struct {
 	registry *rules.RuleRegistry
 	config   Config
+
+	// cache holds the findings of line-local rules for AnalyzeIncremental.
+	cache incrementalCache
 }
 
 // New creates a new Analyzer with the given registry and configuration.
+// Rules with RuleOptions are replaced by configured copies; the registry
+// itself is not modified.
 func New(registry *rules.RuleRegistry, config Config) *Analyzer {
+	if len(config.RuleOptions) > 0 {
+		// Invalid options are reported by Config.Validate
+		registry, _ = registry.Configure(config.RuleOptions)
+	}
 	return &Analyzer{
 		registry: registry,
 		config:   config,
@@ -40,35 +94,89 @@ func (a *Analyzer) Analyze(dockerfile *ast.Dockerfile) []ast.Finding {
 		return nil
 	}
 
-	// Build a set of globally ignored rules for fast lookup
-	ignoredRules := make(map[string]bool)
-	for _, ruleID := range a.config.IgnoreRules {
-		ignoredRules[ruleID] = true
+	return a.run(dockerfile, a.enabledRules())
+}
+
+// enabledRules returns the registered rules that the select/ignore
+// configuration enables, in registry order.
+func (a *Analyzer) enabledRules() []rules.Rule {
+	var enabled []rules.Rule
+	for _, rule := range a.registry.All() {
+		if a.IsEnabled(rule.ID()) {
+			enabled = append(enabled, rule)
+		}
 	}
+	return enabled
+}
 
-	var allFindings []ast.Finding
+// run executes the given rules against the Dockerfile, spreading them across
+// the configured number of workers. Findings are merged in rule order, filtered
+// by inline ignores and sorted, so the result does not depend on scheduling.
+func (a *Analyzer) run(dockerfile *ast.Dockerfile, ruleList []rules.Rule) []ast.Finding {
+	return a.collect(dockerfile, ruleList, a.check(dockerfile, ruleList))
+}
 
-	// Run each registered rule
-	for _, rule := range a.registry.All() {
-		// Skip globally ignored rules
-		if ignoredRules[rule.ID()] {
-			continue
+// check runs the given rules against the Dockerfile concurrently and returns
+// the findings of each rule, indexed like ruleList.
+func (a *Analyzer) check(dockerfile *ast.Dockerfile, ruleList []rules.Rule) [][]ast.Finding {
+	results := make([][]ast.Finding, len(ruleList))
+
+	workers := a.workers()
+	if workers > len(ruleList) {
+		workers = len(ruleList)
+	}
+
+	if workers <= 1 {
+		for i, rule := range ruleList {
+			results[i] = rule.Check(dockerfile)
 		}
+	} else {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
 
-		// Execute the rule
-		findings := rule.Check(dockerfile)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					results[i] = ruleList[i].Check(dockerfile)
+				}
+			}()
+		}
 
-		// Filter findings based on inline ignores
+		for i := range ruleList {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	return results
+}
+
+// collect merges the findings of each rule in ruleList, given by results, in
+// rule order. It applies severity overrides and inline ignores, sets the
+// category and stage of each finding and sorts them.
+func (a *Analyzer) collect(dockerfile *ast.Dockerfile, ruleList []rules.Rule, results [][]ast.Finding) []ast.Finding {
+	var allFindings []ast.Finding
+	for i, findings := range results {
+		category := rules.CategoryOf(ruleList[i])
+		fixer, _ := ruleList[i].(rules.Fixer)
 		for _, finding := range findings {
-			if a.isIgnoredByInlineComment(dockerfile, finding) {
-				continue
+			if finding, ok := a.report(dockerfile, finding); ok {
+				finding.RuleCategory = category
+				finding.FixAvailable = canFix(fixer, finding, dockerfile)
+				allFindings = append(allFindings, attributeStage(dockerfile, finding))
 			}
-			allFindings = append(allFindings, finding)
 		}
 	}
 
+	if a.config.SummarizeUnpinned {
+		allFindings = summarizeUnpinned(allFindings)
+	}
+
 	// Sort findings by line number, then by rule ID for deterministic output
-	sort.Slice(allFindings, func(i, j int) bool {
+	sort.SliceStable(allFindings, func(i, j int) bool {
 		if allFindings[i].Line != allFindings[j].Line {
 			return allFindings[i].Line < allFindings[j].Line
 		}
@@ -78,9 +186,167 @@ func (a *Analyzer) Analyze(dockerfile *ast.Dockerfile) []ast.Finding {
 	return allFindings
 }
 
+// canFix reports whether fixer, if not nil, returns edits for the finding. A
+// Fixer may decline a finding with an error, as DL3006 does to ask for a tag.
+func canFix(fixer rules.Fixer, finding ast.Finding, dockerfile *ast.Dockerfile) bool {
+	if fixer == nil {
+		return false
+	}
+	fixes, err := fixer.Fix(finding, dockerfile)
+	return err == nil && len(fixes) > 0
+}
+
+// summarizeUnpinned replaces two or more unpinned base image findings (DL3006
+// and DL3007) with one finding on the line of the first, reported under its
+// rule ID with the highest severity among them.
+func summarizeUnpinned(findings []ast.Finding) []ast.Finding {
+	var unpinned, rest []ast.Finding
+	for _, finding := range findings {
+		if finding.RuleID == rules.RuleMissingTag || finding.RuleID == rules.RuleLatestTag {
+			unpinned = append(unpinned, finding)
+		} else {
+			rest = append(rest, finding)
+		}
+	}
+	if len(unpinned) < 2 {
+		return findings
+	}
+
+	sort.SliceStable(unpinned, func(i, j int) bool { return unpinned[i].Line < unpinned[j].Line })
+
+	summary := ast.Finding{
+		RuleID:       unpinned[0].RuleID,
+		Line:         unpinned[0].Line,
+		Column:       1,
+		StageIndex:   -1,
+		Suggestion:   "Pin each base image to a specific version tag or digest for reproducible builds",
+		RuleCategory: unpinned[0].RuleCategory,
+	}
+	lines := make([]string, len(unpinned))
+	for i, finding := range unpinned {
+		if finding.Severity > summary.Severity {
+			summary.Severity = finding.Severity
+		}
+		lines[i] = strconv.Itoa(finding.Line)
+	}
+	summary.Message = strconv.Itoa(len(unpinned)) + " images unpinned, on lines " + strings.Join(lines, ", ")
+
+	return append(rest, summary)
+}
+
+// report applies severity overrides to a finding and reports whether it passes
+// the severity and inline ignore filters.
+func (a *Analyzer) report(dockerfile *ast.Dockerfile, finding ast.Finding) (ast.Finding, bool) {
+	if severity, ok := a.config.SeverityOverrides[finding.RuleID]; ok {
+		finding.Severity = severity
+	}
+	if finding.Severity < a.config.MinSeverity {
+		return finding, false
+	}
+	if a.isIgnoredByInlineComment(dockerfile, finding) {
+		return finding, false
+	}
+	return finding, true
+}
+
+// attributeStage sets the stage of a finding whose rule left it unset, based on
+// the line ranges of the Dockerfile's stages.
+func attributeStage(dockerfile *ast.Dockerfile, finding ast.Finding) ast.Finding {
+	if finding.StageIndex != 0 || finding.StageName != "" {
+		return finding
+	}
+
+	if stage := dockerfile.StageAt(finding.Line); stage != nil {
+		finding.StageIndex = stage.Index
+		finding.StageName = stage.Name
+	} else {
+		finding.StageIndex = -1
+	}
+	return finding
+}
+
+// Fix applies the fixes of enabled rules that implement rules.Fixer to
+// source, the text dockerfile was parsed from, and returns the patched source.
+// Findings that Analyze would not report are left alone.
+func (a *Analyzer) Fix(source []byte, dockerfile *ast.Dockerfile) *rules.FixResult {
+	if dockerfile == nil {
+		return &rules.FixResult{Source: source}
+	}
+
+	fixers := ruleFixers{}
+	var findings []ast.Finding
+	for _, rule := range a.registry.All() {
+		fixer, ok := rule.(rules.Fixer)
+		if !ok || !a.IsEnabled(rule.ID()) {
+			continue
+		}
+
+		fixers[rule.ID()] = fixer
+		for _, finding := range rule.Check(dockerfile) {
+			if _, ok := a.report(dockerfile, finding); ok {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return rules.NewAutoFixer(fixers).Apply(source, dockerfile, findings)
+}
+
+// ruleFixers dispatches each finding to the Fixer of the rule that reported it.
+type ruleFixers map[string]rules.Fixer
+
+func (f ruleFixers) Fix(finding ast.Finding, dockerfile *ast.Dockerfile) ([]rules.Fix, error) {
+	fixer, ok := f[finding.RuleID]
+	if !ok {
+		return nil, nil
+	}
+	return fixer.Fix(finding, dockerfile)
+}
+
+// workers returns the number of concurrent workers to use for rule execution.
+func (a *Analyzer) workers() int {
+	if a.config.Workers > 0 {
+		return a.config.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// IsEnabled reports whether a rule would run under the current configuration.
+// SelectRules and SelectCategories, when set, restrict analysis to the listed
+// rules and categories; IgnoreRules is then applied within that subset.
+func (a *Analyzer) IsEnabled(ruleID string) bool {
+	if len(a.config.SelectRules) > 0 && !containsRule(a.config.SelectRules, ruleID) {
+		return false
+	}
+	if len(a.config.SelectCategories) > 0 {
+		rule := a.registry.Get(ruleID)
+		if rule == nil || !containsRule(a.config.SelectCategories, rules.CategoryOf(rule)) {
+			return false
+		}
+	}
+	return !containsRule(a.config.IgnoreRules, ruleID)
+}
+
+// containsRule checks if a rule ID is present in the given list.
+func containsRule(ruleIDs []string, ruleID string) bool {
+	for _, id := range ruleIDs {
+		if id == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
 // isIgnoredByInlineComment checks if a finding should be ignored based on inline comments.
-// Inline ignore comments apply to the line immediately following the comment.
+// Inline ignore comments apply to the line immediately following the comment,
+// and ignore ranges to the lines from their ignore-start to their ignore-end.
 func (a *Analyzer) isIgnoredByInlineComment(dockerfile *ast.Dockerfile, finding ast.Finding) bool {
+	for _, r := range dockerfile.IgnoreRanges {
+		if r.Contains(finding.Line, finding.RuleID) {
+			return true
+		}
+	}
+
 	if dockerfile.InlineIgnores == nil {
 		return false
 	}
@@ -108,19 +374,13 @@ func (a *Analyzer) AnalyzeWithRules(dockerfile *ast.Dockerfile, ruleIDs []string
 		return nil
 	}
 
-	// Build a set of globally ignored rules for fast lookup
-	ignoredRules := make(map[string]bool)
-	for _, ruleID := range a.config.IgnoreRules {
-		ignoredRules[ruleID] = true
-	}
-
 	// Build a set of requested rules
 	requestedRules := make(map[string]bool)
 	for _, ruleID := range ruleIDs {
 		requestedRules[ruleID] = true
 	}
 
-	var allFindings []ast.Finding
+	var selected []rules.Rule
 
 	// Run only the requested rules
 	for _, rule := range a.registry.All() {
@@ -129,32 +389,15 @@ func (a *Analyzer) AnalyzeWithRules(dockerfile *ast.Dockerfile, ruleIDs []string
 			continue
 		}
 
-		// Skip globally ignored rules
-		if ignoredRules[rule.ID()] {
+		// Skip rules disabled by the select/ignore configuration
+		if !a.IsEnabled(rule.ID()) {
 			continue
 		}
 
-		// Execute the rule
-		findings := rule.Check(dockerfile)
-
-		// Filter findings based on inline ignores
-		for _, finding := range findings {
-			if a.isIgnoredByInlineComment(dockerfile, finding) {
-				continue
-			}
-			allFindings = append(allFindings, finding)
-		}
+		selected = append(selected, rule)
 	}
 
-	// Sort findings by line number, then by rule ID for deterministic output
-	sort.Slice(allFindings, func(i, j int) bool {
-		if allFindings[i].Line != allFindings[j].Line {
-			return allFindings[i].Line < allFindings[j].Line
-		}
-		return allFindings[i].RuleID < allFindings[j].RuleID
-	})
-
-	return allFindings
+	return a.run(dockerfile, selected)
 }
 
 // Registry returns the rule registry used by this analyzer.
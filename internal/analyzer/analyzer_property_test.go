@@ -697,6 +697,7 @@ func genNonEmptyIgnoreRules() gopter.Gen {
 		rules.RuleAddOverCopy,
 		rules.RuleMissingHealthcheck,
 		rules.RuleWildcardCopy,
+		rules.RuleSudoInRun,
 	}
 
 	return gen.IntRange(1, 5).FlatMap(func(n interface{}) gopter.Gen {
@@ -706,7 +707,7 @@ func genNonEmptyIgnoreRules() gopter.Gen {
 			allRules[4], allRules[5], allRules[6], allRules[7],
 			allRules[8], allRules[9], allRules[10], allRules[11],
 			allRules[12], allRules[13], allRules[14], allRules[15],
-			allRules[16],
+			allRules[16], allRules[17],
 		)).Map(func(rules []string) []string {
 			// Deduplicate
 			seen := make(map[string]bool)
@@ -854,13 +855,71 @@ func genLineIgnorePair() gopter.Gen {
 	}, reflect.TypeOf(lineIgnorePair{}))
 }
 
+// **Feature: docker-lint, Property 11: Ignore Range Effectiveness**
+//
+// Property: For any Dockerfile with an ignore range for rule R from line S to
+// line E, the findings SHALL NOT contain a finding for rule R on any line from
+// S to E.
+func TestIgnoreRangeEffectiveness(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.MaxSize = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("ignore ranges suppress findings for specified rules on every line in the range", prop.ForAll(
+		func(df *ast.Dockerfile, ranges []ast.IgnoreRange) bool {
+			df.IgnoreRanges = ranges
+
+			findings := NewWithDefaults(Config{}).Analyze(df)
+
+			for _, finding := range findings {
+				for _, r := range ranges {
+					if finding.Line < r.Start || finding.Line > r.End {
+						continue
+					}
+					for _, ruleID := range r.RuleIDs {
+						if finding.RuleID == ruleID {
+							t.Logf("Found finding for rule %s on line %d, but it should be ignored by range %d-%d",
+								finding.RuleID, finding.Line, r.Start, r.End)
+							return false
+						}
+					}
+				}
+			}
+
+			return true
+		},
+		genDockerfileWithIssues(),
+		gen.SliceOfN(3, genIgnoreRange()),
+	))
+
+	properties.TestingRun(t)
+}
+
+// genIgnoreRange generates a range of lines 1-10 and the rule IDs it ignores.
+func genIgnoreRange() gopter.Gen {
+	return gopter.CombineGens(
+		gen.IntRange(1, 10),
+		gen.IntRange(0, 5),
+		genLineIgnorePair(),
+	).Map(func(vals []interface{}) ast.IgnoreRange {
+		start := vals[0].(int)
+		return ast.IgnoreRange{
+			Start:   start,
+			End:     start + vals[1].(int),
+			RuleIDs: vals[2].(lineIgnorePair).ruleIDs,
+		}
+	})
+}
+
 // **Feature: docker-lint, Property 8: Multi-stage Stage Isolation**
 // **Validates: Requirements 1.3**
 //
 // Property: For any multi-stage Dockerfile, rules that apply to stage-specific context
 // (like missing USER) SHALL be evaluated per-stage, not globally.
-// Specifically: if one stage has a USER instruction and another doesn't, we should get
-// a finding only for the stage without USER, not for the entire Dockerfile.
+// Specifically: DL4002 reports each stage without USER whose layers are shipped (the
+// final stage and the stages it is built FROM), and never reports builder-only stages.
 func TestMultiStageStageIsolation(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 100
@@ -884,11 +943,26 @@ func TestMultiStageStageIsolation(t *testing.T) {
 			// Run analysis with only the NoUser rule
 			findings := analyzer.AnalyzeWithRules(df, []string{rules.RuleNoUser})
 
-			// Count stages with and without USER instructions
+			// The final stage is shipped, and so is any stage it is built FROM
+			final, _ := df.StageByIndex(len(df.Stages) - 1)
+			shipped := map[int]bool{final.Index: true}
+			for stage := final; ; {
+				parent, ok := df.StageByName(stage.FromInstr.Image)
+				if !ok || parent.Index >= stage.Index {
+					break
+				}
+				shipped[parent.Index] = true
+				stage = parent
+			}
+
+			// Count shipped stages with and without USER instructions
 			stagesWithUser := make(map[int]bool)
 			stagesWithoutUser := make(map[int]bool)
 
 			for i, stage := range df.Stages {
+				if !shipped[i] {
+					continue
+				}
 				hasUser := false
 				for _, instr := range stage.Instructions {
 					if _, ok := instr.(*ast.UserInstruction); ok {
@@ -908,10 +982,15 @@ func TestMultiStageStageIsolation(t *testing.T) {
 			for _, finding := range findings {
 				if finding.RuleID == rules.RuleNoUser {
 					noUserFindings++
+					stage, ok := df.StageByIndex(finding.StageIndex)
+					if !ok || !shipped[stage.Index] {
+						t.Logf("Unexpected NoUser finding for builder-only stage %d", finding.StageIndex)
+						return false
+					}
 				}
 			}
 
-			// The number of NoUser findings should equal the number of stages without USER
+			// The number of NoUser findings should equal the number of shipped stages without USER
 			expectedFindings := len(stagesWithoutUser)
 
 			if noUserFindings != expectedFindings {
@@ -928,7 +1007,8 @@ func TestMultiStageStageIsolation(t *testing.T) {
 	properties.TestingRun(t)
 }
 
-// genMultiStageDockerfile generates multi-stage Dockerfiles with varying USER instruction presence.
+// genMultiStageDockerfile generates multi-stage Dockerfiles with varying USER instruction
+// presence, where each stage is built either from an image or from the previous stage.
 func genMultiStageDockerfile() gopter.Gen {
 	return gen.IntRange(2, 4).FlatMap(func(numStagesVal interface{}) gopter.Gen {
 		numStages := numStagesVal.(int)
@@ -937,14 +1017,18 @@ func genMultiStageDockerfile() gopter.Gen {
 		return gen.SliceOfN(numStages, gen.Bool()).FlatMap(func(hasUserSliceVal interface{}) gopter.Gen {
 			hasUserSlice := hasUserSliceVal.([]bool)
 
-			return gen.Const(buildMultiStageDockerfile(numStages, hasUserSlice))
+			// Generate a slice of booleans indicating whether each stage is built FROM the previous one
+			return gen.SliceOfN(numStages, gen.Bool()).Map(func(fromPrevious []bool) *ast.Dockerfile {
+				return buildMultiStageDockerfile(numStages, hasUserSlice, fromPrevious)
+			})
 		}, reflect.TypeOf(&ast.Dockerfile{}))
 	}, reflect.TypeOf(&ast.Dockerfile{}))
 }
 
 // buildMultiStageDockerfile constructs a multi-stage Dockerfile AST with the specified
-// number of stages and USER instruction presence per stage.
-func buildMultiStageDockerfile(numStages int, hasUserPerStage []bool) *ast.Dockerfile {
+// number of stages, USER instruction presence per stage and whether each stage is
+// built FROM the previous stage.
+func buildMultiStageDockerfile(numStages int, hasUserPerStage, fromPrevious []bool) *ast.Dockerfile {
 	stages := make([]ast.Stage, numStages)
 	allInstructions := make([]ast.Instruction, 0)
 	lineNum := 1
@@ -962,6 +1046,9 @@ func buildMultiStageDockerfile(numStages int, hasUserPerStage []bool) *ast.Docke
 		if i < len(images) {
 			image = images[i]
 		}
+		if i > 0 && i < len(fromPrevious) && fromPrevious[i] {
+			image = stages[i-1].Name
+		}
 
 		// Create FROM instruction for this stage
 		fromInstr := &ast.FromInstruction{
@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -63,6 +65,341 @@ RUN apt-get update
 	}
 }
 
+func TestAnalyzer_Analyze_SelectRules(t *testing.T) {
+	dockerfile := `FROM ubuntu
+ENV API_KEY=secret123
+RUN apt-get update
+`
+	df, err := parser.ParseString(dockerfile)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	analyzer := NewWithDefaults(Config{
+		SelectRules: []string{rules.RuleSecretInEnv, rules.RuleNoUser},
+	})
+	findings := analyzer.Analyze(df)
+
+	if len(findings) == 0 {
+		t.Fatal("Expected findings from selected rules but got none")
+	}
+
+	// Should only have findings from the selected rules
+	for _, f := range findings {
+		if f.RuleID != rules.RuleSecretInEnv && f.RuleID != rules.RuleNoUser {
+			t.Errorf("Expected only selected rules to run, got %s", f.RuleID)
+		}
+	}
+}
+
+func TestAnalyzer_Analyze_MinSeverity(t *testing.T) {
+	dockerfile := `FROM ubuntu:22.04
+COPY *.json /app/
+EXPOSE 70000
+`
+	df, err := parser.ParseString(dockerfile)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		minSeverity ast.Severity
+	}{
+		{"info reports everything", ast.SeverityInfo},
+		{"warning hides info", ast.SeverityWarning},
+		{"error hides warnings and info", ast.SeverityError},
+	}
+
+	all := NewWithDefaults(Config{}).Analyze(df)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := NewWithDefaults(Config{MinSeverity: tt.minSeverity}).Analyze(df)
+
+			expected := 0
+			for _, f := range all {
+				if f.Severity >= tt.minSeverity {
+					expected++
+				}
+			}
+			if len(findings) != expected {
+				t.Errorf("expected %d findings, got %d", expected, len(findings))
+			}
+			for _, f := range findings {
+				if f.Severity < tt.minSeverity {
+					t.Errorf("finding %s with severity %s reported below minimum %s", f.RuleID, f.Severity, tt.minSeverity)
+				}
+			}
+		})
+	}
+
+	if len(NewWithDefaults(Config{MinSeverity: ast.SeverityError}).Analyze(df)) == 0 {
+		t.Error("expected the invalid EXPOSE port to be reported at error severity")
+	}
+}
+
+func TestAnalyzer_Analyze_SeverityOverrides(t *testing.T) {
+	df, err := parser.ParseString("FROM ubuntu:22.04\nEXPOSE 70000\n")
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		config        Config
+		expectedCount int
+		expected      ast.Severity
+	}{
+		{
+			"raise to error",
+			Config{SeverityOverrides: map[string]ast.Severity{rules.RuleNoUser: ast.SeverityError}},
+			1,
+			ast.SeverityError,
+		},
+		{
+			"lower to info",
+			Config{SeverityOverrides: map[string]ast.Severity{rules.RuleNoUser: ast.SeverityInfo}},
+			1,
+			ast.SeverityInfo,
+		},
+		{
+			"lowered finding is filtered by min severity",
+			Config{
+				SeverityOverrides: map[string]ast.Severity{rules.RuleNoUser: ast.SeverityInfo},
+				MinSeverity:       ast.SeverityWarning,
+			},
+			0,
+			ast.SeverityInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var noUser []ast.Finding
+			for _, f := range NewWithDefaults(tt.config).Analyze(df) {
+				if f.RuleID == rules.RuleNoUser {
+					noUser = append(noUser, f)
+				}
+			}
+			if len(noUser) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(noUser))
+			}
+			for _, f := range noUser {
+				if f.Severity != tt.expected {
+					t.Errorf("expected severity %s, got %s", tt.expected, f.Severity)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzer_Analyze_StageAttribution(t *testing.T) {
+	dockerfile := `ARG GO_VERSION=1.22
+FROM golang:${GO_VERSION} AS builder
+RUN go build -o /app
+RUN strip /app
+FROM alpine:3.18
+COPY --from=builder /app /app
+CMD ["/app"]
+CMD ["/app", "--help"]
+`
+	df, err := parser.ParseString(dockerfile)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	expected := map[string]struct {
+		index int
+		name  string
+	}{
+		rules.RuleConsecutiveRun: {0, "builder"}, // whole-file rule, backfilled by line
+		rules.RuleMultipleCMD:    {1, ""},        // per-stage rule, set by the rule
+	}
+
+	findings := NewWithDefaults(Config{}).Analyze(df)
+	for ruleID, want := range expected {
+		found := false
+		for _, f := range findings {
+			if f.RuleID != ruleID {
+				continue
+			}
+			found = true
+			if f.StageIndex != want.index || f.StageName != want.name {
+				t.Errorf("%s: stage = %d %q, want %d %q", ruleID, f.StageIndex, f.StageName, want.index, want.name)
+			}
+		}
+		if !found {
+			t.Errorf("expected a %s finding", ruleID)
+		}
+	}
+
+	// The builder stage is only used through COPY --from, so DL4002 reports
+	// the final stage alone
+	noUser := 0
+	for _, f := range findings {
+		if f.RuleID == rules.RuleNoUser {
+			noUser++
+			if f.StageIndex != 1 {
+				t.Errorf("DL4002 finding attributed to stage %d, want 1", f.StageIndex)
+			}
+		}
+	}
+	if noUser != 1 {
+		t.Errorf("expected one DL4002 finding, got %d", noUser)
+	}
+}
+
+func TestAnalyzer_Analyze_CategoryAndFix(t *testing.T) {
+	dockerfile := `ARG BASE
+FROM ${BASE} AS build
+FROM alpine:3.18
+ADD app.tar.gz /app
+ADD config.json /etc/app/
+CMD ["/app"]
+CMD ["/app", "--help"]
+FROM ubuntu
+`
+	df, err := parser.ParseString(dockerfile)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	expected := map[string]struct {
+		category string
+		fix      bool
+	}{
+		rules.RuleAddOverCopy: {rules.CategorySecurity, true},
+		rules.RuleMultipleCMD: {rules.CategoryCorrectness, false},
+		// DL3006 implements Fixer, but only to ask for a tag
+		rules.RuleMissingTag: {rules.CategoryBestPractice, false},
+	}
+
+	findings := NewWithDefaults(Config{}).Analyze(df)
+	for ruleID, want := range expected {
+		found := false
+		for _, f := range findings {
+			if f.RuleID != ruleID {
+				continue
+			}
+			found = true
+			if f.RuleCategory != want.category || f.FixAvailable != want.fix {
+				t.Errorf("%s: category = %q, fix = %v, want %q, %v", ruleID, f.RuleCategory, f.FixAvailable, want.category, want.fix)
+			}
+		}
+		if !found {
+			t.Errorf("expected a %s finding", ruleID)
+		}
+	}
+}
+
+func TestAnalyzer_Fix(t *testing.T) {
+	dockerfile := `FROM alpine:3.18 AS build
+WORKDIR src
+# docker-lint ignore: DL4004
+ADD go.mod /src/
+FROM alpine:3.18
+ADD app /app/
+WORKDIR app
+`
+	tests := []struct {
+		name          string
+		config        Config
+		expectedCount int
+	}{
+		{"all fixable rules", Config{}, 3},
+		{"ignored rule is not fixed", Config{IgnoreRules: []string{rules.RuleRelativeWorkdir}}, 1},
+		{"findings below min severity are not fixed", Config{MinSeverity: ast.SeverityError}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(dockerfile)
+			if err != nil {
+				t.Fatalf("Failed to parse Dockerfile: %v", err)
+			}
+
+			analyzer := NewWithDefaults(tt.config)
+			result := analyzer.Fix([]byte(dockerfile), df)
+			if result.Fixed != tt.expectedCount {
+				t.Fatalf("expected %d fixes, got %d", tt.expectedCount, result.Fixed)
+			}
+			if !strings.Contains(string(result.Source), "# docker-lint ignore: DL4004\nADD go.mod /src/\n") {
+				t.Errorf("ignored finding was changed or comment lost:\n%s", result.Source)
+			}
+
+			df, err = parser.ParseString(string(result.Source))
+			if err != nil {
+				t.Fatalf("Failed to parse fixed Dockerfile: %v", err)
+			}
+			for _, f := range analyzer.AnalyzeWithRules(df, []string{rules.RuleRelativeWorkdir, rules.RuleAddOverCopy}) {
+				if analyzer.IsEnabled(f.RuleID) && f.Severity >= tt.config.MinSeverity {
+					t.Errorf("unexpected finding after fix: %s at line %d", f.RuleID, f.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzer_Analyze_SelectWithIgnore(t *testing.T) {
+	dockerfile := `FROM ubuntu
+ENV API_KEY=secret123
+`
+	df, err := parser.ParseString(dockerfile)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	// Ignore is applied within the selected subset
+	analyzer := NewWithDefaults(Config{
+		SelectRules: []string{rules.RuleSecretInEnv, rules.RuleNoUser},
+		IgnoreRules: []string{rules.RuleNoUser, rules.RuleMissingTag},
+	})
+	findings := analyzer.Analyze(df)
+
+	if len(findings) != 1 || findings[0].RuleID != rules.RuleSecretInEnv {
+		t.Errorf("Expected only a DL4000 finding, got %v", findings)
+	}
+}
+
+func TestAnalyzer_IsEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		ruleID string
+		want   bool
+	}{
+		{"no filters", Config{}, rules.RuleMissingTag, true},
+		{"ignored", Config{IgnoreRules: []string{rules.RuleMissingTag}}, rules.RuleMissingTag, false},
+		{"selected", Config{SelectRules: []string{rules.RuleMissingTag}}, rules.RuleMissingTag, true},
+		{"not selected", Config{SelectRules: []string{rules.RuleNoUser}}, rules.RuleMissingTag, false},
+		{
+			"selected and ignored",
+			Config{SelectRules: []string{rules.RuleMissingTag}, IgnoreRules: []string{rules.RuleMissingTag}},
+			rules.RuleMissingTag,
+			false,
+		},
+		{"category selected", Config{SelectCategories: []string{rules.CategorySecurity}}, rules.RuleNoUser, true},
+		{"category not selected", Config{SelectCategories: []string{rules.CategorySecurity}}, rules.RuleMissingTag, false},
+		{
+			"category and rule selected",
+			Config{SelectRules: []string{rules.RuleNoUser}, SelectCategories: []string{rules.CategoryPerformance}},
+			rules.RuleNoUser,
+			false,
+		},
+		{"unknown rule with category", Config{SelectCategories: []string{rules.CategorySecurity}}, "DL9999", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewWithDefaults(tt.config)
+			if got := analyzer.IsEnabled(tt.ruleID); got != tt.want {
+				t.Errorf("IsEnabled(%s) = %v, want %v", tt.ruleID, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAnalyzer_Analyze_InlineIgnore(t *testing.T) {
 	// Dockerfile with inline ignore comment
 	dockerfile := `# docker-lint ignore: DL3006
@@ -85,6 +422,31 @@ RUN apt-get update
 	}
 }
 
+func TestAnalyzer_Analyze_IgnoreRange(t *testing.T) {
+	dockerfile := `FROM ubuntu:22.04
+# docker-lint ignore-start: DL3009
+RUN apt-get update && \
+    apt-get install -y --no-install-recommends curl=7.81.0-1
+RUN apt-get install -y --no-install-recommends git=1:2.34.1-1
+# docker-lint ignore-end: DL3009
+RUN apt-get install -y --no-install-recommends make=4.3-4
+`
+	df, err := parser.ParseString(dockerfile)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	var lines []int
+	for _, f := range NewWithDefaults(Config{}).Analyze(df) {
+		if f.RuleID == rules.RuleCacheNotCleaned {
+			lines = append(lines, f.Line)
+		}
+	}
+	if !reflect.DeepEqual(lines, []int{7}) {
+		t.Errorf("DL3009 findings on lines %v, want only line 7 after the range", lines)
+	}
+}
+
 func TestAnalyzer_Analyze_InlineIgnoreMultipleRules(t *testing.T) {
 	// Dockerfile with inline ignore for multiple rules
 	dockerfile := `# docker-lint ignore: DL3006, DL3008
@@ -166,6 +528,25 @@ RUN apt-get update
 	}
 }
 
+func TestAnalyzer_Analyze_ConcurrentMatchesSequential(t *testing.T) {
+	df, err := parser.ParseString(largeDockerfile(20))
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	sequential := NewWithDefaults(Config{Workers: 1}).Analyze(df)
+	if len(sequential) == 0 {
+		t.Fatal("expected findings for the generated Dockerfile")
+	}
+
+	for _, workers := range []int{0, 2, 8, 64} {
+		concurrent := NewWithDefaults(Config{Workers: workers}).Analyze(df)
+		if !reflect.DeepEqual(sequential, concurrent) {
+			t.Errorf("Workers=%d: findings differ from sequential run", workers)
+		}
+	}
+}
+
 func TestAnalyzer_AnalyzeWithRules(t *testing.T) {
 	dockerfile := `FROM ubuntu
 RUN apt-get update
@@ -265,6 +646,65 @@ RUN apt-get update
 	}
 }
 
+// strictTagRule replaces MissingTagRule and also reports tagged images.
+type strictTagRule struct{ rules.MissingTagRule }
+
+func (r *strictTagRule) Check(df *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+	for _, from := range ast.FindFrom(df) {
+		if from.Digest == "" {
+			findings = append(findings, ast.Finding{RuleID: r.ID(), Severity: ast.SeverityError, Line: from.Line(), Column: 1, Message: "pin a digest"})
+		}
+	}
+	return findings
+}
+
+func TestNew_WithReplacedRule(t *testing.T) {
+	registry := rules.DefaultRegistry.Clone()
+	if !registry.Replace(&strictTagRule{}) {
+		t.Fatal("Replace() = false, want true")
+	}
+
+	df, err := parser.ParseString("FROM alpine:3.18\nUSER app\n")
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	findings := New(registry, Config{}).AnalyzeWithRules(df, []string{rules.RuleMissingTag})
+	if len(findings) != 1 || findings[0].Message != "pin a digest" {
+		t.Errorf("expected the replacement rule's finding, got %v", findings)
+	}
+	if findings := NewWithDefaults(Config{}).AnalyzeWithRules(df, []string{rules.RuleMissingTag}); len(findings) != 0 {
+		t.Errorf("default registry was modified, got %v", findings)
+	}
+}
+
+func TestNew_WithRuleOptions(t *testing.T) {
+	df, err := parser.ParseString("FROM corp-base:1.4\nUSER app\n")
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	cfg := Config{
+		SelectRules: []string{rules.RuleLargeBaseImage},
+		RuleOptions: map[string]map[string]string{rules.RuleLargeBaseImage: {"large_images": "corp-base"}},
+	}
+	if err := cfg.Validate(rules.DefaultRegistry); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if findings := NewWithDefaults(cfg).Analyze(df); len(findings) != 1 {
+		t.Errorf("expected 1 finding for the configured image, got %v", findings)
+	}
+	if findings := NewWithDefaults(Config{SelectRules: cfg.SelectRules}).Analyze(df); len(findings) != 0 {
+		t.Errorf("default rule reported a custom image, got %v", findings)
+	}
+
+	cfg.RuleOptions = map[string]map[string]string{rules.RuleLargeBaseImage: {"images": "corp-base"}}
+	if err := cfg.Validate(rules.DefaultRegistry); err == nil {
+		t.Error("Validate() with an unknown option: expected error")
+	}
+}
+
 func TestAnalyzer_Analyze_SortedByLine(t *testing.T) {
 	dockerfile := `FROM ubuntu
 FROM debian
@@ -505,3 +945,190 @@ CMD ["node", "server.js"]
 func containsSubstring(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+// largeDockerfile generates a multi-stage Dockerfile with the given number of stages.
+func largeDockerfile(stages int) string {
+	var sb strings.Builder
+	for i := 0; i < stages; i++ {
+		fmt.Fprintf(&sb, "FROM ubuntu:latest AS stage%d\n", i)
+		sb.WriteString("ENV API_KEY=secret\n")
+		sb.WriteString("WORKDIR app\n")
+		sb.WriteString("RUN apt-get update\n")
+		sb.WriteString("RUN apt-get install curl wget\n")
+		sb.WriteString("RUN cd /tmp && chmod 777 /tmp\n")
+		sb.WriteString("COPY *.json src/*.go /app\n")
+		sb.WriteString("ADD https://example.com/file.tar.gz /tmp/\n")
+		sb.WriteString("EXPOSE 8080 70000\n")
+	}
+	sb.WriteString("CMD [\"/app/server\"]\n")
+	return sb.String()
+}
+
+func benchmarkAnalyze(b *testing.B, workers int) {
+	df, err := parser.ParseString(largeDockerfile(200))
+	if err != nil {
+		b.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+	analyzer := NewWithDefaults(Config{Workers: workers})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyzer.Analyze(df)
+	}
+}
+
+func BenchmarkAnalyze_Sequential(b *testing.B) { benchmarkAnalyze(b, 1) }
+
+func BenchmarkAnalyze_Concurrent(b *testing.B) { benchmarkAnalyze(b, 0) }
+
+// benchmarkAllRulesDockerfile triggers every default rule except DL5000
+// (missing HEALTHCHECK), which cannot fire together with DL5003 (HEALTHCHECK
+// in shell form), and DL5002 (privileged port for a non-root user), which
+// cannot fire together with DL4002 and DL4012 (final stage runs as root).
+const benchmarkAllRulesDockerfile = `FROM golang:${GO_VERSION} AS build
+MAINTAINER dev@example.com
+WORKDIR src
+COPY main.go /src/
+RUN cd /src && go build -o /out/app .
+COPY static /src/static
+RUN apt-get update
+RUN apt-get install curl wget
+RUN apt-get upgrade -y
+RUN sudo apk add git
+RUN pip install requests
+RUN curl -k https://example.com/install.sh | sh
+RUN chmod 777 /tmp
+RUN export API_TOKEN=s3cr3t && ./deploy
+COPY *.json src/*.go /app
+COPY .git /app/.git
+COPY id_rsa /root/.ssh/id_rsa
+ADD config.json /etc/app/
+ADD https://example.com/file.tar.gz /tmp/
+ENV DB_PASSWORD=secret
+ENV DB_PASSWORD=other
+ARG API_SECRET=abc
+ENV TOKEN=$API_SECRET
+LABEL version=1 version=2
+EXPOSE 80 70000
+STOPSIGNAL SIGFOO
+HEALTHCHECK CMD curl -f http://localhost/
+CMD echo one
+CMD echo two
+ENTRYPOINT /bin/app
+ENTRYPOINT /bin/other
+
+FROM ubuntu:latest AS tools
+RUN echo tools
+
+FROM node
+COPY --from=missing /out/app /app
+COPY --from=busybox /bin/sh /bin/sh
+COPY . .
+COPY package.json package-lock.json ./
+RUN npm install
+`
+
+// BenchmarkAnalyze runs all default rules over a Dockerfile that triggers
+// them, so every rule does its full work.
+// Target: <1ms per Dockerfile.
+func BenchmarkAnalyze(b *testing.B) {
+	df, err := parser.ParseString(benchmarkAllRulesDockerfile)
+	if err != nil {
+		b.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+	analyzer := NewWithDefaults(Config{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyzer.Analyze(df)
+	}
+}
+
+func TestAnalyzer_SummarizeUnpinned(t *testing.T) {
+	content := `FROM golang AS build
+RUN go build -o /app .
+
+FROM node:latest AS assets
+RUN npm ci
+
+FROM alpine:3.18
+COPY --from=build /app /app
+`
+	df, err := parser.ParseString(content)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	selected := []string{rules.RuleMissingTag, rules.RuleLatestTag, rules.RuleNoUser}
+
+	perInstruction := New(rules.DefaultRegistry, Config{SelectRules: selected}).Analyze(df)
+	var unpinned []int
+	for _, f := range perInstruction {
+		if f.RuleID == rules.RuleMissingTag || f.RuleID == rules.RuleLatestTag {
+			unpinned = append(unpinned, f.Line)
+		}
+	}
+	if !reflect.DeepEqual(unpinned, []int{1, 4}) {
+		t.Fatalf("expected unpinned findings on lines [1 4], got %v", unpinned)
+	}
+
+	summarized := New(rules.DefaultRegistry, Config{SelectRules: selected, SummarizeUnpinned: true}).Analyze(df)
+	if len(summarized) != len(perInstruction)-1 {
+		t.Fatalf("expected %d findings, got %d: %v", len(perInstruction)-1, len(summarized), summarized)
+	}
+
+	var summary *ast.Finding
+	for i, f := range summarized {
+		switch f.RuleID {
+		case rules.RuleMissingTag:
+			summary = &summarized[i]
+		case rules.RuleLatestTag:
+			t.Errorf("unexpected %s finding on line %d", f.RuleID, f.Line)
+		}
+	}
+	if summary == nil {
+		t.Fatalf("expected a summary finding, got %v", summarized)
+	}
+	if summary.Line != 1 || summary.Severity != ast.SeverityWarning {
+		t.Errorf("summary Line = %d, Severity = %s, want 1, warning", summary.Line, summary.Severity)
+	}
+	if want := "2 images unpinned, on lines 1, 4"; summary.Message != want {
+		t.Errorf("summary Message = %q, want %q", summary.Message, want)
+	}
+
+	// Findings of other rules are not affected
+	for _, f := range perInstruction {
+		if f.RuleID == rules.RuleNoUser && !containsFinding(summarized, f) {
+			t.Errorf("missing %s finding on line %d", f.RuleID, f.Line)
+		}
+	}
+}
+
+func TestAnalyzer_SummarizeUnpinned_Single(t *testing.T) {
+	df, err := parser.ParseString("FROM ubuntu\nUSER app\n")
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	cfg := Config{SelectRules: []string{rules.RuleMissingTag}}
+	perInstruction := New(rules.DefaultRegistry, cfg).Analyze(df)
+
+	cfg.SummarizeUnpinned = true
+	summarized := New(rules.DefaultRegistry, cfg).Analyze(df)
+
+	if !reflect.DeepEqual(summarized, perInstruction) {
+		t.Errorf("a single unpinned image should not be summarized:\ngot  %v\nwant %v", summarized, perInstruction)
+	}
+}
+
+// containsFinding reports whether findings contains f.
+func containsFinding(findings []ast.Finding, f ast.Finding) bool {
+	for _, finding := range findings {
+		if finding == f {
+			return true
+		}
+	}
+	return false
+}
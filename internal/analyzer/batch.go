@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
+)
+
+// FileResult holds the outcome of analyzing one file with AnalyzeFiles.
+type FileResult struct {
+	// Path is the file path, as passed to AnalyzeFiles.
+	Path string
+
+	// Findings are the findings for the file, sorted as by Analyze. They are
+	// empty when ParseError is set.
+	Findings []ast.Finding
+
+	// ParseError is set when the file could not be read or parsed.
+	ParseError error
+}
+
+// AnalyzeFiles reads, parses and analyzes the given files with the default
+// rule registry. See Analyzer.AnalyzeFiles.
+func AnalyzeFiles(paths []string, cfg Config) ([]FileResult, error) {
+	return NewWithDefaults(cfg).AnalyzeFiles(paths)
+}
+
+// AnalyzeFiles reads, parses and analyzes the given files concurrently, using
+// Config.Workers goroutines (runtime.NumCPU() by default). The files are the
+// unit of concurrency, so the rules for each file run sequentially.
+//
+// A file that cannot be read or parsed does not stop the others: its
+// FileResult carries the ParseError, and the returned error joins the errors
+// of all such files. Results are returned in the order of paths, even when
+// the error is not nil.
+func (a *Analyzer) AnalyzeFiles(paths []string) ([]FileResult, error) {
+	results := make([]FileResult, len(paths))
+
+	sequential := &Analyzer{registry: a.registry, config: a.config}
+	sequential.config.Workers = 1
+
+	workers := a.workers()
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = sequential.analyzeFile(paths[i])
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.ParseError != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Path, result.ParseError))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// analyzeFile reads, parses and analyzes a single file.
+func (a *Analyzer) analyzeFile(path string) FileResult {
+	result := FileResult{Path: path}
+
+	file, err := os.Open(path)
+	if err != nil {
+		result.ParseError = err
+		return result
+	}
+	defer file.Close()
+
+	dockerfile, err := parser.ParseReader(file)
+	if err != nil {
+		result.ParseError = err
+		return result
+	}
+
+	result.Findings = a.Analyze(dockerfile)
+	return result
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devblac/docker-lint/internal/parser"
+	"github.com/devblac/docker-lint/internal/rules"
+)
+
+func TestAnalyzeFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"untagged.Dockerfile", "FROM ubuntu\nUSER app\n"},
+		{"invalid.Dockerfile", "FROM alpine:3.18\nCOPY .\n"},
+		{"tagged.Dockerfile", "FROM alpine:3.18\nUSER app\n"},
+	}
+
+	var paths []string
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		if err := os.WriteFile(path, []byte(file.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	missing := filepath.Join(dir, "missing.Dockerfile")
+	paths = append(paths, missing)
+
+	cfg := Config{SelectRules: []string{rules.RuleMissingTag}, Workers: 2}
+	results, err := AnalyzeFiles(paths, cfg)
+
+	if len(results) != len(paths) {
+		t.Fatalf("expected %d results, got %d", len(paths), len(results))
+	}
+	for i, result := range results {
+		if result.Path != paths[i] {
+			t.Errorf("results[%d].Path = %q, want %q", i, result.Path, paths[i])
+		}
+	}
+
+	if results[0].ParseError != nil || len(results[0].Findings) != 1 || results[0].Findings[0].RuleID != rules.RuleMissingTag {
+		t.Errorf("untagged: ParseError = %v, Findings = %v, want one %s finding", results[0].ParseError, results[0].Findings, rules.RuleMissingTag)
+	}
+
+	var parseErr *parser.ParseError
+	if !errors.As(results[1].ParseError, &parseErr) || parseErr.Line != 2 {
+		t.Errorf("invalid: ParseError = %v, want a ParseError on line 2", results[1].ParseError)
+	}
+	if len(results[1].Findings) != 0 {
+		t.Errorf("invalid: expected no findings, got %v", results[1].Findings)
+	}
+
+	if results[2].ParseError != nil || len(results[2].Findings) != 0 {
+		t.Errorf("tagged: ParseError = %v, Findings = %v, want none", results[2].ParseError, results[2].Findings)
+	}
+
+	if !errors.Is(results[3].ParseError, os.ErrNotExist) {
+		t.Errorf("missing: ParseError = %v, want os.ErrNotExist", results[3].ParseError)
+	}
+
+	if err == nil {
+		t.Fatal("expected error for the invalid and missing files, got nil")
+	}
+	if !errors.As(err, &parseErr) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("error %v does not wrap both file errors", err)
+	}
+	for _, path := range []string{paths[1], missing} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("error %q does not name %s", err, path)
+		}
+	}
+}
+
+func TestAnalyzeFiles_Order(t *testing.T) {
+	dir := t.TempDir()
+
+	var paths []string
+	for i := 0; i < 50; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%02d.Dockerfile", i))
+		content := "FROM alpine:3.18\n"
+		if i%3 == 0 {
+			content = "FROM ubuntu\n"
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	for _, workers := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			cfg := Config{SelectRules: []string{rules.RuleMissingTag}, Workers: workers}
+			results, err := AnalyzeFiles(paths, cfg)
+			if err != nil {
+				t.Fatalf("AnalyzeFiles() error = %v", err)
+			}
+
+			for i, result := range results {
+				if result.Path != paths[i] {
+					t.Fatalf("results[%d].Path = %q, want %q", i, result.Path, paths[i])
+				}
+				want := 0
+				if i%3 == 0 {
+					want = 1
+				}
+				if len(result.Findings) != want {
+					t.Errorf("%s: expected %d findings, got %d", result.Path, want, len(result.Findings))
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeFiles_Empty(t *testing.T) {
+	results, err := AnalyzeFiles(nil, Config{})
+	if err != nil || len(results) != 0 {
+		t.Errorf("AnalyzeFiles(nil) = %v, %v, want no results and no error", results, err)
+	}
+}
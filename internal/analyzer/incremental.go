@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/rules"
+)
+
+// incrementalCache holds the findings that rules implementing
+// rules.LineLocalRule reported for each instruction in the last
+// AnalyzeIncremental call.
+type incrementalCache struct {
+	mu       sync.Mutex
+	findings map[instructionKey][]ast.Finding
+}
+
+// instructionKey identifies the findings of one rule for one instruction.
+type instructionKey struct {
+	ruleID string
+	hash   [sha256.Size]byte
+}
+
+// AnalyzeIncremental returns the same findings as Analyze, reusing the work of
+// the previous call where it can. It is meant for editors that re-lint a
+// Dockerfile on every change and pass the lines that changed since the last
+// call.
+//
+// Rules that implement rules.LineLocalRule are only run against the
+// instructions containing changedLines; their findings for the other
+// instructions are taken from a cache keyed by a hash of each instruction's
+// AST node, including its line. An instruction that is not in the cache, for
+// example because lines inserted above it moved it, is checked even if it did
+// not change, so an incomplete changedLines does not produce stale findings.
+// All other rules look at more than one instruction and always run.
+//
+// The cache only holds the instructions of the last call, so alternating
+// between files gains nothing. Concurrent calls are serialized.
+func (a *Analyzer) AnalyzeIncremental(dockerfile *ast.Dockerfile, changedLines []int) []ast.Finding {
+	if dockerfile == nil {
+		return nil
+	}
+
+	enabled := a.enabledRules()
+	changed := changedInstructions(dockerfile, changedLines)
+
+	hashes := make([][sha256.Size]byte, len(dockerfile.Instructions))
+	for i, instr := range dockerfile.Instructions {
+		hashes[i] = hashInstruction(dockerfile, instr)
+	}
+
+	a.cache.mu.Lock()
+	defer a.cache.mu.Unlock()
+
+	results := make([][]ast.Finding, len(enabled))
+	cached := make(map[instructionKey][]ast.Finding)
+
+	var wholeFile []rules.Rule
+	var wholeFileIndex []int
+	for i, rule := range enabled {
+		lineLocal, ok := rule.(rules.LineLocalRule)
+		if !ok {
+			wholeFile = append(wholeFile, rule)
+			wholeFileIndex = append(wholeFileIndex, i)
+			continue
+		}
+
+		for j, instr := range dockerfile.Instructions {
+			key := instructionKey{ruleID: rule.ID(), hash: hashes[j]}
+			findings, found := a.cache.findings[key]
+			if !found || changed[j] {
+				findings = lineLocal.CheckInstruction(instr)
+			}
+			cached[key] = findings
+			results[i] = append(results[i], findings...)
+		}
+	}
+
+	for i, findings := range a.check(dockerfile, wholeFile) {
+		results[wholeFileIndex[i]] = findings
+	}
+
+	// Drop the instructions that are gone so the cache does not grow
+	a.cache.findings = cached
+
+	return a.collect(dockerfile, enabled, results)
+}
+
+// changedInstructions returns the indexes of the instructions containing the
+// given lines. An instruction is taken to span the lines after the previous
+// instruction up to its own line, the last line of a continued instruction,
+// so a changed continuation line or comment above it counts as a change to
+// it. Lines after the last instruction belong to none.
+func changedInstructions(dockerfile *ast.Dockerfile, lines []int) map[int]bool {
+	instrs := dockerfile.Instructions
+	changed := make(map[int]bool)
+	for _, line := range lines {
+		i := sort.Search(len(instrs), func(i int) bool { return instrs[i].Line() >= line })
+		if i < len(instrs) {
+			changed[i] = true
+		}
+	}
+	return changed
+}
+
+// hashInstruction hashes an instruction's type, line and text, together with
+// the escape character it was parsed with.
+func hashInstruction(dockerfile *ast.Dockerfile, instr ast.Instruction) [sha256.Size]byte {
+	return sha256.Sum256([]byte(string(dockerfile.EscapeChar) + "\x00" + string(instr.Type()) + "\x00" +
+		strconv.Itoa(instr.Line()) + "\x00" + instr.Raw()))
+}
@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
+	"github.com/devblac/docker-lint/internal/rules"
+)
+
+func TestAnalyzeIncremental_MatchesAnalyze(t *testing.T) {
+	edits := []struct {
+		name         string
+		content      string
+		changedLines []int
+	}{
+		{
+			"initial",
+			"FROM ubuntu\nRUN curl https://example.com/install.sh | bash\nRUN chmod 777 /app\nUSER app\n",
+			nil,
+		},
+		{
+			"edit one RUN",
+			"FROM ubuntu\nRUN curl -o /tmp/install.sh https://example.com/install.sh\nRUN chmod 777 /app\nUSER app\n",
+			[]int{2},
+		},
+		{
+			"insert lines above",
+			"FROM ubuntu\nENV API_TOKEN=abc\nARG DB_PASSWORD\nRUN curl -o /tmp/install.sh https://example.com/install.sh\nRUN chmod 777 /app\nUSER app\n",
+			[]int{2, 3},
+		},
+		{
+			"remove USER",
+			"FROM ubuntu\nENV API_TOKEN=abc\nARG DB_PASSWORD\nRUN curl -o /tmp/install.sh https://example.com/install.sh\nRUN chmod 777 /app\n",
+			[]int{6},
+		},
+		{
+			"ignore comment above",
+			"FROM ubuntu\nENV API_TOKEN=abc\nARG DB_PASSWORD\nRUN curl -o /tmp/install.sh https://example.com/install.sh\n# docker-lint ignore: DL3015\nRUN chmod 777 /app\n",
+			[]int{5},
+		},
+		{
+			"edit continuation line",
+			"FROM ubuntu\nENV API_TOKEN=abc\nARG DB_PASSWORD\nRUN apt-get update && \\\n    curl -fsSL https://example.com/install.sh | sh\n# docker-lint ignore: DL3015\nRUN chmod 777 /app\n",
+			[]int{4, 5},
+		},
+		{
+			"changed lines missing",
+			"FROM ubuntu:22.04\nRUN wget -qO- https://example.com/install.sh | sudo sh\nENV API_TOKEN=abc\nRUN chmod 777 /app\n",
+			nil,
+		},
+	}
+
+	incremental := NewWithDefaults(Config{Workers: 1})
+	full := NewWithDefaults(Config{Workers: 1})
+
+	for _, edit := range edits {
+		t.Run(edit.name, func(t *testing.T) {
+			df, err := parser.ParseString(edit.content)
+			if err != nil {
+				t.Fatalf("Failed to parse Dockerfile: %v", err)
+			}
+
+			got := incremental.AnalyzeIncremental(df, edit.changedLines)
+			want := full.Analyze(df)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("AnalyzeIncremental() = %v\nwant %v", got, want)
+			}
+		})
+	}
+}
+
+// countingPipeRule records the lines of the instructions it checks.
+type countingPipeRule struct {
+	rules.CurlPipeBashRule
+	checked []int
+}
+
+func (r *countingPipeRule) CheckInstruction(instr ast.Instruction) []ast.Finding {
+	r.checked = append(r.checked, instr.Line())
+	return r.CurlPipeBashRule.CheckInstruction(instr)
+}
+
+// countingNoUserRule counts how often it checks a whole Dockerfile.
+type countingNoUserRule struct {
+	rules.NoUserRule
+	calls int
+}
+
+func (r *countingNoUserRule) Check(df *ast.Dockerfile) []ast.Finding {
+	r.calls++
+	return r.NoUserRule.Check(df)
+}
+
+func TestAnalyzeIncremental_ReusesLineLocalFindings(t *testing.T) {
+	pipe := &countingPipeRule{}
+	noUser := &countingNoUserRule{}
+	registry := rules.NewRegistry()
+	registry.Register(pipe)
+	registry.Register(noUser)
+	a := New(registry, Config{Workers: 1})
+
+	analyze := func(content string, changedLines []int) []ast.Finding {
+		t.Helper()
+		df, err := parser.ParseString(content)
+		if err != nil {
+			t.Fatalf("Failed to parse Dockerfile: %v", err)
+		}
+		pipe.checked = nil
+		return a.AnalyzeIncremental(df, changedLines)
+	}
+
+	findings := analyze("FROM alpine:3.18\nRUN curl https://example.com/a.sh | sh\nRUN echo hello\n", nil)
+	if !reflect.DeepEqual(pipe.checked, []int{1, 2, 3}) {
+		t.Errorf("first call checked lines %v, want [1 2 3]", pipe.checked)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+
+	// Only the changed instruction is checked again; the DL3022 finding on
+	// line 2 comes from the cache
+	findings = analyze("FROM alpine:3.18\nRUN curl https://example.com/a.sh | sh\nRUN echo world\n", []int{3})
+	if !reflect.DeepEqual(pipe.checked, []int{3}) {
+		t.Errorf("second call checked lines %v, want [3]", pipe.checked)
+	}
+	if len(findings) != 2 || findings[0].RuleID != rules.RuleCurlPipeBash || findings[0].Line != 2 {
+		t.Errorf("expected the cached DL3022 finding on line 2, got %v", findings)
+	}
+
+	// Instructions moved by an inserted line are not in the cache
+	analyze("FROM alpine:3.18\nUSER app\nRUN curl https://example.com/a.sh | sh\nRUN echo world\n", []int{2})
+	if !reflect.DeepEqual(pipe.checked, []int{2, 3, 4}) {
+		t.Errorf("third call checked lines %v, want [2 3 4]", pipe.checked)
+	}
+
+	if noUser.calls != 3 {
+		t.Errorf("whole-file rule ran %d times, want 3", noUser.calls)
+	}
+}
+
+func TestAnalyzeIncremental_NilDockerfile(t *testing.T) {
+	if findings := NewWithDefaults(Config{}).AnalyzeIncremental(nil, []int{1}); findings != nil {
+		t.Errorf("expected nil findings, got %v", findings)
+	}
+}
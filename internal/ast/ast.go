@@ -1,6 +1,12 @@
 // Package ast defines the Abstract Syntax Tree data structures for Dockerfile representation.
 package ast
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // InstructionType represents the type of a Dockerfile instruction.
 type InstructionType string
 
@@ -22,6 +28,7 @@ const (
 	InstrSHELL       InstructionType = "SHELL"
 	InstrSTOPSIGNAL  InstructionType = "STOPSIGNAL"
 	InstrONBUILD     InstructionType = "ONBUILD"
+	InstrMAINTAINER  InstructionType = "MAINTAINER"
 )
 
 // Severity represents the severity level of a lint finding.
@@ -47,6 +54,20 @@ func (s Severity) String() string {
 	}
 }
 
+// ParseSeverity converts a severity name (info, warning or error) to a Severity.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	default:
+		return SeverityInfo, fmt.Errorf("unknown severity: %s", s)
+	}
+}
+
 // Finding represents a lint finding from rule analysis.
 type Finding struct {
 	RuleID     string
@@ -55,6 +76,30 @@ type Finding struct {
 	Column     int
 	Message    string
 	Suggestion string
+
+	// StageIndex and StageName identify the build stage the finding belongs
+	// to; StageIndex is -1 for findings outside any stage. Rules may leave
+	// both unset, in which case the analyzer attributes the finding by line.
+	StageIndex int
+	StageName  string
+
+	// RuleCategory is the category of the rule that reported the finding
+	// and FixAvailable whether that rule can fix it automatically. The
+	// analyzer sets both; rules leave them unset.
+	RuleCategory string
+	FixAvailable bool
+}
+
+// Column returns the 1-based column of the first occurrence of substr in
+// text, counted from the start of the line it is on, or 0 if text does not
+// contain substr. Rules can use it to point at a token in an instruction's
+// RawText.
+func Column(text, substr string) int {
+	i := strings.Index(text, substr)
+	if i < 0 {
+		return 0
+	}
+	return i - strings.LastIndex(text[:i], "\n")
 }
 
 // Instruction is the interface that all Dockerfile instructions implement.
@@ -70,6 +115,35 @@ type Comment struct {
 	Text    string
 }
 
+// ParseWarning represents a problem the parser recovered from, such as an
+// invalid parser directive.
+type ParseWarning struct {
+	LineNum int
+	Message string
+}
+
+// IgnoreRange is a range of lines, from the ignore-start comment on line
+// Start to the ignore-end comment on line End inclusive, in which findings of
+// the rules in RuleIDs are ignored.
+type IgnoreRange struct {
+	Start   int
+	End     int
+	RuleIDs []string
+}
+
+// Contains reports whether the range ignores ruleID on the given line.
+func (r IgnoreRange) Contains(line int, ruleID string) bool {
+	if line < r.Start || line > r.End {
+		return false
+	}
+	for _, id := range r.RuleIDs {
+		if id == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
 // Stage represents a build stage in a multi-stage Dockerfile.
 type Stage struct {
 	Name         string
@@ -84,6 +158,185 @@ type Dockerfile struct {
 	Instructions  []Instruction
 	Comments      []Comment
 	InlineIgnores map[int][]string // line -> rule IDs to ignore
+
+	// IgnoreRanges lists the line ranges between ignore-start and
+	// ignore-end comments, in which the given rules are ignored.
+	IgnoreRanges []IgnoreRange
+
+	// EscapeChar is the escape and line continuation character, '\\' unless
+	// changed with the escape parser directive.
+	EscapeChar rune
+
+	// ParserDirectives holds the parser directives at the top of the file
+	// (e.g. "syntax", "escape"), keyed by lowercase name.
+	ParserDirectives map[string]string
+
+	// Warnings lists problems the parser recovered from.
+	Warnings []ParseWarning
+}
+
+// StageAt returns the build stage containing the given line, or nil if the
+// line comes before the first FROM.
+func (d *Dockerfile) StageAt(line int) *Stage {
+	var found *Stage
+	for i := range d.Stages {
+		stage := &d.Stages[i]
+		if stage.FromInstr == nil || stage.FromInstr.Line() > line {
+			continue
+		}
+		found = stage
+	}
+	return found
+}
+
+// StageByName returns the stage with the given name. Names are compared
+// case-insensitively, as Docker does, and the first stage wins when several
+// share a name.
+func (d *Dockerfile) StageByName(name string) (*Stage, bool) {
+	if name == "" {
+		return nil, false
+	}
+	for i := range d.Stages {
+		if strings.EqualFold(d.Stages[i].Name, name) {
+			return &d.Stages[i], true
+		}
+	}
+	return nil, false
+}
+
+// StageByIndex returns the stage with the given 0-based index.
+func (d *Dockerfile) StageByIndex(idx int) (*Stage, bool) {
+	if idx < 0 || idx >= len(d.Stages) {
+		return nil, false
+	}
+	return &d.Stages[idx], true
+}
+
+// ResolveCopyFrom returns the stage a COPY --from value refers to: a number
+// such as --from=0 is a stage index, anything else a stage name. It reports
+// false for values that name no stage, such as external images, and does not
+// check that the stage comes before the COPY.
+func (d *Dockerfile) ResolveCopyFrom(from string) (*Stage, bool) {
+	if idx, err := strconv.Atoi(from); err == nil {
+		return d.StageByIndex(idx)
+	}
+	return d.StageByName(from)
+}
+
+// Visitor is implemented by types that traverse a Dockerfile with Walk.
+type Visitor interface {
+	// Visit is called for each instruction. Returning false stops the traversal.
+	Visit(instr Instruction) bool
+}
+
+// StageVisitor is a Visitor that also wants to be told about build stages.
+// When the visitor passed to Walk implements StageVisitor, the Dockerfile is
+// walked stage by stage instead of as a flat instruction list.
+type StageVisitor interface {
+	Visitor
+
+	// VisitStage is called before the instructions of each stage. Returning
+	// false skips the stage's instructions and continues with the next stage.
+	VisitStage(stage Stage) bool
+}
+
+// Walk traverses the Dockerfile, calling v.Visit for each instruction in
+// declaration order.
+//
+// If v implements StageVisitor, stages are visited in order instead: VisitStage
+// is called for each stage, followed by Visit for each of the stage's
+// instructions (starting with its FROM). Returning false from Visit then ends
+// the current stage only, and traversal continues with the next stage.
+// Instructions before the first FROM belong to no stage and are not visited
+// in this mode.
+func (d *Dockerfile) Walk(v Visitor) {
+	sv, ok := v.(StageVisitor)
+	if !ok {
+		for _, instr := range d.Instructions {
+			if !v.Visit(instr) {
+				return
+			}
+		}
+		return
+	}
+
+	for _, stage := range d.Stages {
+		if !sv.VisitStage(stage) {
+			continue
+		}
+		for _, instr := range stage.Instructions {
+			if !sv.Visit(instr) {
+				break
+			}
+		}
+	}
+}
+
+// visitorFunc adapts an ordinary function to the Visitor interface.
+type visitorFunc func(Instruction) bool
+
+func (f visitorFunc) Visit(instr Instruction) bool { return f(instr) }
+
+// WalkFunc calls fn for each instruction of df in declaration order until fn
+// returns false.
+func WalkFunc(df *Dockerfile, fn func(Instruction) bool) {
+	df.Walk(visitorFunc(fn))
+}
+
+// FindInstructions returns the instructions of df whose type is T, in
+// declaration order. It returns nil when df is nil.
+//
+// The results are the AST nodes themselves, not copies: modifying them
+// modifies df.
+func FindInstructions[T Instruction](df *Dockerfile) []T {
+	if df == nil {
+		return nil
+	}
+	return filterInstructions[T](df.Instructions)
+}
+
+// FindInstructionsByStage returns the instructions of stage whose type is T,
+// in declaration order. Like FindInstructions, it returns the AST nodes
+// themselves.
+func FindInstructionsByStage[T Instruction](stage Stage) []T {
+	return filterInstructions[T](stage.Instructions)
+}
+
+// filterInstructions returns the elements of instrs whose type is T.
+func filterInstructions[T Instruction](instrs []Instruction) []T {
+	var found []T
+	for _, instr := range instrs {
+		if typed, ok := instr.(T); ok {
+			found = append(found, typed)
+		}
+	}
+	return found
+}
+
+// FindFrom returns the FROM instructions of df. See FindInstructions.
+func FindFrom(df *Dockerfile) []*FromInstruction { return FindInstructions[*FromInstruction](df) }
+
+// FindRun returns the RUN instructions of df. See FindInstructions.
+func FindRun(df *Dockerfile) []*RunInstruction { return FindInstructions[*RunInstruction](df) }
+
+// FindCopy returns the COPY instructions of df. See FindInstructions.
+func FindCopy(df *Dockerfile) []*CopyInstruction { return FindInstructions[*CopyInstruction](df) }
+
+// FindAdd returns the ADD instructions of df. See FindInstructions.
+func FindAdd(df *Dockerfile) []*AddInstruction { return FindInstructions[*AddInstruction](df) }
+
+// FindEnv returns the ENV instructions of df. See FindInstructions.
+func FindEnv(df *Dockerfile) []*EnvInstruction { return FindInstructions[*EnvInstruction](df) }
+
+// FindUser returns the USER instructions of df. See FindInstructions.
+func FindUser(df *Dockerfile) []*UserInstruction { return FindInstructions[*UserInstruction](df) }
+
+// FindCmd returns the CMD instructions of df. See FindInstructions.
+func FindCmd(df *Dockerfile) []*CmdInstruction { return FindInstructions[*CmdInstruction](df) }
+
+// FindEntrypoint returns the ENTRYPOINT instructions of df. See FindInstructions.
+func FindEntrypoint(df *Dockerfile) []*EntrypointInstruction {
+	return FindInstructions[*EntrypointInstruction](df)
 }
 
 // FromInstruction represents a FROM instruction.
@@ -95,6 +348,9 @@ type FromInstruction struct {
 	Digest   string
 	Alias    string // AS name
 	Platform string // --platform flag
+
+	// TagColumn is the 1-based source column of Tag, or 0 if unknown.
+	TagColumn int
 }
 
 func (f *FromInstruction) Line() int             { return f.LineNum }
@@ -106,7 +362,32 @@ type RunInstruction struct {
 	LineNum int
 	RawText string
 	Command string
-	Shell   bool // shell form vs exec form
+	Shell   bool       // shell form vs exec form
+	Mounts  []RunMount // BuildKit --mount flags
+
+	// Network and Security hold the BuildKit --network and --security flag
+	// values (e.g. "none", "insecure"), or "" when the flag is not set.
+	Network  string
+	Security string
+
+	// IsHeredoc is set for RUN <<DELIM; Command then holds the instruction
+	// line followed by the heredoc body on the next lines.
+	IsHeredoc        bool
+	HeredocDelimiter string
+}
+
+// RunMount represents a BuildKit --mount flag on a RUN instruction.
+type RunMount struct {
+	Type     string // cache, bind, secret, ssh or tmpfs
+	ID       string // id option for cache and secret mounts
+	Source   string // source or src option
+	Target   string // target, dst or destination option
+	From     string // stage or image to mount from
+	ReadOnly string // "true" or "false" when readonly/ro/rw is set
+
+	// Options holds the other options, such as sharing=locked or required,
+	// as written and in order.
+	Options []string
 }
 
 func (r *RunInstruction) Line() int             { return r.LineNum }
@@ -121,6 +402,13 @@ type CopyInstruction struct {
 	Dest    string
 	From    string // --from flag for multi-stage
 	Chown   string // --chown flag
+	Chmod   string // --chmod flag
+	Link    bool   // --link flag
+
+	// HeredocDelimiter and HeredocContent are set for COPY <<DELIM, whose
+	// source is the inline content rather than a file.
+	HeredocDelimiter string
+	HeredocContent   string
 }
 
 func (c *CopyInstruction) Line() int             { return c.LineNum }
@@ -134,24 +422,53 @@ type AddInstruction struct {
 	Sources []string
 	Dest    string
 	Chown   string // --chown flag
+	Chmod   string // --chmod flag
+	Link    bool   // --link flag
+
+	// HeredocDelimiter and HeredocContent are set for ADD <<DELIM.
+	HeredocDelimiter string
+	HeredocContent   string
 }
 
 func (a *AddInstruction) Line() int             { return a.LineNum }
 func (a *AddInstruction) Raw() string           { return a.RawText }
 func (a *AddInstruction) Type() InstructionType { return InstrADD }
 
-// EnvInstruction represents an ENV instruction.
+// EnvPair is one key=value assignment of an ENV instruction.
+type EnvPair struct {
+	Key   string
+	Value string
+
+	// Column is the 1-based source column of Key, or 0 if unknown.
+	Column int
+}
+
+// EnvInstruction represents an ENV instruction. ENV k1=v1 k2=v2 sets several
+// variables; Key and Value hold the first pair and Pairs holds all of them.
 type EnvInstruction struct {
 	LineNum int
 	RawText string
 	Key     string
 	Value   string
+	Pairs   []EnvPair
 }
 
 func (e *EnvInstruction) Line() int             { return e.LineNum }
 func (e *EnvInstruction) Raw() string           { return e.RawText }
 func (e *EnvInstruction) Type() InstructionType { return InstrENV }
 
+// AllPairs returns the pairs the instruction assigns. It falls back to Key
+// and Value when Pairs is empty, as in instructions built by hand.
+func (e *EnvInstruction) AllPairs() []EnvPair {
+	if len(e.Pairs) > 0 {
+		return e.Pairs
+	}
+	if e.Key == "" {
+		return nil
+	}
+	return []EnvPair{{Key: e.Key, Value: e.Value}}
+}
+
 // ArgInstruction represents an ARG instruction.
 type ArgInstruction struct {
 	LineNum int
@@ -202,7 +519,8 @@ func (u *UserInstruction) Type() InstructionType { return InstrUSER }
 type LabelInstruction struct {
 	LineNum int
 	RawText string
-	Labels  map[string]string
+	Labels  map[string]string // Last value set for each key
+	Keys    []string          // Keys in declaration order, including repeated keys
 }
 
 func (l *LabelInstruction) Line() int             { return l.LineNum }
@@ -292,3 +610,14 @@ type OnbuildInstruction struct {
 func (o *OnbuildInstruction) Line() int             { return o.LineNum }
 func (o *OnbuildInstruction) Raw() string           { return o.RawText }
 func (o *OnbuildInstruction) Type() InstructionType { return InstrONBUILD }
+
+// MaintainerInstruction represents a deprecated MAINTAINER instruction.
+type MaintainerInstruction struct {
+	LineNum    int
+	RawText    string
+	Maintainer string
+}
+
+func (m *MaintainerInstruction) Line() int             { return m.LineNum }
+func (m *MaintainerInstruction) Raw() string           { return m.RawText }
+func (m *MaintainerInstruction) Type() InstructionType { return InstrMAINTAINER }
@@ -21,6 +21,53 @@ func TestSeverityString(t *testing.T) {
 	}
 }
 
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Severity
+		wantErr  bool
+	}{
+		{"info", SeverityInfo, false},
+		{"warning", SeverityWarning, false},
+		{"ERROR", SeverityError, false},
+		{" warning ", SeverityWarning, false},
+		{"fatal", SeverityInfo, true},
+		{"", SeverityInfo, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSeverity(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSeverity(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseSeverity(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestColumn(t *testing.T) {
+	tests := []struct {
+		text   string
+		substr string
+		want   int
+	}{
+		{"FROM ubuntu:latest", "latest", 13},
+		{"FROM ubuntu:latest", "FROM", 1},
+		{"ENV A=1 TOKEN=x", "TOKEN", 9},
+		{"RUN <<EOF\necho secret\nEOF", "secret", 6},
+		{"FROM ubuntu", "latest", 0},
+		{"FROM ubuntu", "", 1},
+	}
+
+	for _, tt := range tests {
+		if got := Column(tt.text, tt.substr); got != tt.want {
+			t.Errorf("Column(%q, %q) = %d, want %d", tt.text, tt.substr, got, tt.want)
+		}
+	}
+}
+
 func TestFindingCreation(t *testing.T) {
 	finding := Finding{
 		RuleID:     "DL3006",
@@ -321,6 +368,21 @@ func TestOnbuildInstructionMethods(t *testing.T) {
 	}
 }
 
+func TestMaintainerInstructionMethods(t *testing.T) {
+	instr := &MaintainerInstruction{
+		LineNum:    2,
+		RawText:    "MAINTAINER dev@example.com",
+		Maintainer: "dev@example.com",
+	}
+
+	if instr.Line() != 2 {
+		t.Errorf("MaintainerInstruction.Line() = %d, want %d", instr.Line(), 2)
+	}
+	if instr.Type() != InstrMAINTAINER {
+		t.Errorf("MaintainerInstruction.Type() = %v, want %v", instr.Type(), InstrMAINTAINER)
+	}
+}
+
 // TestInstructionInterface verifies all instruction types implement the Instruction interface.
 func TestInstructionInterface(t *testing.T) {
 	instructions := []Instruction{
@@ -341,6 +403,7 @@ func TestInstructionInterface(t *testing.T) {
 		&ShellInstruction{LineNum: 15, RawText: "SHELL /bin/sh"},
 		&StopsignalInstruction{LineNum: 16, RawText: "STOPSIGNAL SIGTERM"},
 		&OnbuildInstruction{LineNum: 17, RawText: "ONBUILD RUN echo"},
+		&MaintainerInstruction{LineNum: 18, RawText: "MAINTAINER dev@example.com"},
 	}
 
 	for i, instr := range instructions {
@@ -355,3 +418,268 @@ func TestInstructionInterface(t *testing.T) {
 		}
 	}
 }
+
+// walkTestDockerfile builds a two-stage Dockerfile with a leading ARG.
+func walkTestDockerfile() *Dockerfile {
+	arg := &ArgInstruction{LineNum: 1, Name: "VERSION"}
+	from1 := &FromInstruction{LineNum: 2, Image: "golang", Alias: "builder"}
+	run := &RunInstruction{LineNum: 3, Command: "go build"}
+	from2 := &FromInstruction{LineNum: 4, Image: "alpine"}
+	cmd := &CmdInstruction{LineNum: 5, Command: []string{"/app"}}
+
+	return &Dockerfile{
+		Instructions: []Instruction{arg, from1, run, from2, cmd},
+		Stages: []Stage{
+			{Name: "builder", FromInstr: from1, Instructions: []Instruction{from1, run}, Index: 0},
+			{FromInstr: from2, Instructions: []Instruction{from2, cmd}, Index: 1},
+		},
+	}
+}
+
+func TestDockerfileStageAt(t *testing.T) {
+	builder := &FromInstruction{LineNum: 2, Image: "golang", Alias: "builder"}
+	final := &FromInstruction{LineNum: 5, Image: "alpine"}
+	df := &Dockerfile{
+		Stages: []Stage{
+			{Name: "builder", FromInstr: builder, Index: 0},
+			{FromInstr: final, Index: 1},
+		},
+	}
+
+	tests := []struct {
+		line      int
+		wantIndex int // -1 for no stage
+	}{
+		{1, -1},
+		{2, 0},
+		{4, 0},
+		{5, 1},
+		{9, 1},
+	}
+
+	for _, tt := range tests {
+		stage := df.StageAt(tt.line)
+		got := -1
+		if stage != nil {
+			got = stage.Index
+		}
+		if got != tt.wantIndex {
+			t.Errorf("StageAt(%d) = stage %d, want %d", tt.line, got, tt.wantIndex)
+		}
+	}
+}
+
+func TestDockerfileStageLookup(t *testing.T) {
+	df := &Dockerfile{
+		Stages: []Stage{
+			{Name: "Builder", FromInstr: &FromInstruction{LineNum: 1, Image: "golang", Alias: "Builder"}, Index: 0},
+			{Name: "builder", FromInstr: &FromInstruction{LineNum: 3, Image: "golang", Alias: "builder"}, Index: 1},
+			{FromInstr: &FromInstruction{LineNum: 5, Image: "alpine"}, Index: 2},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		lookup    func() (*Stage, bool)
+		wantIndex int // -1 for no stage
+	}{
+		{"name found", func() (*Stage, bool) { return df.StageByName("Builder") }, 0},
+		{"name case-insensitive, first wins", func() (*Stage, bool) { return df.StageByName("BUILDER") }, 0},
+		{"name not found", func() (*Stage, bool) { return df.StageByName("runtime") }, -1},
+		{"empty name", func() (*Stage, bool) { return df.StageByName("") }, -1},
+		{"index 0", func() (*Stage, bool) { return df.StageByIndex(0) }, 0},
+		{"last index", func() (*Stage, bool) { return df.StageByIndex(2) }, 2},
+		{"index out of range", func() (*Stage, bool) { return df.StageByIndex(3) }, -1},
+		{"negative index", func() (*Stage, bool) { return df.StageByIndex(-1) }, -1},
+		{"copy from index", func() (*Stage, bool) { return df.ResolveCopyFrom("1") }, 1},
+		{"copy from name", func() (*Stage, bool) { return df.ResolveCopyFrom("builder") }, 0},
+		{"copy from index out of range", func() (*Stage, bool) { return df.ResolveCopyFrom("7") }, -1},
+		{"copy from external image", func() (*Stage, bool) { return df.ResolveCopyFrom("nginx:1.25") }, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, ok := tt.lookup()
+			got := -1
+			if stage != nil {
+				got = stage.Index
+			}
+			if got != tt.wantIndex || ok != (tt.wantIndex >= 0) {
+				t.Errorf("got stage %d, %v, want %d", got, ok, tt.wantIndex)
+			}
+		})
+	}
+
+	// The returned stage points into the Dockerfile
+	if stage, _ := df.StageByIndex(2); stage != &df.Stages[2] {
+		t.Error("StageByIndex() returned a copy of the stage")
+	}
+}
+
+func TestWalkFunc(t *testing.T) {
+	df := walkTestDockerfile()
+
+	var lines []int
+	WalkFunc(df, func(instr Instruction) bool {
+		lines = append(lines, instr.Line())
+		return true
+	})
+	if len(lines) != 5 {
+		t.Fatalf("visited %d instructions, want 5", len(lines))
+	}
+	for i, line := range lines {
+		if line != i+1 {
+			t.Errorf("visit %d was line %d, want %d", i, line, i+1)
+		}
+	}
+
+	// Returning false stops the traversal
+	visited := 0
+	WalkFunc(df, func(instr Instruction) bool {
+		visited++
+		return instr.Type() != InstrRUN
+	})
+	if visited != 3 {
+		t.Errorf("visited %d instructions before stopping, want 3", visited)
+	}
+}
+
+// stageRecorder records the traversal order of a stage walk.
+type stageRecorder struct {
+	events    []string
+	skipStage int
+	stopAt    InstructionType
+}
+
+func (r *stageRecorder) VisitStage(stage Stage) bool {
+	r.events = append(r.events, "stage "+stage.FromInstr.Image)
+	return stage.Index != r.skipStage
+}
+
+func (r *stageRecorder) Visit(instr Instruction) bool {
+	r.events = append(r.events, string(instr.Type()))
+	return instr.Type() != r.stopAt
+}
+
+func TestWalkStageVisitor(t *testing.T) {
+	tests := []struct {
+		name      string
+		skipStage int
+		stopAt    InstructionType
+		expected  []string
+	}{
+		{
+			name:      "visits stages in order",
+			skipStage: -1,
+			expected:  []string{"stage golang", "FROM", "RUN", "stage alpine", "FROM", "CMD"},
+		},
+		{
+			name:      "VisitStage false skips stage",
+			skipStage: 0,
+			expected:  []string{"stage golang", "stage alpine", "FROM", "CMD"},
+		},
+		{
+			name:      "Visit false ends current stage only",
+			skipStage: -1,
+			stopAt:    InstrFROM,
+			expected:  []string{"stage golang", "FROM", "stage alpine", "FROM"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &stageRecorder{skipStage: tt.skipStage, stopAt: tt.stopAt}
+			walkTestDockerfile().Walk(r)
+
+			if len(r.events) != len(tt.expected) {
+				t.Fatalf("events = %v, want %v", r.events, tt.expected)
+			}
+			for i := range tt.expected {
+				if r.events[i] != tt.expected[i] {
+					t.Errorf("events = %v, want %v", r.events, tt.expected)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFindInstructions(t *testing.T) {
+	df := walkTestDockerfile()
+
+	froms := FindInstructions[*FromInstruction](df)
+	if len(froms) != 2 || froms[0].Image != "golang" || froms[1].Image != "alpine" {
+		t.Errorf("FindInstructions[*FromInstruction]() = %v, want golang and alpine", froms)
+	}
+	if runs := FindRun(df); len(runs) != 1 || runs[0].Line() != 3 {
+		t.Errorf("FindRun() = %v, want the RUN on line 3", runs)
+	}
+	if copies := FindCopy(df); len(copies) != 0 {
+		t.Errorf("FindCopy() returned %d instructions, want 0", len(copies))
+	}
+	if found := FindInstructions[*RunInstruction](nil); found != nil {
+		t.Errorf("FindInstructions(nil) = %v, want nil", found)
+	}
+
+	// The results are the AST nodes themselves
+	FindCmd(df)[0].Command = []string{"/bin/true"}
+	if got := df.Stages[1].Instructions[1].(*CmdInstruction).Command[0]; got != "/bin/true" {
+		t.Errorf("CMD after mutation = %q, want /bin/true", got)
+	}
+}
+
+func TestFindInstructionsByStage(t *testing.T) {
+	df := walkTestDockerfile()
+
+	if runs := FindInstructionsByStage[*RunInstruction](df.Stages[0]); len(runs) != 1 {
+		t.Errorf("builder stage has %d RUN instructions, want 1", len(runs))
+	}
+	if runs := FindInstructionsByStage[*RunInstruction](df.Stages[1]); len(runs) != 0 {
+		t.Errorf("final stage has %d RUN instructions, want 0", len(runs))
+	}
+	if froms := FindInstructionsByStage[*FromInstruction](df.Stages[1]); len(froms) != 1 || froms[0] != df.Stages[1].FromInstr {
+		t.Errorf("final stage FROM = %v, want %v", froms, df.Stages[1].FromInstr)
+	}
+}
+
+// benchmarkDockerfile returns a Dockerfile with n instructions of mixed types.
+func benchmarkDockerfile(n int) *Dockerfile {
+	df := &Dockerfile{}
+	for i := 0; i < n; i++ {
+		var instr Instruction
+		switch i % 3 {
+		case 0:
+			instr = &RunInstruction{LineNum: i + 1, Command: "make"}
+		case 1:
+			instr = &CopyInstruction{LineNum: i + 1, Sources: []string{"."}, Dest: "/app"}
+		default:
+			instr = &EnvInstruction{LineNum: i + 1, Key: "KEY", Value: "value"}
+		}
+		df.Instructions = append(df.Instructions, instr)
+	}
+	return df
+}
+
+func BenchmarkFindRun_Manual(b *testing.B) {
+	df := benchmarkDockerfile(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var runs []*RunInstruction
+		for _, instr := range df.Instructions {
+			if run, ok := instr.(*RunInstruction); ok {
+				runs = append(runs, run)
+			}
+		}
+		_ = runs
+	}
+}
+
+func BenchmarkFindRun_Generic(b *testing.B) {
+	df := benchmarkDockerfile(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = FindInstructions[*RunInstruction](df)
+	}
+}
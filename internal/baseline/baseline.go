@@ -0,0 +1,156 @@
+// Package baseline records the findings of a lint run so that later runs can
+// suppress them and report only new findings.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// Version is the version of the baseline file format.
+const Version = 1
+
+// Entry is a single known finding.
+type Entry struct {
+	File   string `json:"file"`
+	RuleID string `json:"rule_id"`
+	Line   int    `json:"line"`
+	// Hash identifies the finding's message, with line numbers in it removed.
+	Hash string `json:"hash"`
+}
+
+// Baseline is a set of known findings.
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"findings"`
+}
+
+// New returns an empty baseline.
+func New() *Baseline {
+	return &Baseline{Version: Version}
+}
+
+// Load reads a baseline file written by Write.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", b.Version, path)
+	}
+	return &b, nil
+}
+
+// Write writes the baseline to a file as indented JSON, sorted by file, line
+// and rule ID.
+func (b *Baseline) Write(path string) error {
+	entries := append([]Entry(nil), b.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].File != entries[j].File {
+			return entries[i].File < entries[j].File
+		}
+		if entries[i].Line != entries[j].Line {
+			return entries[i].Line < entries[j].Line
+		}
+		return entries[i].RuleID < entries[j].RuleID
+	})
+
+	data, err := json.MarshalIndent(Baseline{Version: Version, Entries: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Add records the findings of a file.
+func (b *Baseline) Add(file string, findings []ast.Finding) {
+	for _, finding := range findings {
+		b.Entries = append(b.Entries, Entry{
+			File:   normalizePath(file),
+			RuleID: finding.RuleID,
+			Line:   finding.Line,
+			Hash:   messageHash(finding.Message),
+		})
+	}
+}
+
+// Filter returns the findings of a file that are not in the baseline.
+//
+// Each entry suppresses at most one finding. Findings are first matched on
+// rule ID, line and message; the remaining ones are then matched on rule ID
+// and message alone, so findings whose line moved because lines were added
+// or removed above them are still recognized.
+func (b *Baseline) Filter(file string, findings []ast.Finding) []ast.Finding {
+	file = normalizePath(file)
+
+	var known []Entry
+	for _, entry := range b.Entries {
+		if entry.File == file {
+			known = append(known, entry)
+		}
+	}
+	used := make([]bool, len(known))
+	matched := make([]bool, len(findings))
+
+	match := func(sameLine bool) {
+		for i, finding := range findings {
+			if matched[i] {
+				continue
+			}
+			hash := messageHash(finding.Message)
+			for j, entry := range known {
+				if used[j] || entry.RuleID != finding.RuleID || entry.Hash != hash {
+					continue
+				}
+				if sameLine && entry.Line != finding.Line {
+					continue
+				}
+				used[j], matched[i] = true, true
+				break
+			}
+		}
+	}
+	match(true)
+	match(false)
+
+	var remaining []ast.Finding
+	for i, finding := range findings {
+		if !matched[i] {
+			remaining = append(remaining, finding)
+		}
+	}
+	return remaining
+}
+
+// lineNumbersPattern matches line references in messages, such as
+// "on line 4" or "on lines 1, 4".
+var lineNumbersPattern = regexp.MustCompile(`(?i)\b(lines?)\s+\d+(?:\s*,\s*\d+)*`)
+
+// messageHash returns the hash of a message with whitespace collapsed and
+// line references removed, so that it survives line drift.
+func messageHash(message string) string {
+	normalized := strings.Join(strings.Fields(message), " ")
+	normalized = lineNumbersPattern.ReplaceAllString(normalized, "$1 N")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:16])
+}
+
+// normalizePath makes paths recorded on one platform match on another.
+func normalizePath(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
@@ -0,0 +1,166 @@
+package baseline
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+func finding(ruleID string, line int, message string) ast.Finding {
+	return ast.Finding{RuleID: ruleID, Severity: ast.SeverityWarning, Line: line, Column: 1, Message: message}
+}
+
+func TestBaseline_WriteAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	b := New()
+	b.Add("web/Dockerfile", []ast.Finding{finding("DL3007", 1, "Using 'latest' tag for image 'ubuntu' is not recommended")})
+	b.Add("Dockerfile", []ast.Finding{
+		finding("DL4000", 5, "ENV instruction contains key 'DB_PASSWORD' which may contain a secret"),
+		finding("DL3006", 1, "Image 'alpine' has no explicit tag"),
+	})
+	if err := b.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Version != Version || len(loaded.Entries) != 3 {
+		t.Fatalf("Load() = %+v, want version %d with 3 entries", loaded, Version)
+	}
+
+	var got []string
+	for _, entry := range loaded.Entries {
+		if entry.Hash == "" {
+			t.Errorf("entry %+v has no message hash", entry)
+		}
+		got = append(got, entry.File+":"+entry.RuleID)
+	}
+	want := []string{"Dockerfile:DL3006", "Dockerfile:DL4000", "web/Dockerfile:DL3007"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want sorted %v", got, want)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load(missing) error = %v, want os.ErrNotExist", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(invalid); err == nil {
+		t.Error("Load(invalid JSON) error = nil, want an error")
+	}
+
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"version": 99, "findings": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(future); err == nil {
+		t.Error("Load(version 99) error = nil, want an error")
+	}
+}
+
+func TestBaseline_Filter(t *testing.T) {
+	known := []ast.Finding{
+		finding("DL3007", 1, "Using 'latest' tag for image 'ubuntu' is not recommended"),
+		finding("DL4012", 1, "Final stage (FROM on line 1) has no USER instruction and runs as root"),
+		finding("DL3009", 4, "Package manager cache not cleaned"),
+	}
+	b := New()
+	b.Add("Dockerfile", known)
+
+	tests := []struct {
+		name      string
+		file      string
+		findings  []ast.Finding
+		wantRules []string
+	}{
+		{
+			name:     "unchanged findings are suppressed",
+			file:     "Dockerfile",
+			findings: known,
+		},
+		{
+			name:     "path is normalized",
+			file:     "./Dockerfile",
+			findings: known[:1],
+		},
+		{
+			name:      "new finding is reported",
+			file:      "Dockerfile",
+			findings:  append(append([]ast.Finding(nil), known...), finding("DL4005", 6, "sudo used in RUN")),
+			wantRules: []string{"DL4005"},
+		},
+		{
+			name: "line drift is matched on rule and message",
+			file: "Dockerfile",
+			findings: []ast.Finding{
+				finding("DL3007", 3, "Using 'latest' tag for image 'ubuntu' is not recommended"),
+				finding("DL4012", 3, "Final stage (FROM on line 3) has no USER instruction and runs as root"),
+				finding("DL3009", 6, "Package manager cache not cleaned"),
+			},
+		},
+		{
+			name: "changed message is reported",
+			file: "Dockerfile",
+			findings: []ast.Finding{
+				finding("DL3007", 1, "Using 'latest' tag for image 'debian' is not recommended"),
+			},
+			wantRules: []string{"DL3007"},
+		},
+		{
+			name: "each entry suppresses one finding",
+			file: "Dockerfile",
+			findings: []ast.Finding{
+				finding("DL3009", 4, "Package manager cache not cleaned"),
+				finding("DL3009", 8, "Package manager cache not cleaned"),
+			},
+			wantRules: []string{"DL3009"},
+		},
+		{
+			name:      "other files are not suppressed",
+			file:      "api/Dockerfile",
+			findings:  known[:1],
+			wantRules: []string{"DL3007"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range b.Filter(tt.file, tt.findings) {
+				got = append(got, f.RuleID)
+			}
+			if !reflect.DeepEqual(got, tt.wantRules) {
+				t.Errorf("Filter() = %v, want %v", got, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestBaseline_FilterPrefersSameLine(t *testing.T) {
+	b := New()
+	b.Add("Dockerfile", []ast.Finding{finding("DL3009", 8, "Package manager cache not cleaned")})
+
+	// The finding that stayed on line 8 matches the entry, so the one on
+	// line 4 is new even though it was seen first
+	remaining := b.Filter("Dockerfile", []ast.Finding{
+		finding("DL3009", 4, "Package manager cache not cleaned"),
+		finding("DL3009", 8, "Package manager cache not cleaned"),
+	})
+	if len(remaining) != 1 || remaining[0].Line != 4 {
+		t.Errorf("Filter() = %+v, want the finding on line 4", remaining)
+	}
+}
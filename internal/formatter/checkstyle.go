@@ -0,0 +1,91 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// CheckstyleError represents a single finding in Checkstyle XML output.
+type CheckstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// CheckstyleFile represents the findings for one file in Checkstyle XML output.
+type CheckstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []CheckstyleError `xml:"error"`
+}
+
+// CheckstyleOutput represents the complete Checkstyle XML output structure.
+type CheckstyleOutput struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []CheckstyleFile `xml:"file"`
+}
+
+// CheckstyleFormatter formats findings as Checkstyle XML for Jenkins and other CI tools.
+type CheckstyleFormatter struct {
+	// Filename is the name of the file being analyzed.
+	Filename string
+	// Quiet suppresses informational findings in the output.
+	Quiet bool
+}
+
+// NewCheckstyleFormatter creates a new CheckstyleFormatter with the given filename.
+func NewCheckstyleFormatter(filename string, quiet bool) Formatter {
+	return &CheckstyleFormatter{
+		Filename: filename,
+		Quiet:    quiet,
+	}
+}
+
+// Format writes the findings to the given writer as Checkstyle XML.
+func (f *CheckstyleFormatter) Format(findings []ast.Finding, w io.Writer) error {
+	return writeCheckstyle(w, []CheckstyleFile{f.newFile(f.Filename, findings)})
+}
+
+// FormatFiles writes the findings of all files as a single Checkstyle XML
+// document with one file element per file.
+func (f *CheckstyleFormatter) FormatFiles(results []FileResult, w io.Writer) error {
+	files := make([]CheckstyleFile, 0, len(results))
+	for _, result := range results {
+		files = append(files, f.newFile(result.Filename, result.Findings))
+	}
+	return writeCheckstyle(w, files)
+}
+
+// newFile builds the Checkstyle file element for a single file.
+func (f *CheckstyleFormatter) newFile(filename string, findings []ast.Finding) CheckstyleFile {
+	file := CheckstyleFile{Name: filename}
+
+	for _, finding := range findings {
+		// Skip info-level findings in quiet mode
+		if f.Quiet && finding.Severity == ast.SeverityInfo {
+			continue
+		}
+
+		file.Errors = append(file.Errors, CheckstyleError{
+			Line:     finding.Line,
+			Column:   finding.Column,
+			Severity: finding.Severity.String(),
+			Message:  finding.Message,
+			Source:   finding.RuleID,
+		})
+	}
+
+	return file
+}
+
+// writeCheckstyle writes a complete Checkstyle XML document to the given writer.
+func writeCheckstyle(w io.Writer, files []CheckstyleFile) error {
+	return writeXML(w, CheckstyleOutput{
+		Version: "4.3",
+		Files:   files,
+	})
+}
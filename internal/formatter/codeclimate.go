@@ -0,0 +1,164 @@
+package formatter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// Code Climate severities, from least to most severe.
+const (
+	CodeClimateInfo     = "info"
+	CodeClimateMinor    = "minor"
+	CodeClimateMajor    = "major"
+	CodeClimateCritical = "critical"
+	CodeClimateBlocker  = "blocker"
+)
+
+// DefaultCodeClimateSeverities maps finding severities to the Code Climate
+// severities used when CodeClimateFormatterOptions does not override them.
+var DefaultCodeClimateSeverities = map[ast.Severity]string{
+	ast.SeverityError:   CodeClimateCritical,
+	ast.SeverityWarning: CodeClimateMajor,
+	ast.SeverityInfo:    CodeClimateMinor,
+}
+
+// CodeClimateLines represents the line range of an issue in Code Climate output.
+type CodeClimateLines struct {
+	Begin int `json:"begin"`
+}
+
+// CodeClimateLocation represents the location of an issue in Code Climate output.
+type CodeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines CodeClimateLines `json:"lines"`
+}
+
+// CodeClimateIssue represents a single finding in Code Climate JSON output.
+type CodeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    CodeClimateLocation `json:"location"`
+}
+
+// CodeClimateFormatterOptions configures a CodeClimateFormatter.
+type CodeClimateFormatterOptions struct {
+	// Severities maps finding severities to Code Climate severities ("info",
+	// "minor", "major", "critical" or "blocker"). Severities missing from the
+	// map use DefaultCodeClimateSeverities.
+	Severities map[ast.Severity]string
+}
+
+// CodeClimateFormatter formats findings as a Code Climate JSON array, the
+// format read by GitLab's Code Quality reports.
+type CodeClimateFormatter struct {
+	// Filename is the path of the file being analyzed, relative to the repository root.
+	Filename string
+	// Quiet suppresses informational findings in the output.
+	Quiet bool
+	// Options holds the severity mapping.
+	Options CodeClimateFormatterOptions
+	// RuleCategories maps rule IDs to rule categories. Issues of rules in the
+	// "security" category are reported in the Security category; all others,
+	// including rules missing from the map, in the Style category.
+	RuleCategories map[string]string
+}
+
+// NewCodeClimateFormatter creates a new CodeClimateFormatter with the given filename.
+func NewCodeClimateFormatter(filename string, quiet bool) Formatter {
+	return &CodeClimateFormatter{
+		Filename: filename,
+		Quiet:    quiet,
+	}
+}
+
+// Format writes the findings to the given writer as a Code Climate JSON array.
+func (f *CodeClimateFormatter) Format(findings []ast.Finding, w io.Writer) error {
+	issues, err := f.appendIssues(nil, f.Filename, findings)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, issues)
+}
+
+// FormatFiles writes the findings of all files as a single Code Climate JSON array.
+func (f *CodeClimateFormatter) FormatFiles(results []FileResult, w io.Writer) error {
+	var issues []CodeClimateIssue
+	for _, result := range results {
+		var err error
+		if issues, err = f.appendIssues(issues, result.Filename, result.Findings); err != nil {
+			return err
+		}
+	}
+	return writeJSON(w, issues)
+}
+
+// appendIssues appends the issues for the findings in a single file.
+func (f *CodeClimateFormatter) appendIssues(issues []CodeClimateIssue, filename string, findings []ast.Finding) ([]CodeClimateIssue, error) {
+	if issues == nil {
+		// Encode no findings as [] rather than null.
+		issues = []CodeClimateIssue{}
+	}
+
+	for _, finding := range findings {
+		// Skip info-level findings in quiet mode
+		if f.Quiet && finding.Severity == ast.SeverityInfo {
+			continue
+		}
+
+		severity, err := f.severity(finding.Severity)
+		if err != nil {
+			return nil, err
+		}
+
+		category := "Style"
+		if f.RuleCategories[finding.RuleID] == "security" {
+			category = "Security"
+		}
+
+		issues = append(issues, CodeClimateIssue{
+			Type:        "issue",
+			CheckName:   finding.RuleID,
+			Description: finding.Message,
+			Categories:  []string{category},
+			Severity:    severity,
+			Fingerprint: codeClimateFingerprint(filename, finding),
+			Location: CodeClimateLocation{
+				Path:  filename,
+				Lines: CodeClimateLines{Begin: finding.Line},
+			},
+		})
+	}
+
+	return issues, nil
+}
+
+// severity maps a finding severity to a Code Climate severity.
+func (f *CodeClimateFormatter) severity(s ast.Severity) (string, error) {
+	severity, ok := f.Options.Severities[s]
+	if !ok {
+		severity = DefaultCodeClimateSeverities[s]
+	}
+
+	switch severity {
+	case CodeClimateInfo, CodeClimateMinor, CodeClimateMajor, CodeClimateCritical, CodeClimateBlocker:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("invalid Code Climate severity %q for %s findings", severity, s)
+	}
+}
+
+// codeClimateFingerprint identifies an issue across runs, which GitLab uses
+// to tell new issues from resolved ones.
+func codeClimateFingerprint(filename string, finding ast.Finding) string {
+	sum := sha256.Sum256([]byte(filename + "\x00" + finding.RuleID + "\x00" + strconv.Itoa(finding.Line) + "\x00" + finding.Message))
+	return hex.EncodeToString(sum[:16])
+}
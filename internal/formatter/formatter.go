@@ -0,0 +1,49 @@
+// Package formatter provides output formatters for lint findings.
+package formatter
+
+import (
+	"io"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// Formatter writes lint findings to an output destination.
+//
+// Format must be safe to call multiple times on the same formatter with
+// different finding slices; implementations must not keep state between calls.
+type Formatter interface {
+	// Format writes the findings to the given writer.
+	Format(findings []ast.Finding, w io.Writer) error
+}
+
+// FormatterFunc adapts an ordinary function to the Formatter interface.
+type FormatterFunc func(findings []ast.Finding, w io.Writer) error
+
+// Format calls f(findings, w).
+func (f FormatterFunc) Format(findings []ast.Finding, w io.Writer) error {
+	return f(findings, w)
+}
+
+// skipFinding reports whether a finding is hidden by the quiet or minimum
+// severity options. Quiet is shorthand for a minimum severity of warning.
+func skipFinding(finding ast.Finding, quiet bool, minSeverity ast.Severity) bool {
+	if quiet && minSeverity < ast.SeverityWarning {
+		minSeverity = ast.SeverityWarning
+	}
+	return finding.Severity < minSeverity
+}
+
+// FileResult holds the findings for a single analyzed file.
+type FileResult struct {
+	Filename string
+	Findings []ast.Finding
+	// Source optionally holds the lines of the file, for formatters that
+	// show source snippets.
+	Source []string
+}
+
+// MultiFormatter writes the findings of several files as one output document.
+type MultiFormatter interface {
+	// FormatFiles writes the findings of each file to the given writer.
+	FormatFiles(results []FileResult, w io.Writer) error
+}
@@ -4,6 +4,12 @@ package formatter
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -136,6 +142,84 @@ func TestTextFormatter_Format(t *testing.T) {
 	}
 }
 
+// fakeTerminal is a writer that reports itself as a character device, like
+// a terminal.
+type fakeTerminal struct {
+	bytes.Buffer
+}
+
+func (t *fakeTerminal) Stat() (os.FileInfo, error) { return terminalInfo{}, nil }
+
+// terminalInfo is the os.FileInfo of a fakeTerminal.
+type terminalInfo struct {
+	os.FileInfo
+}
+
+func (terminalInfo) Mode() os.FileMode { return os.ModeDevice | os.ModeCharDevice }
+
+func TestTextFormatter_ColorOutput(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL3000", Severity: ast.SeverityError, Line: 1, Column: 1, Message: "Invalid syntax"},
+		{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 2, Column: 1, Message: "Missing explicit image tag"},
+		{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 3, Column: 1, Message: "Wildcard in COPY source"},
+	}
+
+	w := &fakeTerminal{}
+	if !IsTerminal(w) {
+		t.Fatal("IsTerminal() = false for a terminal, want true")
+	}
+
+	f := &TextFormatter{Filename: "Dockerfile", Color: IsTerminal(w)}
+	if err := f.Format(findings, w); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	output := w.String()
+	for _, want := range []string{
+		"Dockerfile:1:1: \x1b[31m[error]\x1b[0m \x1b[1mDL3000\x1b[0m: Invalid syntax",
+		"Dockerfile:2:1: \x1b[33m[warning]\x1b[0m \x1b[1mDL3006\x1b[0m: Missing explicit image tag",
+		"Dockerfile:3:1: \x1b[34m[info]\x1b[0m \x1b[1mDL5001\x1b[0m: Wildcard in COPY source",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Format() output missing expected string: %q\nGot: %q", want, output)
+		}
+	}
+
+	var buf bytes.Buffer
+	f = &TextFormatter{Filename: "Dockerfile", Color: IsTerminal(&buf)}
+	if err := f.Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("Format() output to a buffer contains escape codes: %q", buf.String())
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if IsTerminal(&bytes.Buffer{}) {
+		t.Error("IsTerminal(buffer) = true, want false")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if IsTerminal(w) {
+		t.Error("IsTerminal(pipe) = true, want false")
+	}
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if IsTerminal(file) {
+		t.Error("IsTerminal(regular file) = true, want false")
+	}
+}
+
 func TestJSONFormatter_Format(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -282,6 +366,100 @@ func TestJSONFormatter_Format(t *testing.T) {
 	}
 }
 
+func TestFormatters_Stage(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL3010", Severity: ast.SeverityWarning, Line: 3, Column: 1, Message: "consecutive RUN", StageIndex: 0, StageName: "builder"},
+		{RuleID: "DL3001", Severity: ast.SeverityWarning, Line: 7, Column: 1, Message: "multiple CMD", StageIndex: 1},
+		{RuleID: "DL3029", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "outside any stage", StageIndex: -1},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var plain, staged bytes.Buffer
+		if err := NewTextFormatter("Dockerfile", false).Format(findings, &plain); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		if strings.Contains(plain.String(), "[stage:") {
+			t.Errorf("stage shown without ShowStage:\n%s", plain.String())
+		}
+
+		f := &TextFormatter{Filename: "Dockerfile", ShowStage: true}
+		if err := f.Format(findings, &staged); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		for _, want := range []string{
+			"DL3010: consecutive RUN [stage: builder]",
+			"DL3001: multiple CMD [stage: 1]",
+			"DL3029: outside any stage\n",
+		} {
+			if !strings.Contains(staged.String(), want) {
+				t.Errorf("output missing %q:\n%s", want, staged.String())
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewJSONFormatter("Dockerfile", false).Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		var output JSONOutput
+		if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+			t.Fatalf("Format() produced invalid JSON: %v", err)
+		}
+
+		if s := output.Findings[0].Stage; s == nil || s.Index != 0 || s.Name != "builder" {
+			t.Errorf("finding 0 stage = %+v, want builder", s)
+		}
+		if s := output.Findings[1].Stage; s == nil || s.Index != 1 || s.Name != "" {
+			t.Errorf("finding 1 stage = %+v, want index 1", s)
+		}
+		if s := output.Findings[2].Stage; s != nil {
+			t.Errorf("finding 2 stage = %+v, want none", s)
+		}
+	})
+}
+
+func TestFormatters_CategoryAndFix(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL4004", Severity: ast.SeverityWarning, Line: 2, Column: 1, Message: "use COPY", StageIndex: -1, RuleCategory: "security", FixAvailable: true},
+		{RuleID: "DL3001", Severity: ast.SeverityWarning, Line: 5, Column: 1, Message: "multiple CMD", StageIndex: -1, RuleCategory: "correctness"},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewTextFormatter("Dockerfile", false).Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "DL4004: use COPY [fix available]\n") {
+			t.Errorf("output missing fix annotation:\n%s", buf.String())
+		}
+		if strings.Contains(buf.String(), "multiple CMD [fix available]") {
+			t.Errorf("fix annotation shown for finding without a fix:\n%s", buf.String())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewJSONFormatter("Dockerfile", false).Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		var output JSONOutput
+		if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+			t.Fatalf("Format() produced invalid JSON: %v", err)
+		}
+
+		if f := output.Findings[0]; f.Category != "security" || !f.FixAvailable {
+			t.Errorf("finding 0 category = %q, fix_available = %v, want security, true", f.Category, f.FixAvailable)
+		}
+		if f := output.Findings[1]; f.Category != "correctness" || f.FixAvailable {
+			t.Errorf("finding 1 category = %q, fix_available = %v, want correctness, false", f.Category, f.FixAvailable)
+		}
+		if strings.Count(buf.String(), `"fix_available"`) != 1 {
+			t.Errorf("fix_available should be omitted when false:\n%s", buf.String())
+		}
+	})
+}
+
 func TestJSONFormatter_SchemaConformance(t *testing.T) {
 	findings := []ast.Finding{
 		{
@@ -332,3 +510,816 @@ func TestJSONFormatter_SchemaConformance(t *testing.T) {
 		t.Errorf("Finding suggestion = %q, want %q", f.Suggestion, "Use 'FROM alpine:3.18' instead of 'FROM alpine'")
 	}
 }
+
+func TestGitHubActionsFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		quiet    bool
+		findings []ast.Finding
+		want     []string
+		notWant  []string
+	}{
+		{
+			name: "severity levels map to workflow commands",
+			findings: []ast.Finding{
+				{RuleID: "DL3000", Severity: ast.SeverityError, Line: 1, Column: 1, Message: "Error message"},
+				{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 2, Column: 3, Message: "Warning message"},
+				{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 4, Column: 1, Message: "Info message"},
+			},
+			want: []string{
+				"::error file=Dockerfile,line=1,col=1,title=DL3000::Error message\n",
+				"::warning file=Dockerfile,line=2,col=3,title=DL3006::Warning message\n",
+				"::notice file=Dockerfile,line=4,col=1,title=DL5001::Info message\n",
+			},
+		},
+		{
+			name: "suggestion and special characters are escaped",
+			findings: []ast.Finding{
+				{RuleID: "DL3009", Severity: ast.SeverityWarning, Line: 5, Column: 1, Message: "100% cache", Suggestion: "Clean up"},
+			},
+			want: []string{
+				"::warning file=Dockerfile,line=5,col=1,title=DL3009::100%25 cache%0ASuggestion: Clean up\n",
+			},
+		},
+		{
+			name:  "quiet mode filters info",
+			quiet: true,
+			findings: []ast.Finding{
+				{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 3, Column: 1, Message: "Info message"},
+			},
+			notWant: []string{"::notice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := NewGitHubActionsFormatter("Dockerfile", tt.quiet)
+			var buf bytes.Buffer
+
+			if err := formatter.Format(tt.findings, &buf); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			output := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Format() output missing expected string: %q\nGot: %s", want, output)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(output, notWant) {
+					t.Errorf("Format() output contains unexpected string: %q\nGot: %s", notWant, output)
+				}
+			}
+		})
+	}
+}
+
+func TestEscapeGitHubProperty(t *testing.T) {
+	got := escapeGitHubProperty("dir,a:b/Dockerfile")
+	want := "dir%2Ca%3Ab/Dockerfile"
+	if got != want {
+		t.Errorf("escapeGitHubProperty() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckstyleFormatter_Format(t *testing.T) {
+	findings := []ast.Finding{
+		{
+			RuleID:   "DL3006",
+			Severity: ast.SeverityWarning,
+			Line:     1,
+			Column:   1,
+			Message:  `Image "ubuntu" <no tag> & 'latest'`,
+		},
+		{
+			RuleID:   "DL4006",
+			Severity: ast.SeverityError,
+			Line:     4,
+			Column:   2,
+			Message:  "COPY copies the .git directory into the image",
+		},
+		{
+			RuleID:   "DL5001",
+			Severity: ast.SeverityInfo,
+			Line:     6,
+			Column:   1,
+			Message:  "Wildcard in COPY source",
+		},
+	}
+
+	formatter := NewCheckstyleFormatter("Dockerfile", false)
+	var buf bytes.Buffer
+
+	if err := formatter.Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var output CheckstyleOutput
+	if err := xml.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Format() produced invalid XML: %v\nOutput: %s", err, buf.String())
+	}
+
+	if len(output.Files) != 1 || output.Files[0].Name != "Dockerfile" {
+		t.Fatalf("Format() files = %+v, want one file named Dockerfile", output.Files)
+	}
+
+	errs := output.Files[0].Errors
+	if len(errs) != len(findings) {
+		t.Fatalf("Format() errors count = %d, want %d", len(errs), len(findings))
+	}
+
+	for i, f := range findings {
+		if errs[i].Message != f.Message {
+			t.Errorf("error[%d] message = %q, want %q", i, errs[i].Message, f.Message)
+		}
+		if errs[i].Source != f.RuleID {
+			t.Errorf("error[%d] source = %q, want %q", i, errs[i].Source, f.RuleID)
+		}
+		if errs[i].Severity != f.Severity.String() {
+			t.Errorf("error[%d] severity = %q, want %q", i, errs[i].Severity, f.Severity.String())
+		}
+		if errs[i].Line != f.Line || errs[i].Column != f.Column {
+			t.Errorf("error[%d] position = %d:%d, want %d:%d", i, errs[i].Line, errs[i].Column, f.Line, f.Column)
+		}
+	}
+}
+
+func TestCheckstyleFormatter_QuietAndEmpty(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 3, Column: 1, Message: "Info message"},
+	}
+
+	formatter := NewCheckstyleFormatter("Dockerfile", true)
+	var buf bytes.Buffer
+
+	if err := formatter.Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var output CheckstyleOutput
+	if err := xml.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Format() produced invalid XML: %v", err)
+	}
+
+	if len(output.Files) != 1 || len(output.Files[0].Errors) != 0 {
+		t.Errorf("Format() expected one file with no errors, got %+v", output.Files)
+	}
+}
+
+func TestFormatFiles(t *testing.T) {
+	results := []FileResult{
+		{
+			Filename: "Dockerfile",
+			Findings: []ast.Finding{
+				{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag"},
+			},
+		},
+		{
+			Filename: "services/api/Dockerfile",
+			Findings: []ast.Finding{
+				{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 3, Column: 1, Message: "Wildcard in COPY source"},
+			},
+		},
+	}
+
+	t.Run("text sections", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewTextFormatter("", false).(MultiFormatter).FormatFiles(results, &buf); err != nil {
+			t.Fatalf("FormatFiles() error = %v", err)
+		}
+		output := buf.String()
+		for _, want := range []string{
+			"==> Dockerfile <==\nDockerfile:1:1: [warning] DL3006",
+			"\n\n==> services/api/Dockerfile <==\nservices/api/Dockerfile:3:1: [info] DL5001",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("output missing %q:\n%s", want, output)
+			}
+		}
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewJSONFormatter("", true).(MultiFormatter).FormatFiles(results, &buf); err != nil {
+			t.Fatalf("FormatFiles() error = %v", err)
+		}
+		var outputs []JSONOutput
+		if err := json.Unmarshal(buf.Bytes(), &outputs); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(outputs) != 2 {
+			t.Fatalf("expected 2 file outputs, got %d", len(outputs))
+		}
+		if outputs[0].File != "Dockerfile" || outputs[0].Summary.Total != 1 {
+			t.Errorf("unexpected first output: %+v", outputs[0])
+		}
+		if outputs[1].File != "services/api/Dockerfile" || outputs[1].Summary.Total != 0 {
+			t.Errorf("expected quiet mode to drop info finding, got %+v", outputs[1])
+		}
+	})
+
+	t.Run("checkstyle files", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewCheckstyleFormatter("", false).(MultiFormatter).FormatFiles(results, &buf); err != nil {
+			t.Fatalf("FormatFiles() error = %v", err)
+		}
+		var output CheckstyleOutput
+		if err := xml.Unmarshal(buf.Bytes(), &output); err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+		if len(output.Files) != 2 || output.Files[1].Name != "services/api/Dockerfile" {
+			t.Errorf("unexpected files: %+v", output.Files)
+		}
+	})
+
+	t.Run("github annotations", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewGitHubActionsFormatter("", false).(MultiFormatter)
+		if err := f.FormatFiles(results, &buf); err != nil {
+			t.Fatalf("FormatFiles() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "file=services/api/Dockerfile,line=3") {
+			t.Errorf("missing annotation for second file:\n%s", buf.String())
+		}
+	})
+}
+
+func TestFormatterFunc(t *testing.T) {
+	var f Formatter = FormatterFunc(func(findings []ast.Finding, w io.Writer) error {
+		for _, finding := range findings {
+			if _, err := io.WriteString(w, finding.RuleID+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	findings := []ast.Finding{{RuleID: "DL3006"}, {RuleID: "DL4000"}}
+	if err := f.Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if got, want := buf.String(), "DL3006\nDL4000\n"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestTeeFormatter(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag", StageIndex: -1},
+		{RuleID: "DL4000", Severity: ast.SeverityError, Line: 4, Column: 1, Message: "Secret in ENV", StageIndex: -1},
+	}
+
+	// recorder records the findings it is given and writes their rule IDs
+	recorder := func(got *[]ast.Finding) Formatter {
+		return FormatterFunc(func(findings []ast.Finding, w io.Writer) error {
+			*got = append(*got, findings...)
+			for _, finding := range findings {
+				if _, err := io.WriteString(w, finding.RuleID+"\n"); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	t.Run("same writer", func(t *testing.T) {
+		var first, second []ast.Finding
+		var buf bytes.Buffer
+		if err := NewTeeFormatter(recorder(&first), recorder(&second)).Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		if !reflect.DeepEqual(first, findings) || !reflect.DeepEqual(second, findings) {
+			t.Errorf("formatters got %v and %v, want %v", first, second, findings)
+		}
+		if got, want := buf.String(), "DL3006\nDL4000\nDL3006\nDL4000\n"; got != want {
+			t.Errorf("Format() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("targets", func(t *testing.T) {
+		var stdout, file bytes.Buffer
+		tee := NewTeeFormatterWithTargets([]FormatterTarget{
+			{Formatter: NewTextFormatter("Dockerfile", false)},
+			{Formatter: NewJSONFormatter("Dockerfile", false), Writer: &file},
+		})
+		if err := tee.Format(findings, &stdout); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+
+		if !strings.Contains(stdout.String(), "Dockerfile:4:1: [error] DL4000: Secret in ENV") {
+			t.Errorf("text output missing finding:\n%s", stdout.String())
+		}
+		var output JSONOutput
+		if err := json.Unmarshal(file.Bytes(), &output); err != nil {
+			t.Fatalf("JSON target is invalid: %v\n%s", err, file.String())
+		}
+		if output.Summary.Total != len(findings) {
+			t.Errorf("JSON summary.total = %d, want %d", output.Summary.Total, len(findings))
+		}
+	})
+
+	t.Run("several files", func(t *testing.T) {
+		results := []FileResult{
+			{Filename: "a/Dockerfile", Findings: findings[:1]},
+			{Filename: "b/Dockerfile", Findings: findings[1:]},
+		}
+		var stdout, file bytes.Buffer
+		tee := NewTeeFormatterWithTargets([]FormatterTarget{
+			{Formatter: NewTextFormatter("", false)},
+			{Formatter: NewJSONFormatter("", false), Writer: &file},
+		})
+		if err := tee.FormatFiles(results, &stdout); err != nil {
+			t.Fatalf("FormatFiles() error = %v", err)
+		}
+		if !strings.Contains(stdout.String(), "b/Dockerfile:4:1") {
+			t.Errorf("text output missing second file:\n%s", stdout.String())
+		}
+		var outputs []JSONOutput
+		if err := json.Unmarshal(file.Bytes(), &outputs); err != nil || len(outputs) != 2 {
+			t.Errorf("JSON target = %s, want an array of 2 files (err %v)", file.String(), err)
+		}
+
+		var got []ast.Finding
+		err := NewTeeFormatter(recorder(&got)).FormatFiles(results, &stdout)
+		if err == nil {
+			t.Error("FormatFiles() with a single-file formatter should fail")
+		}
+	})
+
+	t.Run("errors do not stop other targets", func(t *testing.T) {
+		failing := FormatterFunc(func([]ast.Finding, io.Writer) error { return errors.New("disk full") })
+		var got []ast.Finding
+		var buf bytes.Buffer
+		err := NewTeeFormatter(failing, recorder(&got)).Format(findings, &buf)
+		if err == nil || !strings.Contains(err.Error(), "disk full") {
+			t.Errorf("Format() error = %v, want disk full", err)
+		}
+		if !reflect.DeepEqual(got, findings) {
+			t.Errorf("second formatter got %v, want %v", got, findings)
+		}
+	})
+}
+
+func TestFormatters_Reusable(t *testing.T) {
+	first := []ast.Finding{
+		{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag"},
+	}
+	second := []ast.Finding{
+		{RuleID: "DL4000", Severity: ast.SeverityError, Line: 4, Column: 1, Message: "Secret in ENV"},
+	}
+
+	formatters := map[string]Formatter{
+		"text":        NewTextFormatter("Dockerfile", false),
+		"json":        NewJSONFormatter("Dockerfile", false),
+		"github":      NewGitHubActionsFormatter("Dockerfile", false),
+		"checkstyle":  NewCheckstyleFormatter("Dockerfile", false),
+		"junit":       NewJUnitFormatter("Dockerfile", false),
+		"codeclimate": NewCodeClimateFormatter("Dockerfile", false),
+	}
+
+	for name, f := range formatters {
+		t.Run(name, func(t *testing.T) {
+			var before, other, after bytes.Buffer
+			if err := f.Format(first, &before); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if err := f.Format(second, &other); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if err := f.Format(first, &after); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if before.String() != after.String() {
+				t.Errorf("output changed between calls:\nfirst:\n%s\nagain:\n%s", before.String(), after.String())
+			}
+			if strings.Contains(other.String(), "DL3006") {
+				t.Errorf("second call leaked findings from the first:\n%s", other.String())
+			}
+		})
+	}
+}
+
+func TestJUnitFormatter_Format(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag", Suggestion: "Pin a tag"},
+		{RuleID: "DL4006", Severity: ast.SeverityError, Line: 4, Column: 1, Message: "COPY copies the .git directory"},
+		{RuleID: "DL4006", Severity: ast.SeverityError, Line: 6, Column: 1, Message: "ADD copies the .git directory"},
+		{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 7, Column: 1, Message: "Wildcard in COPY source"},
+	}
+
+	tests := []struct {
+		name          string
+		quiet         bool
+		ruleIDs       []string
+		wantTests     int
+		wantFailures  int
+		wantTestNames []string
+	}{
+		{
+			name:          "rules without findings pass",
+			ruleIDs:       []string{"DL3006", "DL3007", "DL4006", "DL5001"},
+			wantTests:     5,
+			wantFailures:  3,
+			wantTestNames: []string{"DL3006", "DL3007", "DL4006", "DL4006", "DL5001"},
+		},
+		{
+			name:          "quiet drops info findings",
+			quiet:         true,
+			ruleIDs:       []string{"DL3006", "DL4006"},
+			wantTests:     3,
+			wantFailures:  3,
+			wantTestNames: []string{"DL3006", "DL4006", "DL4006"},
+		},
+		{
+			name:          "without rule list only findings are reported",
+			wantTests:     4,
+			wantFailures:  3,
+			wantTestNames: []string{"DL3006", "DL4006", "DL4006", "DL5001"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := NewJUnitFormatter("Dockerfile", tt.quiet).(*JUnitFormatter)
+			formatter.RuleIDs = tt.ruleIDs
+
+			var buf bytes.Buffer
+			if err := formatter.Format(findings, &buf); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			var suite JUnitTestSuite
+			if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+				t.Fatalf("Format() produced invalid XML: %v\nOutput: %s", err, buf.String())
+			}
+
+			if suite.Name != "Dockerfile" {
+				t.Errorf("suite name = %q, want %q", suite.Name, "Dockerfile")
+			}
+			if suite.Tests != tt.wantTests || len(suite.TestCases) != tt.wantTests {
+				t.Errorf("tests = %d (%d testcases), want %d", suite.Tests, len(suite.TestCases), tt.wantTests)
+			}
+
+			failures := 0
+			for i, tc := range suite.TestCases {
+				if tc.Failure != nil {
+					failures++
+				}
+				if i < len(tt.wantTestNames) && tc.Name != tt.wantTestNames[i] {
+					t.Errorf("testcase[%d] name = %q, want %q", i, tc.Name, tt.wantTestNames[i])
+				}
+			}
+			if suite.Failures != tt.wantFailures || failures != tt.wantFailures {
+				t.Errorf("failures = %d (%d failure elements), want %d", suite.Failures, failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestJUnitFormatter_FormatFiles(t *testing.T) {
+	results := []FileResult{
+		{Filename: "Dockerfile"},
+		{
+			Filename: "api/Dockerfile",
+			Findings: []ast.Finding{
+				{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag"},
+			},
+		},
+	}
+
+	formatter := NewJUnitFormatter("", false).(*JUnitFormatter)
+	formatter.RuleIDs = []string{"DL3006"}
+
+	var buf bytes.Buffer
+	if err := formatter.FormatFiles(results, &buf); err != nil {
+		t.Fatalf("FormatFiles() error = %v", err)
+	}
+
+	var suites JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("FormatFiles() produced invalid XML: %v", err)
+	}
+	if len(suites.Suites) != 2 {
+		t.Fatalf("expected 2 test suites, got %d", len(suites.Suites))
+	}
+	if suites.Suites[0].Failures != 0 || suites.Suites[1].Failures != 1 {
+		t.Errorf("unexpected failures: %d, %d", suites.Suites[0].Failures, suites.Suites[1].Failures)
+	}
+}
+
+func TestFormatters_MinSeverity(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL4006", Severity: ast.SeverityError, Line: 1, Column: 1, Message: "Error message"},
+		{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 2, Column: 1, Message: "Warning message"},
+		{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 3, Column: 1, Message: "Info message"},
+	}
+
+	tests := []struct {
+		name        string
+		quiet       bool
+		minSeverity ast.Severity
+		wantRules   []string
+	}{
+		{"info shows all", false, ast.SeverityInfo, []string{"DL4006", "DL3006", "DL5001"}},
+		{"warning hides info", false, ast.SeverityWarning, []string{"DL4006", "DL3006"}},
+		{"error shows errors only", false, ast.SeverityError, []string{"DL4006"}},
+		{"quiet acts as warning", true, ast.SeverityInfo, []string{"DL4006", "DL3006"}},
+		{"quiet does not lower error", true, ast.SeverityError, []string{"DL4006"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var text bytes.Buffer
+			tf := &TextFormatter{Filename: "Dockerfile", Quiet: tt.quiet, MinSeverity: tt.minSeverity}
+			if err := tf.Format(findings, &text); err != nil {
+				t.Fatalf("TextFormatter.Format() error = %v", err)
+			}
+			if lines := strings.Count(text.String(), "\n"); lines != len(tt.wantRules) {
+				t.Errorf("text output has %d lines, want %d:\n%s", lines, len(tt.wantRules), text.String())
+			}
+
+			var buf bytes.Buffer
+			jf := &JSONFormatter{Filename: "Dockerfile", Quiet: tt.quiet, MinSeverity: tt.minSeverity}
+			if err := jf.Format(findings, &buf); err != nil {
+				t.Fatalf("JSONFormatter.Format() error = %v", err)
+			}
+			var output JSONOutput
+			if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if output.Summary.Total != len(tt.wantRules) || len(output.Findings) != len(tt.wantRules) {
+				t.Errorf("JSON summary total = %d with %d findings, want %d", output.Summary.Total, len(output.Findings), len(tt.wantRules))
+			}
+			for i, rule := range tt.wantRules {
+				if i < len(output.Findings) && output.Findings[i].RuleID != rule {
+					t.Errorf("JSON finding %d = %s, want %s", i, output.Findings[i].RuleID, rule)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONFormatter_Snippets(t *testing.T) {
+	source := []string{"FROM ubuntu", "RUN apt-get update", "USER app"}
+	findings := []ast.Finding{
+		{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "untagged"},
+		{RuleID: "DL3012", Severity: ast.SeverityWarning, Line: 2, Column: 1, Message: "update"},
+		{RuleID: "DL4002", Severity: ast.SeverityWarning, Line: 3, Column: 1, Message: "user"},
+		{RuleID: "DL9999", Severity: ast.SeverityWarning, Line: 9, Column: 1, Message: "out of range"},
+	}
+
+	format := func(t *testing.T, f *JSONFormatter) JSONOutput {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := f.Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		var output JSONOutput
+		if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		return output
+	}
+
+	t.Run("with source", func(t *testing.T) {
+		jf := &JSONFormatter{Filename: "Dockerfile"}
+		jf.SetSource(source)
+		output := format(t, jf)
+
+		expected := []*JSONSnippet{
+			{Text: "FROM ubuntu", StartLine: 1, Lines: []string{"FROM ubuntu", "RUN apt-get update"}},
+			{Text: "RUN apt-get update", StartLine: 1, Lines: source},
+			{Text: "USER app", StartLine: 2, Lines: []string{"RUN apt-get update", "USER app"}},
+			nil,
+		}
+		for i, want := range expected {
+			if got := output.Findings[i].Snippet; !reflect.DeepEqual(got, want) {
+				t.Errorf("finding on line %d: snippet = %+v, want %+v", output.Findings[i].Line, got, want)
+			}
+		}
+	})
+
+	t.Run("without source", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewJSONFormatter("Dockerfile", false).Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		if strings.Contains(buf.String(), "snippet") {
+			t.Errorf("expected no snippet fields without source:\n%s", buf.String())
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		results := []FileResult{
+			{Filename: "a/Dockerfile", Findings: findings[1:2], Source: source},
+			{Filename: "b/Dockerfile", Findings: findings[1:2]},
+		}
+		var buf bytes.Buffer
+		if err := (&JSONFormatter{}).FormatFiles(results, &buf); err != nil {
+			t.Fatalf("FormatFiles() error = %v", err)
+		}
+		var outputs []JSONOutput
+		if err := json.Unmarshal(buf.Bytes(), &outputs); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if outputs[0].Findings[0].Snippet == nil || outputs[0].Findings[0].Snippet.Text != "RUN apt-get update" {
+			t.Errorf("first file: snippet = %+v, want the RUN line", outputs[0].Findings[0].Snippet)
+		}
+		if outputs[1].Findings[0].Snippet != nil {
+			t.Errorf("second file: snippet = %+v, want none", outputs[1].Findings[0].Snippet)
+		}
+	})
+}
+
+func TestCodeClimateFormatter_Format(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL4000", Severity: ast.SeverityError, Line: 3, Column: 1, Message: "Secret in ENV"},
+		{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag"},
+		{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 6, Column: 1, Message: "Wildcard in COPY source"},
+	}
+
+	f := &CodeClimateFormatter{
+		Filename:       "build/Dockerfile",
+		RuleCategories: map[string]string{"DL4000": "security", "DL3006": "best-practice"},
+	}
+	var buf bytes.Buffer
+	if err := f.Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	// Decode generically so the test checks the field names GitLab reads.
+	var issues []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("Format() produced invalid JSON: %v\nOutput: %s", err, buf.String())
+	}
+	if len(issues) != len(findings) {
+		t.Fatalf("Format() issues count = %d, want %d", len(issues), len(findings))
+	}
+
+	wantSeverities := []string{"critical", "major", "minor"}
+	wantCategories := []string{"Security", "Style", "Style"}
+	fingerprints := make(map[string]bool)
+
+	for i, issue := range issues {
+		for _, field := range []string{"type", "check_name", "description", "categories", "severity", "fingerprint", "location"} {
+			if _, ok := issue[field]; !ok {
+				t.Errorf("issue[%d] is missing %q: %v", i, field, issue)
+			}
+		}
+		if issue["type"] != "issue" {
+			t.Errorf("issue[%d] type = %v, want issue", i, issue["type"])
+		}
+		if issue["check_name"] != findings[i].RuleID {
+			t.Errorf("issue[%d] check_name = %v, want %s", i, issue["check_name"], findings[i].RuleID)
+		}
+		if issue["description"] != findings[i].Message {
+			t.Errorf("issue[%d] description = %v, want %q", i, issue["description"], findings[i].Message)
+		}
+		if issue["severity"] != wantSeverities[i] {
+			t.Errorf("issue[%d] severity = %v, want %s", i, issue["severity"], wantSeverities[i])
+		}
+		if categories, _ := issue["categories"].([]interface{}); len(categories) != 1 || categories[0] != wantCategories[i] {
+			t.Errorf("issue[%d] categories = %v, want [%s]", i, issue["categories"], wantCategories[i])
+		}
+
+		location, _ := issue["location"].(map[string]interface{})
+		lines, _ := location["lines"].(map[string]interface{})
+		if location["path"] != "build/Dockerfile" || lines["begin"] != float64(findings[i].Line) {
+			t.Errorf("issue[%d] location = %v, want build/Dockerfile line %d", i, issue["location"], findings[i].Line)
+		}
+
+		fingerprint, _ := issue["fingerprint"].(string)
+		if fingerprint == "" || fingerprints[fingerprint] {
+			t.Errorf("issue[%d] fingerprint %q is empty or not unique", i, fingerprint)
+		}
+		fingerprints[fingerprint] = true
+	}
+}
+
+func TestCodeClimateFormatter_Options(t *testing.T) {
+	findings := []ast.Finding{
+		{RuleID: "DL4000", Severity: ast.SeverityError, Line: 3, Column: 1, Message: "Secret in ENV"},
+		{RuleID: "DL5001", Severity: ast.SeverityInfo, Line: 6, Column: 1, Message: "Wildcard in COPY source"},
+	}
+
+	t.Run("severity mapping", func(t *testing.T) {
+		f := &CodeClimateFormatter{
+			Filename: "Dockerfile",
+			Options: CodeClimateFormatterOptions{
+				Severities: map[ast.Severity]string{ast.SeverityError: CodeClimateBlocker},
+			},
+		}
+		var buf bytes.Buffer
+		if err := f.Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		var issues []CodeClimateIssue
+		if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+			t.Fatalf("Format() produced invalid JSON: %v", err)
+		}
+		if len(issues) != 2 || issues[0].Severity != "blocker" || issues[1].Severity != "minor" {
+			t.Errorf("Format() issues = %+v, want blocker and minor", issues)
+		}
+	})
+
+	t.Run("invalid severity", func(t *testing.T) {
+		f := &CodeClimateFormatter{
+			Filename: "Dockerfile",
+			Options: CodeClimateFormatterOptions{
+				Severities: map[ast.Severity]string{ast.SeverityInfo: "trivial"},
+			},
+		}
+		if err := f.Format(findings, io.Discard); err == nil {
+			t.Error("Format() error = nil, want an error for an unknown severity")
+		}
+	})
+
+	t.Run("quiet and empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewCodeClimateFormatter("Dockerfile", true).Format(findings[1:], &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		if got := strings.TrimSpace(buf.String()); got != "[]" {
+			t.Errorf("Format() = %q, want []", got)
+		}
+	})
+}
+
+func TestCodeClimateFormatter_FormatFiles(t *testing.T) {
+	results := []FileResult{
+		{Filename: "Dockerfile", Findings: []ast.Finding{
+			{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag"},
+		}},
+		{Filename: "api/Dockerfile"},
+		{Filename: "web/Dockerfile", Findings: []ast.Finding{
+			{RuleID: "DL3006", Severity: ast.SeverityWarning, Line: 1, Column: 1, Message: "Missing explicit image tag"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewCodeClimateFormatter("", false).(MultiFormatter).FormatFiles(results, &buf); err != nil {
+		t.Fatalf("FormatFiles() error = %v", err)
+	}
+
+	var issues []CodeClimateIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("FormatFiles() produced invalid JSON: %v\nOutput: %s", err, buf.String())
+	}
+	if len(issues) != 2 || issues[0].Location.Path != "Dockerfile" || issues[1].Location.Path != "web/Dockerfile" {
+		t.Fatalf("FormatFiles() issues = %+v, want one issue each for Dockerfile and web/Dockerfile", issues)
+	}
+	if issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Errorf("issues in different files share fingerprint %s", issues[0].Fingerprint)
+	}
+}
+
+// benchmarkFindings returns n findings with mixed severities and suggestions.
+func benchmarkFindings(n int) []ast.Finding {
+	severities := []ast.Severity{ast.SeverityError, ast.SeverityWarning, ast.SeverityInfo}
+	findings := make([]ast.Finding, n)
+	for i := range findings {
+		findings[i] = ast.Finding{
+			RuleID:     "DL3006",
+			Severity:   severities[i%len(severities)],
+			Line:       i + 1,
+			Column:     1,
+			Message:    "Image 'ubuntu' has no explicit tag",
+			Suggestion: "Pin the image to a specific version, e.g. ubuntu:22.04",
+		}
+	}
+	return findings
+}
+
+// BenchmarkTextFormatter_Format formats 100 findings as text.
+// Target: <200µs, about 2µs per finding.
+func BenchmarkTextFormatter_Format(b *testing.B) {
+	findings := benchmarkFindings(100)
+	f := NewTextFormatter("Dockerfile", false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := f.Format(findings, io.Discard); err != nil {
+			b.Fatalf("Format() error = %v", err)
+		}
+	}
+}
+
+// BenchmarkJSONFormatter_Format formats 100 findings as JSON.
+// Target: <400µs, about 4µs per finding.
+func BenchmarkJSONFormatter_Format(b *testing.B) {
+	findings := benchmarkFindings(100)
+	f := NewJSONFormatter("Dockerfile", false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := f.Format(findings, io.Discard); err != nil {
+			b.Fatalf("Format() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// GitHubActionsFormatter formats findings as GitHub Actions workflow commands,
+// which GitHub renders as inline annotations on pull requests.
+type GitHubActionsFormatter struct {
+	// Filename is the path of the file being analyzed, relative to the repository root.
+	Filename string
+	// Quiet suppresses informational findings in the output.
+	Quiet bool
+}
+
+// NewGitHubActionsFormatter creates a new GitHubActionsFormatter with the given filename.
+func NewGitHubActionsFormatter(filename string, quiet bool) Formatter {
+	return &GitHubActionsFormatter{
+		Filename: filename,
+		Quiet:    quiet,
+	}
+}
+
+// Format writes the findings to the given writer as workflow commands.
+// Format: ::level file=<file>,line=<line>,col=<col>,title=<rule_id>::message
+func (f *GitHubActionsFormatter) Format(findings []ast.Finding, w io.Writer) error {
+	return f.writeCommands(f.Filename, findings, w)
+}
+
+// FormatFiles writes the workflow commands for each file in turn.
+func (f *GitHubActionsFormatter) FormatFiles(results []FileResult, w io.Writer) error {
+	for _, result := range results {
+		if err := f.writeCommands(result.Filename, result.Findings, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCommands writes one workflow command per finding in the given file.
+func (f *GitHubActionsFormatter) writeCommands(filename string, findings []ast.Finding, w io.Writer) error {
+	for _, finding := range findings {
+		// Skip info-level findings in quiet mode
+		if f.Quiet && finding.Severity == ast.SeverityInfo {
+			continue
+		}
+
+		message := finding.Message
+		if finding.Suggestion != "" {
+			message += "\nSuggestion: " + finding.Suggestion
+		}
+
+		line := fmt.Sprintf("::%s file=%s,line=%d,col=%d,title=%s::%s",
+			githubLevel(finding.Severity),
+			escapeGitHubProperty(filename),
+			finding.Line,
+			finding.Column,
+			escapeGitHubProperty(finding.RuleID),
+			escapeGitHubData(message),
+		)
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// githubLevel maps a finding severity to a workflow command name.
+func githubLevel(s ast.Severity) string {
+	switch s {
+	case ast.SeverityError:
+		return "error"
+	case ast.SeverityWarning:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// escapeGitHubData escapes a workflow command message.
+func escapeGitHubData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
+
+// escapeGitHubProperty escapes a workflow command property value.
+func escapeGitHubProperty(s string) string {
+	s = escapeGitHubData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	s = strings.ReplaceAll(s, ",", "%2C")
+	return s
+}
@@ -9,12 +9,33 @@ import (
 
 // JSONFinding represents a single finding in JSON output format.
 type JSONFinding struct {
-	RuleID     string `json:"rule_id"`
-	Severity   string `json:"severity"`
-	Line       int    `json:"line"`
-	Column     int    `json:"column"`
-	Message    string `json:"message"`
-	Suggestion string `json:"suggestion,omitempty"`
+	RuleID       string       `json:"rule_id"`
+	Severity     string       `json:"severity"`
+	Line         int          `json:"line"`
+	Column       int          `json:"column"`
+	Message      string       `json:"message"`
+	Suggestion   string       `json:"suggestion,omitempty"`
+	Category     string       `json:"category,omitempty"`
+	FixAvailable bool         `json:"fix_available,omitempty"`
+	Stage        *JSONStage   `json:"stage,omitempty"`
+	Snippet      *JSONSnippet `json:"snippet,omitempty"`
+}
+
+// JSONStage identifies the build stage a finding belongs to.
+type JSONStage struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+}
+
+// JSONSnippet is the source text around a finding. It is only included when
+// the formatter has the source of the analyzed file.
+type JSONSnippet struct {
+	// Text is the source line the finding is reported on.
+	Text string `json:"text"`
+	// StartLine is the line number of the first entry in Lines.
+	StartLine int `json:"start_line"`
+	// Lines holds the finding's line with up to one line of context on each side.
+	Lines []string `json:"lines"`
 }
 
 // JSONSummary represents the summary section of JSON output.
@@ -38,20 +59,46 @@ type JSONFormatter struct {
 	Filename string
 	// Quiet suppresses informational findings in the output.
 	Quiet bool
+	// MinSeverity omits findings below this severity from the output and summary.
+	MinSeverity ast.Severity
+	// Source holds the lines of the analyzed file. When set, each finding
+	// includes a snippet of the source around it.
+	Source []string
 }
 
 // NewJSONFormatter creates a new JSONFormatter with the given filename.
-func NewJSONFormatter(filename string, quiet bool) *JSONFormatter {
+func NewJSONFormatter(filename string, quiet bool) Formatter {
 	return &JSONFormatter{
 		Filename: filename,
 		Quiet:    quiet,
 	}
 }
 
+// SetSource sets the lines of the analyzed file, so that findings include a
+// snippet of the source around them.
+func (f *JSONFormatter) SetSource(lines []string) {
+	f.Source = lines
+}
+
 // Format writes the findings to the given writer as valid JSON.
 func (f *JSONFormatter) Format(findings []ast.Finding, w io.Writer) error {
+	return writeJSON(w, newJSONOutput(f.Filename, findings, f.Source, f.Quiet, f.MinSeverity))
+}
+
+// FormatFiles writes the findings of each file as a JSON array with one
+// output object per file. Snippets use the Source of each FileResult.
+func (f *JSONFormatter) FormatFiles(results []FileResult, w io.Writer) error {
+	outputs := make([]JSONOutput, 0, len(results))
+	for _, result := range results {
+		outputs = append(outputs, newJSONOutput(result.Filename, result.Findings, result.Source, f.Quiet, f.MinSeverity))
+	}
+	return writeJSON(w, outputs)
+}
+
+// newJSONOutput builds the JSON output structure for a single file.
+func newJSONOutput(filename string, findings []ast.Finding, source []string, quiet bool, minSeverity ast.Severity) JSONOutput {
 	output := JSONOutput{
-		File:     f.Filename,
+		File:     filename,
 		Findings: make([]JSONFinding, 0),
 		Summary: JSONSummary{
 			Total:    0,
@@ -62,19 +109,24 @@ func (f *JSONFormatter) Format(findings []ast.Finding, w io.Writer) error {
 	}
 
 	for _, finding := range findings {
-		// Skip info-level findings in quiet mode
-		if f.Quiet && finding.Severity == ast.SeverityInfo {
+		if skipFinding(finding, quiet, minSeverity) {
 			continue
 		}
 
 		jsonFinding := JSONFinding{
-			RuleID:     finding.RuleID,
-			Severity:   finding.Severity.String(),
-			Line:       finding.Line,
-			Column:     finding.Column,
-			Message:    finding.Message,
-			Suggestion: finding.Suggestion,
+			RuleID:       finding.RuleID,
+			Severity:     finding.Severity.String(),
+			Line:         finding.Line,
+			Column:       finding.Column,
+			Message:      finding.Message,
+			Suggestion:   finding.Suggestion,
+			Category:     finding.RuleCategory,
+			FixAvailable: finding.FixAvailable,
+		}
+		if finding.StageIndex >= 0 {
+			jsonFinding.Stage = &JSONStage{Index: finding.StageIndex, Name: finding.StageName}
 		}
+		jsonFinding.Snippet = newJSONSnippet(source, finding.Line)
 		output.Findings = append(output.Findings, jsonFinding)
 
 		// Update summary counts
@@ -89,7 +141,28 @@ func (f *JSONFormatter) Format(findings []ast.Finding, w io.Writer) error {
 		output.Summary.Total++
 	}
 
+	return output
+}
+
+// newJSONSnippet returns the source around a 1-based line, or nil if the
+// line is not in source.
+func newJSONSnippet(source []string, line int) *JSONSnippet {
+	if line < 1 || line > len(source) {
+		return nil
+	}
+
+	start := max(line-1, 1)
+	end := min(line+1, len(source))
+	return &JSONSnippet{
+		Text:      source[line-1],
+		StartLine: start,
+		Lines:     append([]string(nil), source[start-1:end]...),
+	}
+}
+
+// writeJSON encodes v to the given writer as indented JSON.
+func writeJSON(w io.Writer, v interface{}) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(output)
+	return encoder.Encode(v)
 }
@@ -0,0 +1,159 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// JUnitFailure represents a failed test case in JUnit XML output.
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitTestCase represents a single test case in JUnit XML output.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// JUnitTestSuite represents the results for one file in JUnit XML output.
+type JUnitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestSuites represents the results for several files in JUnit XML output.
+type JUnitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitFormatter formats findings as JUnit XML for CI test result reporting.
+// Each rule that produced no findings becomes a passing test case, and each
+// finding becomes a test case named after its rule. Errors and warnings are
+// reported as failures; info findings pass with the message in system-out.
+type JUnitFormatter struct {
+	// Filename is the name of the file being analyzed.
+	Filename string
+	// Quiet suppresses informational findings in the output.
+	Quiet bool
+	// RuleIDs lists the rules that were run. Rules without findings are only
+	// reported as passing test cases when they are listed here.
+	RuleIDs []string
+}
+
+// NewJUnitFormatter creates a new JUnitFormatter with the given filename.
+func NewJUnitFormatter(filename string, quiet bool) Formatter {
+	return &JUnitFormatter{
+		Filename: filename,
+		Quiet:    quiet,
+	}
+}
+
+// Format writes the findings to the given writer as a JUnit XML test suite.
+func (f *JUnitFormatter) Format(findings []ast.Finding, w io.Writer) error {
+	return writeXML(w, f.newSuite(f.Filename, findings))
+}
+
+// FormatFiles writes the findings of all files as JUnit XML with one test
+// suite per file.
+func (f *JUnitFormatter) FormatFiles(results []FileResult, w io.Writer) error {
+	suites := JUnitTestSuites{Suites: make([]JUnitTestSuite, 0, len(results))}
+	for _, result := range results {
+		suites.Suites = append(suites.Suites, f.newSuite(result.Filename, result.Findings))
+	}
+	return writeXML(w, suites)
+}
+
+// newSuite builds the test suite for a single file.
+func (f *JUnitFormatter) newSuite(filename string, findings []ast.Finding) JUnitTestSuite {
+	suite := JUnitTestSuite{Name: filename}
+
+	byRule := make(map[string][]ast.Finding)
+	var unlisted []ast.Finding
+	listed := make(map[string]bool, len(f.RuleIDs))
+	for _, id := range f.RuleIDs {
+		listed[id] = true
+	}
+
+	for _, finding := range findings {
+		// Skip info-level findings in quiet mode
+		if f.Quiet && finding.Severity == ast.SeverityInfo {
+			continue
+		}
+		if listed[finding.RuleID] {
+			byRule[finding.RuleID] = append(byRule[finding.RuleID], finding)
+		} else {
+			unlisted = append(unlisted, finding)
+		}
+	}
+
+	for _, id := range f.RuleIDs {
+		if len(byRule[id]) == 0 {
+			suite.TestCases = append(suite.TestCases, JUnitTestCase{Name: id, ClassName: filename})
+			continue
+		}
+		for _, finding := range byRule[id] {
+			suite.TestCases = append(suite.TestCases, newJUnitTestCase(filename, finding))
+		}
+	}
+	for _, finding := range unlisted {
+		suite.TestCases = append(suite.TestCases, newJUnitTestCase(filename, finding))
+	}
+
+	suite.Tests = len(suite.TestCases)
+	for _, tc := range suite.TestCases {
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	return suite
+}
+
+// newJUnitTestCase converts a finding into a test case.
+func newJUnitTestCase(filename string, finding ast.Finding) JUnitTestCase {
+	text := fmt.Sprintf("%s:%d:%d: %s", filename, finding.Line, finding.Column, finding.Message)
+	if finding.Suggestion != "" {
+		text += "\nSuggestion: " + finding.Suggestion
+	}
+
+	tc := JUnitTestCase{Name: finding.RuleID, ClassName: filename}
+	if finding.Severity == ast.SeverityInfo {
+		tc.SystemOut = text
+		return tc
+	}
+
+	tc.Failure = &JUnitFailure{
+		Message: finding.Message,
+		Type:    finding.Severity.String(),
+		Text:    text,
+	}
+	return tc
+}
+
+// writeXML writes v to the given writer as an indented XML document.
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,76 @@
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// FormatterTarget pairs a formatter with the writer it writes to. A nil
+// Writer stands for the writer passed to TeeFormatter.Format.
+type FormatterTarget struct {
+	Formatter
+	Writer io.Writer
+}
+
+// TeeFormatter writes the same findings with several formatters, each to its
+// own writer, for example a text report to stdout and a JSON report to a
+// file.
+type TeeFormatter struct {
+	Targets []FormatterTarget
+}
+
+// NewTeeFormatter creates a TeeFormatter that writes the output of each
+// formatter, in order, to the writer passed to Format.
+func NewTeeFormatter(formatters ...Formatter) *TeeFormatter {
+	targets := make([]FormatterTarget, len(formatters))
+	for i, f := range formatters {
+		targets[i] = FormatterTarget{Formatter: f}
+	}
+	return &TeeFormatter{Targets: targets}
+}
+
+// NewTeeFormatterWithTargets creates a TeeFormatter that writes the output of
+// each target's formatter to the target's writer.
+func NewTeeFormatterWithTargets(targets []FormatterTarget) *TeeFormatter {
+	return &TeeFormatter{Targets: targets}
+}
+
+// Format writes the findings with each formatter in order. A failing
+// formatter does not stop the others; their errors are joined.
+func (f *TeeFormatter) Format(findings []ast.Finding, w io.Writer) error {
+	var errs []error
+	for _, target := range f.Targets {
+		if err := target.Format(findings, target.writer(w)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FormatFiles writes the findings of each file with each formatter in order.
+// All formatters must implement MultiFormatter.
+func (f *TeeFormatter) FormatFiles(results []FileResult, w io.Writer) error {
+	var errs []error
+	for _, target := range f.Targets {
+		multi, ok := target.Formatter.(MultiFormatter)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%T cannot format several files", target.Formatter))
+			continue
+		}
+		if err := multi.FormatFiles(results, target.writer(w)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writer returns the target's writer, or w if it has none.
+func (t FormatterTarget) writer(w io.Writer) io.Writer {
+	if t.Writer == nil {
+		return w
+	}
+	return t.Writer
+}
@@ -4,6 +4,7 @@ package formatter
 import (
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/devblac/docker-lint/internal/ast"
 )
@@ -14,10 +15,26 @@ type TextFormatter struct {
 	Filename string
 	// Quiet suppresses informational messages, showing only warnings and errors.
 	Quiet bool
+	// MinSeverity hides findings below this severity.
+	MinSeverity ast.Severity
+	// ShowStage adds the build stage of each finding, e.g. [stage: builder].
+	ShowStage bool
+	// Color highlights severities and rule IDs with ANSI escape codes. Use
+	// IsTerminal to enable it only when writing to a terminal.
+	Color bool
 }
 
+// ANSI escape codes used in colored text output.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+)
+
 // NewTextFormatter creates a new TextFormatter with the given filename.
-func NewTextFormatter(filename string, quiet bool) *TextFormatter {
+func NewTextFormatter(filename string, quiet bool) Formatter {
 	return &TextFormatter{
 		Filename: filename,
 		Quiet:    quiet,
@@ -28,20 +45,25 @@ func NewTextFormatter(filename string, quiet bool) *TextFormatter {
 // Format: file:line:column: [severity] rule_id: message
 func (f *TextFormatter) Format(findings []ast.Finding, w io.Writer) error {
 	for _, finding := range findings {
-		// Skip info-level findings in quiet mode
-		if f.Quiet && finding.Severity == ast.SeverityInfo {
+		if skipFinding(finding, f.Quiet, f.MinSeverity) {
 			continue
 		}
 
 		// Format: file:line:column: [severity] rule_id: message
-		line := fmt.Sprintf("%s:%d:%d: [%s] %s: %s",
+		line := fmt.Sprintf("%s:%d:%d: %s %s: %s",
 			f.Filename,
 			finding.Line,
 			finding.Column,
-			finding.Severity.String(),
-			finding.RuleID,
+			f.colorize("["+finding.Severity.String()+"]", severityColor(finding.Severity)),
+			f.colorize(finding.RuleID, ansiBold),
 			finding.Message,
 		)
+		if f.ShowStage && finding.StageIndex >= 0 {
+			line += " " + stageLabel(finding)
+		}
+		if finding.FixAvailable {
+			line += " [fix available]"
+		}
 
 		if _, err := fmt.Fprintln(w, line); err != nil {
 			return err
@@ -58,3 +80,69 @@ func (f *TextFormatter) Format(findings []ast.Finding, w io.Writer) error {
 
 	return nil
 }
+
+// FormatFiles writes the findings of each file as a separate section headed
+// by the file name.
+func (f *TextFormatter) FormatFiles(results []FileResult, w io.Writer) error {
+	for i, result := range results {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, f.colorize("==> "+result.Filename+" <==", ansiBold)); err != nil {
+			return err
+		}
+
+		section := &TextFormatter{Filename: result.Filename, Quiet: f.Quiet, MinSeverity: f.MinSeverity, ShowStage: f.ShowStage, Color: f.Color}
+		if err := section.Format(result.Findings, w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stageLabel formats the build stage of a finding as [stage: name], using the
+// stage index for unnamed stages.
+func stageLabel(finding ast.Finding) string {
+	if finding.StageName != "" {
+		return "[stage: " + finding.StageName + "]"
+	}
+	return fmt.Sprintf("[stage: %d]", finding.StageIndex)
+}
+
+// colorize wraps text in the given ANSI escape code when color is enabled.
+func (f *TextFormatter) colorize(text, code string) string {
+	if !f.Color {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// severityColor returns the ANSI color code for a severity.
+func severityColor(severity ast.Severity) string {
+	switch severity {
+	case ast.SeverityError:
+		return ansiRed
+	case ast.SeverityWarning:
+		return ansiYellow
+	default:
+		return ansiBlue
+	}
+}
+
+// IsTerminal reports whether w writes to a terminal. Writers that are not
+// files, such as buffers, and files that are pipes or regular files are not
+// terminals.
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
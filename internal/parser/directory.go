@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// DefaultFilenamePatterns are the file name patterns ParseDirectory matches
+// when DirectoryOptions.FilenamePatterns is empty.
+var DefaultFilenamePatterns = []string{"Dockerfile", "Dockerfile.*", "*.dockerfile"}
+
+// DefaultIgnoreDirs are the directory names ParseDirectory skips when
+// DirectoryOptions.IgnoreDirs is empty.
+var DefaultIgnoreDirs = []string{".git", "node_modules", "vendor"}
+
+// DirectoryOptions configures ParseDirectory.
+type DirectoryOptions struct {
+	// Recursive searches subdirectories as well as root itself.
+	Recursive bool
+
+	// FilenamePatterns are filepath.Match patterns matched case-insensitively
+	// against file base names. Defaults to DefaultFilenamePatterns.
+	FilenamePatterns []string
+
+	// IgnoreDirs are directory names that are not searched. Defaults to
+	// DefaultIgnoreDirs. The root directory itself is always searched.
+	IgnoreDirs []string
+}
+
+// ParseResult is the outcome of parsing one Dockerfile found by ParseDirectory.
+type ParseResult struct {
+	// Path is the file path, joined with the root passed to ParseDirectory.
+	// It is a directory path for a subdirectory that could not be searched.
+	Path string
+
+	// Dockerfile is the parsed AST, or nil if the file could not be read or parsed.
+	Dockerfile *ast.Dockerfile
+
+	// Error is the error from reading or parsing the file, if any.
+	Error error
+}
+
+// ParseDirectory finds the Dockerfiles under root and parses each of them.
+// Files are returned in lexical order. A file that fails to parse, or a
+// subdirectory that cannot be searched, is still returned, with its Error
+// set; the returned error is only non-nil when root itself cannot be searched.
+func ParseDirectory(root string, opts DirectoryOptions) ([]*ParseResult, error) {
+	patterns := opts.FilenamePatterns
+	if len(patterns) == 0 {
+		patterns = DefaultFilenamePatterns
+	}
+	ignoreDirs := opts.IgnoreDirs
+	if len(ignoreDirs) == 0 {
+		ignoreDirs = DefaultIgnoreDirs
+	}
+
+	var results []*ParseResult
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Report the entry and carry on with the rest of the tree
+			results = append(results, &ParseResult{Path: path, Error: err})
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if !opts.Recursive || containsString(ignoreDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matchesAny(patterns, d.Name()) {
+			results = append(results, parseFile(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// parseFile opens and parses a single Dockerfile.
+func parseFile(path string) *ParseResult {
+	result := &ParseResult{Path: path}
+
+	file, err := os.Open(path)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	defer file.Close()
+
+	result.Dockerfile, result.Error = ParseReader(file)
+	if result.Error != nil {
+		result.Dockerfile = nil
+	}
+	return result
+}
+
+// matchesAny reports whether name matches one of the patterns, ignoring case.
+func matchesAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDirectory(t *testing.T) {
+	root := filepath.Join("testdata", "directory")
+
+	tests := []struct {
+		name      string
+		opts      DirectoryOptions
+		wantPaths []string
+	}{
+		{
+			name:      "top level only",
+			opts:      DirectoryOptions{},
+			wantPaths: []string{"Dockerfile", "Dockerfile.dev"},
+		},
+		{
+			name: "recursive with defaults",
+			opts: DirectoryOptions{Recursive: true},
+			wantPaths: []string{
+				"Dockerfile",
+				"Dockerfile.dev",
+				"services/api/Dockerfile",
+				"services/broken/Dockerfile",
+				"services/web/web.dockerfile",
+			},
+		},
+		{
+			name:      "custom filename patterns",
+			opts:      DirectoryOptions{Recursive: true, FilenamePatterns: []string{"*.DOCKERFILE"}},
+			wantPaths: []string{"services/web/web.dockerfile"},
+		},
+		{
+			name: "custom ignore dirs",
+			opts: DirectoryOptions{Recursive: true, FilenamePatterns: []string{"Dockerfile"}, IgnoreDirs: []string{"services"}},
+			wantPaths: []string{
+				"Dockerfile",
+				"node_modules/pkg/Dockerfile",
+				"vendor/lib/Dockerfile",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := ParseDirectory(root, tt.opts)
+			if err != nil {
+				t.Fatalf("ParseDirectory() error = %v", err)
+			}
+
+			var paths []string
+			for _, result := range results {
+				paths = append(paths, filepath.ToSlash(mustRel(t, root, result.Path)))
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestParseDirectoryResults(t *testing.T) {
+	root := filepath.Join("testdata", "directory")
+
+	results, err := ParseDirectory(root, DirectoryOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("ParseDirectory() error = %v", err)
+	}
+
+	for _, result := range results {
+		rel := filepath.ToSlash(mustRel(t, root, result.Path))
+		if rel == "services/broken/Dockerfile" {
+			if _, ok := result.Error.(*ParseErrors); !ok {
+				t.Errorf("%s: Error = %v, want *ParseErrors", rel, result.Error)
+			}
+			if result.Dockerfile != nil {
+				t.Errorf("%s: Dockerfile should be nil on error", rel)
+			}
+			continue
+		}
+
+		if result.Error != nil {
+			t.Errorf("%s: Error = %v", rel, result.Error)
+			continue
+		}
+		if result.Dockerfile == nil || len(result.Dockerfile.Stages) == 0 {
+			t.Errorf("%s: expected a parsed Dockerfile with stages", rel)
+		}
+	}
+
+	if _, err := ParseDirectory(filepath.Join(root, "missing"), DirectoryOptions{}); err == nil {
+		t.Error("expected error for a missing root directory")
+	}
+}
+
+func TestParseDirectoryUnreadableSubdirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for root")
+	}
+
+	root := t.TempDir()
+	locked := filepath.Join(root, "locked")
+	for _, dir := range []string{locked, filepath.Join(root, "web")} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "web", "Dockerfile"), []byte("FROM alpine:3.18\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0o755) })
+
+	results, err := ParseDirectory(root, DirectoryOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("ParseDirectory() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Path != locked || results[0].Error == nil {
+		t.Errorf("results[0] = %+v, want an error for %s", results[0], locked)
+	}
+	if results[1].Error != nil || results[1].Dockerfile == nil {
+		t.Errorf("results[1] = %+v, want the parsed web/Dockerfile", results[1])
+	}
+}
+
+// mustRel returns path relative to root.
+func mustRel(t *testing.T, root, path string) string {
+	t.Helper()
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		t.Fatalf("filepath.Rel() error = %v", err)
+	}
+	return rel
+}
@@ -12,21 +12,28 @@ import (
 
 // Format converts a Dockerfile AST back to text representation.
 // The output preserves instruction semantics for round-trip testing.
+// Comments are written before the first instruction that follows them in the
+// original file, so inline ignore comments keep applying to their instruction.
 func Format(df *ast.Dockerfile) string {
 	if df == nil {
 		return ""
 	}
 
-	var sb strings.Builder
+	var lines []string
+	comments := df.Comments
 
-	for i, instr := range df.Instructions {
-		if i > 0 {
-			sb.WriteString("\n")
+	for _, instr := range df.Instructions {
+		for len(comments) > 0 && comments[0].LineNum < instr.Line() {
+			lines = append(lines, comments[0].Text)
+			comments = comments[1:]
 		}
-		sb.WriteString(formatInstruction(instr))
+		lines = append(lines, formatInstruction(instr))
+	}
+	for _, comment := range comments {
+		lines = append(lines, comment.Text)
 	}
 
-	return sb.String()
+	return strings.Join(lines, "\n")
 }
 
 // formatInstruction formats a single instruction to its text representation.
@@ -66,6 +73,8 @@ func formatInstruction(instr ast.Instruction) string {
 		return formatStopsignal(i)
 	case *ast.OnbuildInstruction:
 		return formatOnbuild(i)
+	case *ast.MaintainerInstruction:
+		return formatMaintainer(i)
 	default:
 		return ""
 	}
@@ -99,10 +108,76 @@ func formatFrom(f *ast.FromInstruction) string {
 
 // formatRun formats a RUN instruction.
 func formatRun(r *ast.RunInstruction) string {
-	if r.Command == "" {
-		return "RUN"
+	var parts []string
+	parts = append(parts, "RUN")
+
+	for _, m := range r.Mounts {
+		parts = append(parts, "--mount="+formatRunMount(m))
+	}
+	if r.Network != "" {
+		parts = append(parts, "--network="+r.Network)
+	}
+	if r.Security != "" {
+		parts = append(parts, "--security="+r.Security)
 	}
-	return "RUN " + r.Command
+
+	if r.Command != "" {
+		parts = append(parts, r.Command)
+	}
+
+	text := strings.Join(parts, " ")
+	if r.IsHeredoc {
+		text = withHeredocTerminator(text, r.HeredocDelimiter)
+	}
+	return text
+}
+
+// withHeredocTerminator appends the terminating delimiter line to an
+// instruction whose text ends with a heredoc body.
+func withHeredocTerminator(text, delimiter string) string {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text + delimiter
+}
+
+// withHeredoc appends a heredoc body and its terminator to an instruction.
+func withHeredoc(text, delimiter, content string) string {
+	if delimiter == "" {
+		return text
+	}
+	return withHeredocTerminator(text+"\n"+content, delimiter)
+}
+
+// formatRunMount formats the options of a RUN --mount flag.
+func formatRunMount(m ast.RunMount) string {
+	var opts []string
+
+	if m.Type != "" {
+		opts = append(opts, "type="+m.Type)
+	}
+	if m.ID != "" {
+		opts = append(opts, "id="+m.ID)
+	}
+	if m.From != "" {
+		opts = append(opts, "from="+m.From)
+	}
+	if m.Source != "" {
+		opts = append(opts, "source="+m.Source)
+	}
+	if m.Target != "" {
+		opts = append(opts, "target="+m.Target)
+	}
+	switch m.ReadOnly {
+	case "":
+	case "true":
+		opts = append(opts, "readonly")
+	default:
+		opts = append(opts, "readonly="+m.ReadOnly)
+	}
+	opts = append(opts, m.Options...)
+
+	return strings.Join(opts, ",")
 }
 
 // formatCopy formats a COPY instruction.
@@ -116,11 +191,17 @@ func formatCopy(c *ast.CopyInstruction) string {
 	if c.Chown != "" {
 		parts = append(parts, fmt.Sprintf("--chown=%s", c.Chown))
 	}
+	if c.Chmod != "" {
+		parts = append(parts, fmt.Sprintf("--chmod=%s", c.Chmod))
+	}
+	if c.Link {
+		parts = append(parts, "--link")
+	}
 
 	parts = append(parts, c.Sources...)
 	parts = append(parts, c.Dest)
 
-	return strings.Join(parts, " ")
+	return withHeredoc(strings.Join(parts, " "), c.HeredocDelimiter, c.HeredocContent)
 }
 
 // formatAdd formats an ADD instruction.
@@ -131,19 +212,31 @@ func formatAdd(a *ast.AddInstruction) string {
 	if a.Chown != "" {
 		parts = append(parts, fmt.Sprintf("--chown=%s", a.Chown))
 	}
+	if a.Chmod != "" {
+		parts = append(parts, fmt.Sprintf("--chmod=%s", a.Chmod))
+	}
+	if a.Link {
+		parts = append(parts, "--link")
+	}
 
 	parts = append(parts, a.Sources...)
 	parts = append(parts, a.Dest)
 
-	return strings.Join(parts, " ")
+	return withHeredoc(strings.Join(parts, " "), a.HeredocDelimiter, a.HeredocContent)
 }
 
 // formatEnv formats an ENV instruction.
 func formatEnv(e *ast.EnvInstruction) string {
-	if e.Key == "" {
+	pairs := e.AllPairs()
+	if len(pairs) == 0 {
 		return "ENV"
 	}
-	return fmt.Sprintf("ENV %s=%s", e.Key, e.Value)
+
+	parts := make([]string, len(pairs))
+	for i, pair := range pairs {
+		parts[i] = pair.Key + "=" + pair.Value
+	}
+	return "ENV " + strings.Join(parts, " ")
 }
 
 // formatArg formats an ARG instruction.
@@ -325,3 +418,11 @@ func formatOnbuild(o *ast.OnbuildInstruction) string {
 	}
 	return "ONBUILD " + formatInstruction(o.Instruction)
 }
+
+// formatMaintainer formats a MAINTAINER instruction.
+func formatMaintainer(m *ast.MaintainerInstruction) string {
+	if m.Maintainer == "" {
+		return "MAINTAINER"
+	}
+	return "MAINTAINER " + m.Maintainer
+}
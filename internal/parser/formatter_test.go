@@ -81,6 +81,35 @@ func TestFormatRunInstruction(t *testing.T) {
 			instr:    &ast.RunInstruction{Command: ""},
 			expected: "RUN",
 		},
+		{
+			name: "cache mount",
+			instr: &ast.RunInstruction{
+				Command: "npm install",
+				Mounts:  []ast.RunMount{{Type: "cache", Target: "/root/.npm"}},
+			},
+			expected: "RUN --mount=type=cache,target=/root/.npm npm install",
+		},
+		{
+			name: "multiple mounts",
+			instr: &ast.RunInstruction{
+				Command: "make",
+				Mounts: []ast.RunMount{
+					{Type: "secret", ID: "mysecret"},
+					{Type: "bind", Source: ".", Target: "/app", ReadOnly: "true"},
+				},
+			},
+			expected: "RUN --mount=type=secret,id=mysecret --mount=type=bind,source=.,target=/app,readonly make",
+		},
+		{
+			name: "network and security",
+			instr: &ast.RunInstruction{
+				Command:  "make test",
+				Mounts:   []ast.RunMount{{Type: "cache", Target: "/root/.cache"}},
+				Network:  "none",
+				Security: "insecure",
+			},
+			expected: "RUN --mount=type=cache,target=/root/.cache --network=none --security=insecure make test",
+		},
 	}
 
 	for _, tt := range tests {
@@ -114,6 +143,16 @@ func TestFormatCopyInstruction(t *testing.T) {
 			instr:    &ast.CopyInstruction{Sources: []string{"."}, Dest: "/app", Chown: "user:group"},
 			expected: "COPY --chown=user:group . /app",
 		},
+		{
+			name:     "copy with chown and chmod",
+			instr:    &ast.CopyInstruction{Sources: []string{"run.sh"}, Dest: "/bin/", Chown: "app", Chmod: "755"},
+			expected: "COPY --chown=app --chmod=755 run.sh /bin/",
+		},
+		{
+			name:     "copy with all flags",
+			instr:    &ast.CopyInstruction{Sources: []string{"/x"}, Dest: "/y", From: "build", Chown: "app:app", Chmod: "755", Link: true},
+			expected: "COPY --from=build --chown=app:app --chmod=755 --link /x /y",
+		},
 	}
 
 	for _, tt := range tests {
@@ -137,6 +176,11 @@ func TestFormatEnvInstruction(t *testing.T) {
 			instr:    &ast.EnvInstruction{Key: "PATH", Value: "/usr/local/bin"},
 			expected: "ENV PATH=/usr/local/bin",
 		},
+		{
+			name:     "multiple pairs",
+			instr:    &ast.EnvInstruction{Key: "A", Value: "1", Pairs: []ast.EnvPair{{Key: "A", Value: "1"}, {Key: "B", Value: `"x y"`}}},
+			expected: `ENV A=1 B="x y"`,
+		},
 		{
 			name:     "empty key",
 			instr:    &ast.EnvInstruction{Key: "", Value: ""},
@@ -233,6 +277,35 @@ CMD ["./app"]`
 	}
 }
 
+func TestFormatPreservesComments(t *testing.T) {
+	input := "# Build stage\nFROM alpine:3.18\n\n# docker-lint ignore: DL3003\nWORKDIR app\nRUN echo hi\n# trailing comment\n"
+	df, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	result := Format(df)
+	expected := `# Build stage
+FROM alpine:3.18
+# docker-lint ignore: DL3003
+WORKDIR app
+RUN echo hi
+# trailing comment`
+
+	if result != expected {
+		t.Errorf("Format() =\n%s\n\nwant:\n%s", result, expected)
+	}
+
+	// The ignore comment must still apply to the WORKDIR after reformatting
+	df2, err := ParseString(result)
+	if err != nil {
+		t.Fatalf("ParseString(formatted) error = %v", err)
+	}
+	if ids := df2.InlineIgnores[df2.Instructions[1].Line()]; len(ids) != 1 || ids[0] != "DL3003" {
+		t.Errorf("expected DL3003 ignore on WORKDIR line, got %v", df2.InlineIgnores)
+	}
+}
+
 func TestFormatRoundTrip(t *testing.T) {
 	// Test that parsing and formatting produces semantically equivalent output
 	tests := []struct {
@@ -251,6 +324,14 @@ func TestFormatRoundTrip(t *testing.T) {
 			name:  "with workdir and user",
 			input: "FROM alpine:3.18\nWORKDIR /app\nUSER nobody",
 		},
+		{
+			name:  "copy and add flags",
+			input: "FROM alpine:3.18\nCOPY --from=build --chown=app:app --chmod=755 --link /x /y\nADD --chmod=644 --link config.tar /etc/app/",
+		},
+		{
+			name:  "run mount options",
+			input: "FROM alpine:3.18\nRUN --mount=type=cache,target=/var/cache/apt,sharing=locked apt-get update",
+		},
 	}
 
 	for _, tt := range tests {
@@ -282,6 +363,31 @@ func TestFormatRoundTrip(t *testing.T) {
 						i, df1.Instructions[i].Type(), df2.Instructions[i].Type())
 				}
 			}
+
+			// Formatting is stable once flags are in canonical order
+			if again := Format(df2); again != formatted {
+				t.Errorf("Format() not stable:\n%s\nvs\n%s", formatted, again)
+			}
+		})
+	}
+}
+
+func TestFormatRoundTripRunMountOptions(t *testing.T) {
+	tests := []string{
+		"RUN --mount=type=cache,target=/var/cache/apt,sharing=locked,uid=1000 apt-get update",
+		"RUN --mount=type=secret,id=tok,required=true,env=TOKEN ./deploy.sh",
+		"RUN --mount=type=tmpfs,target=/tmp,size=64m make",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			df, err := ParseString("FROM alpine:3.18\n" + input)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if got := formatInstruction(df.Instructions[1]); got != input {
+				t.Errorf("Format() = %q, want %q", got, input)
+			}
 		})
 	}
 }
@@ -312,6 +418,7 @@ func TestFormatAllInstructionTypes(t *testing.T) {
 		{"SHELL", &ast.ShellInstruction{Shell: []string{"/bin/bash", "-c"}}, "SHELL"},
 		{"STOPSIGNAL", &ast.StopsignalInstruction{Signal: "SIGTERM"}, "STOPSIGNAL SIGTERM"},
 		{"ONBUILD", &ast.OnbuildInstruction{Instruction: &ast.RunInstruction{Command: "echo build"}}, "ONBUILD RUN echo build"},
+		{"MAINTAINER", &ast.MaintainerInstruction{Maintainer: "Jane Doe <jane@example.com>"}, "MAINTAINER Jane Doe <jane@example.com>"},
 	}
 
 	for _, tt := range tests {
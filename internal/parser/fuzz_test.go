@@ -0,0 +1,92 @@
+//go:build gofuzz
+
+// Package parser provides lexer, parser, and formatter for Dockerfile content.
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are inputs that exercised parser edge cases during development.
+var fuzzSeeds = []string{
+	"",
+	"\n\n\n",
+	"=1 B",
+	"\"quoted\nline\"",
+	"FROM",
+	"FROM alpine:3.18 AS",
+	"RUN echo \\",
+	"RUN echo \"unclosed",
+	"RUN [\"echo\", \"unclosed\"",
+	"# escape=`\nFROM alpine:3.18\nRUN echo `\n  hello",
+	"# escape=x\n# escape=`\nFROM alpine:3.18",
+	"RUN <<EOF\necho hello\n",
+	"RUN <<-EOF cat <<'END'\n\techo one\n\tEOF\nEND\n",
+	"COPY <<EOF /app/config\nkey=value\nEOF",
+	"ENV A=1 B=\"two words\" C\\ D=3 E",
+	"LABEL a= \"b\"=c d",
+	"COPY --chown=1000 --chmod=777 --from= a",
+	"RUN --mount=type=cache,target= --network echo",
+	"HEALTHCHECK --interval= CMD",
+	"HEALTHCHECK NONE extra",
+	"ONBUILD ONBUILD RUN echo",
+	"ONBUILD",
+	"EXPOSE 80/tcp 443/ 8080-",
+	"\tfrom\talpine\r\nrun\techo\r\n",
+	"FROM alpine\x00\nRUN \xff\xfe",
+	"FROM alpine:3.18\nRUN echo " + strings.Repeat("a", 4096),
+	"FROM alpine:3.18 # docker-lint ignore: DL3006\n# docker-lint ignore:\nRUN x",
+}
+
+// FuzzParseString checks that ParseString never panics, reports failures as
+// a *ParseError with a line number, and that Format accepts every successful
+// parse.
+//
+// The target is excluded from normal test runs. Run it with:
+//
+//	go test -tags gofuzz -run '^$' -fuzz FuzzParseString ./internal/parser
+func FuzzParseString(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	for _, pattern := range []string{
+		filepath.Join("..", "..", "testdata", "valid", "*.Dockerfile"),
+		filepath.Join("..", "..", "testdata", "invalid", "*.Dockerfile"),
+	} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(string(content))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data string) {
+		df, err := ParseString(data)
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %T: %v", err, err)
+			}
+			if parseErr.Line <= 0 {
+				t.Fatalf("expected error line > 0, got %d: %v", parseErr.Line, err)
+			}
+			return
+		}
+
+		if df == nil {
+			t.Fatal("expected Dockerfile, got nil")
+		}
+		Format(df)
+	})
+}
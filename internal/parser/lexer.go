@@ -3,7 +3,10 @@ package parser
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"io"
+	"regexp"
 	"strings"
 	"unicode"
 )
@@ -18,6 +21,7 @@ const (
 	TokenNewline                      // End of line
 	TokenEOF                          // End of file
 	TokenError                        // Lexer error
+	TokenHeredoc                      // Heredoc body following an instruction argument
 )
 
 // String returns the string representation of a TokenType.
@@ -35,6 +39,8 @@ func (t TokenType) String() string {
 		return "EOF"
 	case TokenError:
 		return "ERROR"
+	case TokenHeredoc:
+		return "HEREDOC"
 	default:
 		return "UNKNOWN"
 	}
@@ -70,6 +76,54 @@ var validInstructions = map[string]bool{
 	"MAINTAINER":  true, // Deprecated but still valid
 }
 
+// heredocInstructions contains the instructions that accept heredocs.
+var heredocInstructions = map[string]bool{
+	"RUN":  true,
+	"COPY": true,
+	"ADD":  true,
+}
+
+// heredocPattern matches a heredoc redirection such as <<EOF, <<-EOF or <<"EOF".
+var heredocPattern = regexp.MustCompile(`^<<(-?)(["']?)([A-Za-z_][A-Za-z0-9_]*)(["']?)`)
+
+// heredoc describes a heredoc redirection on an instruction line.
+type heredoc struct {
+	delimiter string
+	stripTabs bool // <<- form: leading tabs are ignored on the terminator line
+}
+
+// findHeredocs returns the heredocs started on an instruction line. A << inside
+// quotes or as part of a <<< here-string is not a heredoc.
+func findHeredocs(line string) []heredoc {
+	var found []heredoc
+	inSingleQuote, inDoubleQuote := false, false
+
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\'':
+			if !inDoubleQuote {
+				inSingleQuote = !inSingleQuote
+			}
+		case '"':
+			if !inSingleQuote {
+				inDoubleQuote = !inDoubleQuote
+			}
+		case '<':
+			if inSingleQuote || inDoubleQuote || (i > 0 && line[i-1] == '<') {
+				continue
+			}
+			m := heredocPattern.FindStringSubmatch(line[i:])
+			if m == nil || m[2] != m[4] {
+				continue
+			}
+			found = append(found, heredoc{delimiter: m[3], stripTabs: m[1] == "-"})
+			i += len(m[0]) - 1
+		}
+	}
+
+	return found
+}
+
 // IsValidInstruction checks if a string is a valid Dockerfile instruction.
 func IsValidInstruction(s string) bool {
 	return validInstructions[strings.ToUpper(s)]
@@ -84,14 +138,44 @@ type Lexer struct {
 	linePos     int
 	atEOF       bool
 	peekedToken *Token
+
+	// instruction is the last instruction keyword scanned, and pending a
+	// token (heredoc body or error) to return before scanning further.
+	instruction string
+	pending     *Token
+
+	// maxLineBytes limits the length of a logical line, including its
+	// continuations, and of each heredoc body line. Zero means no limit.
+	maxLineBytes int
+
+	// escapeChar escapes characters in arguments and continues lines.
+	escapeChar byte
+
+	// shifted reports whether offsets in the current logical line or argument
+	// no longer match source columns, because continuation lines were joined
+	// or escape sequences rewritten.
+	shifted bool
 }
 
+// errLineTooLong is returned by readRawLine when a line exceeds maxLineBytes.
+var errLineTooLong = errors.New("line too long")
+
 // NewLexer creates a new Lexer from an io.Reader.
 func NewLexer(r io.Reader) *Lexer {
 	return &Lexer{
-		reader: bufio.NewReader(r),
-		line:   0,
-		column: 0,
+		reader:     bufio.NewReader(r),
+		line:       0,
+		column:     0,
+		escapeChar: '\\',
+	}
+}
+
+// SetEscapeChar sets the escape character used for line continuations and
+// escape sequences in the lines that have not been read yet. Dockerfiles only
+// allow '\\' (the default) and '`'; other characters are ignored.
+func (l *Lexer) SetEscapeChar(r rune) {
+	if r == '\\' || r == '`' {
+		l.escapeChar = byte(r)
 	}
 }
 
@@ -119,6 +203,12 @@ func (l *Lexer) PeekToken() Token {
 
 // scanToken performs the actual token scanning.
 func (l *Lexer) scanToken() Token {
+	if l.pending != nil {
+		tok := *l.pending
+		l.pending = nil
+		return tok
+	}
+
 	// Read next line if needed
 	if l.currentLine == "" || l.linePos >= len(l.currentLine) {
 		if l.atEOF {
@@ -127,6 +217,11 @@ func (l *Lexer) scanToken() Token {
 		if !l.readNextLine() {
 			return Token{Type: TokenEOF, Line: l.line, Column: l.column}
 		}
+		if l.pending != nil {
+			tok := *l.pending
+			l.pending = nil
+			return tok
+		}
 	}
 
 	// Skip leading whitespace
@@ -152,25 +247,100 @@ func (l *Lexer) scanToken() Token {
 	if l.isAtLineStart() {
 		word := l.scanWord()
 		if IsValidInstruction(word) {
-			return Token{Type: TokenInstruction, Value: strings.ToUpper(word), Line: l.line, Column: startCol}
+			l.instruction = strings.ToUpper(word)
+			return Token{Type: TokenInstruction, Value: l.instruction, Line: l.line, Column: startCol}
+		}
+		// Not an instruction, treat as argument. A line starting with a
+		// non-word character falls through to scanArgument, which consumes it.
+		if word != "" {
+			return Token{Type: TokenArgument, Value: word, Line: l.line, Column: startCol}
 		}
-		// Not an instruction, treat as argument
-		return Token{Type: TokenArgument, Value: word, Line: l.line, Column: startCol}
 	}
 
 	// Scan argument (rest of the line, handling continuations and quotes)
+	line := l.line
 	arg := l.scanArgument()
-	return Token{Type: TokenArgument, Value: arg, Line: l.line, Column: startCol}
+	if heredocInstructions[l.instruction] {
+		if heredocs := findHeredocs(arg); len(heredocs) > 0 {
+			l.pending = l.scanHeredocs(heredocs)
+		}
+	}
+	return Token{Type: TokenArgument, Value: arg, Line: line, Column: startCol}
+}
+
+// scanHeredocs reads the bodies of the heredocs started on the current line and
+// returns the heredoc token, or an error token if the bodies are not terminated
+// or more than one heredoc was started.
+func (l *Lexer) scanHeredocs(heredocs []heredoc) *Token {
+	startLine := l.line
+	var body string
+	var tooLong *Token
+
+	for _, doc := range heredocs {
+		var lines []string
+		terminated := false
+
+		for !l.atEOF {
+			text, err := l.readRawLine()
+			if err == errLineTooLong {
+				// Read on to the end of the body so it is not lexed as
+				// instructions
+				if tooLong == nil {
+					tooLong = l.lineTooLong(l.line + 1)
+				}
+				l.line++
+				continue
+			}
+			if err != nil {
+				if err != io.EOF {
+					break
+				}
+				l.atEOF = true
+				if text == "" {
+					break
+				}
+			}
+			l.line++
+
+			terminator := text
+			if doc.stripTabs {
+				terminator = strings.TrimLeft(terminator, "\t")
+			}
+			if terminator == doc.delimiter {
+				terminated = true
+				break
+			}
+			lines = append(lines, text)
+		}
+
+		if tooLong != nil {
+			return tooLong
+		}
+		if !terminated {
+			return &Token{Type: TokenError, Value: fmt.Sprintf("unterminated heredoc: missing %s", doc.delimiter), Line: startLine}
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	if len(heredocs) > 1 {
+		return &Token{Type: TokenError, Value: "multiple heredocs in one instruction are not supported", Line: startLine}
+	}
+
+	// The current line is fully consumed
+	l.currentLine = ""
+	return &Token{Type: TokenHeredoc, Value: body, Line: startLine + 1}
 }
 
 // readNextLine reads the next line from the input, handling line continuations.
 func (l *Lexer) readNextLine() bool {
 	var fullLine strings.Builder
 	firstLine := true
+	startLine := l.line + 1
+	l.shifted = false
 
 	for {
-		line, err := l.reader.ReadString('\n')
-		if err != nil && err != io.EOF {
+		line, err := l.readRawLine()
+		if err != nil && err != io.EOF && err != errLineTooLong {
 			return false
 		}
 
@@ -179,14 +349,19 @@ func (l *Lexer) readNextLine() bool {
 			firstLine = false
 		}
 
-		// Remove trailing newline
-		line = strings.TrimRight(line, "\r\n")
+		if err == errLineTooLong || l.exceedsMaxLine(fullLine.Len()+len(line)) {
+			l.skipContinuation(line, err, fullLine.Len() == 0)
+			l.currentLine = ""
+			l.pending = l.lineTooLong(startLine)
+			return true
+		}
 
-		// Check for line continuation (backslash at end)
-		if strings.HasSuffix(line, "\\") {
-			// Remove the backslash and continue reading
-			fullLine.WriteString(strings.TrimSuffix(line, "\\"))
+		// Check for line continuation (escape character at end)
+		if l.isContinued(line, fullLine.Len() == 0) {
+			// Remove the escape character and continue reading
+			fullLine.WriteString(strings.TrimSuffix(line, string(l.escapeChar)))
 			fullLine.WriteString(" ") // Replace continuation with space
+			l.shifted = true
 			if err == io.EOF {
 				l.atEOF = true
 				break
@@ -210,6 +385,89 @@ func (l *Lexer) readNextLine() bool {
 	return len(l.currentLine) > 0 || !l.atEOF
 }
 
+// isContinued reports whether a physical line ends with the escape character
+// and so continues on the next line. A comment line at the start of a logical
+// line is never continued, so "# escape=\\" does not join the next line.
+func (l *Lexer) isContinued(line string, first bool) bool {
+	isComment := first && strings.HasPrefix(strings.TrimSpace(line), "#")
+	return !isComment && strings.HasSuffix(line, string(l.escapeChar))
+}
+
+// skipContinuation discards the physical lines continuing line, the last line
+// read with error err, up to the end of the logical line, so that they are not
+// lexed as instructions of their own after line was found to be too long.
+// first reports whether line starts the logical line.
+func (l *Lexer) skipContinuation(line string, err error, first bool) {
+	for l.isContinued(line, first) && err != io.EOF && !l.atEOF {
+		line, err = l.readRawLine()
+		if err != nil && err != io.EOF && err != errLineTooLong {
+			return
+		}
+		if err == io.EOF {
+			l.atEOF = true
+			if line == "" {
+				return
+			}
+		}
+		l.line++
+		first = false
+	}
+}
+
+// readRawLine reads one physical line without its line ending. When
+// maxLineBytes is set, it stops buffering once the line is longer than the
+// limit, discards the rest of the line and returns errLineTooLong with an
+// abbreviation of the line, enough to tell whether it is a comment and
+// whether it is continued.
+func (l *Lexer) readRawLine() (string, error) {
+	var buf []byte
+	for {
+		chunk, err := l.reader.ReadSlice('\n')
+		if l.maxLineBytes > 0 && len(buf)+len(chunk) > l.maxLineBytes+len("\r\n") {
+			start := strings.TrimLeft(string(buf)+string(chunk), " \t")
+			end := string(chunk)
+			for err == bufio.ErrBufferFull {
+				// Keep enough of the end for the escape character and line ending
+				chunk, err = l.reader.ReadSlice('\n')
+				end = end[max(0, len(end)-len("\\\r\n")):] + string(chunk)
+			}
+			if err == io.EOF {
+				l.atEOF = true
+			}
+			end = strings.TrimRight(end, "\r\n")
+			return start[:min(len(start), 1)] + " " + end[max(0, len(end)-1):], errLineTooLong
+		}
+		buf = append(buf, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		line := strings.TrimRight(string(buf), "\r\n")
+		if l.exceedsMaxLine(len(line)) {
+			if err == io.EOF {
+				l.atEOF = true
+			}
+			return line, errLineTooLong
+		}
+		return line, err
+	}
+}
+
+// exceedsMaxLine reports whether n bytes are over the configured line limit.
+func (l *Lexer) exceedsMaxLine(n int) bool {
+	return l.maxLineBytes > 0 && n > l.maxLineBytes
+}
+
+// lineTooLong returns the error token for a line over the configured limit.
+func (l *Lexer) lineTooLong(line int) *Token {
+	return &Token{
+		Type:   TokenError,
+		Value:  fmt.Sprintf("line exceeds maximum length of %d bytes", l.maxLineBytes),
+		Line:   line,
+		Column: 1,
+	}
+}
+
 // skipWhitespace advances past any whitespace characters.
 func (l *Lexer) skipWhitespace() {
 	for l.linePos < len(l.currentLine) {
@@ -262,21 +520,24 @@ func (l *Lexer) scanArgument() string {
 		ch := l.currentLine[l.linePos]
 
 		// Handle escape sequences
-		if ch == '\\' && l.linePos+1 < len(l.currentLine) && !inSingleQuote {
+		if ch == l.escapeChar && l.linePos+1 < len(l.currentLine) && !inSingleQuote {
 			nextCh := l.currentLine[l.linePos+1]
 			// Handle common escape sequences
 			switch nextCh {
 			case 'n':
 				result.WriteByte('\n')
 				l.linePos += 2
+				l.shifted = true
 				continue
 			case 't':
 				result.WriteByte('\t')
 				l.linePos += 2
+				l.shifted = true
 				continue
-			case '"', '\'', '\\', ' ':
+			case '"', '\'', l.escapeChar, ' ':
 				result.WriteByte(nextCh)
 				l.linePos += 2
+				l.shifted = true
 				continue
 			default:
 				// Keep the backslash and next char as-is
@@ -322,6 +583,9 @@ func (l *Lexer) Reset(r io.Reader) {
 	l.linePos = 0
 	l.atEOF = false
 	l.peekedToken = nil
+	l.instruction = ""
+	l.pending = nil
+	l.escapeChar = '\\'
 }
 
 // CurrentLine returns the current line number being processed.
@@ -17,6 +17,7 @@ func TestTokenTypeString(t *testing.T) {
 		{TokenNewline, "NEWLINE"},
 		{TokenEOF, "EOF"},
 		{TokenError, "ERROR"},
+		{TokenHeredoc, "HEREDOC"},
 		{TokenType(99), "UNKNOWN"},
 	}
 
@@ -592,6 +593,41 @@ func TestLexerPeekToken(t *testing.T) {
 	}
 }
 
+func TestLexerSetEscapeChar(t *testing.T) {
+	tests := []struct {
+		name     string
+		escape   rune
+		input    string
+		expected string
+	}{
+		{"default backslash", '\\', "RUN echo a \\\n    b", "echo a      b"},
+		{"backtick continuation", '`', "RUN echo a `\n    b", "echo a      b"},
+		{"backslash is literal with backtick", '`', "RUN dir c:\\temp\\new", "dir c:\\temp\\new"},
+		{"backtick escapes quotes", '`', "RUN echo `\"hi`\"", "echo \"hi\""},
+		{"invalid character is ignored", '|', "RUN echo a \\\n    b", "echo a      b"},
+		{"comment is not continued", '\\', "# note \\\nRUN echo", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := NewLexer(strings.NewReader(tt.input))
+			lexer.SetEscapeChar(tt.escape)
+			tokens := lexer.Tokenize()
+
+			if tt.expected == "" {
+				// The comment and the RUN instruction are separate lines
+				if len(tokens) < 2 || tokens[0].Type != TokenComment || tokens[1].Type != TokenInstruction {
+					t.Errorf("tokens = %+v, want COMMENT then INSTRUCTION", tokens)
+				}
+				return
+			}
+			if len(tokens) < 2 || tokens[1].Value != tt.expected {
+				t.Errorf("tokens = %+v, want argument %q", tokens, tt.expected)
+			}
+		})
+	}
+}
+
 func TestLexerReset(t *testing.T) {
 	input1 := "FROM alpine"
 	input2 := "RUN echo hello"
@@ -693,3 +729,14 @@ func TestTokenizeString(t *testing.T) {
 		t.Errorf("last token = %+v, want EOF", tokens[len(tokens)-1])
 	}
 }
+
+// BenchmarkLexer_Tokenize tokenizes the Dockerfile used by
+// BenchmarkParseString, to separate lexing cost from parsing cost.
+// Target: <50µs, under half the time of BenchmarkParseString.
+func BenchmarkLexer_Tokenize(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkDockerfile)))
+	for i := 0; i < b.N; i++ {
+		NewLexer(strings.NewReader(benchmarkDockerfile)).Tokenize()
+	}
+}
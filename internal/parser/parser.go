@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/devblac/docker-lint/internal/ast"
@@ -22,12 +24,51 @@ func (e *ParseError) Error() string {
 	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
 }
 
+// ParseErrors is returned by Parse when one or more lines could not be
+// parsed. Errors are in the order of the lines they occurred on.
+type ParseErrors struct {
+	Errors []ParseError
+}
+
+// Error returns the messages of all errors, one per line.
+func (e *ParseErrors) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		messages[i] = e.Errors[i].Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the individual errors, so errors.As finds the first
+// *ParseError.
+func (e *ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = &e.Errors[i]
+	}
+	return errs
+}
+
 // Parser parses Dockerfile content into an AST.
 type Parser struct {
 	lexer         *Lexer
 	currentToken  Token
 	inlineIgnores map[int][]string
 	errors        []ParseError
+
+	// openIgnores maps the rule IDs of ignore-start comments that have not
+	// been closed yet to the line of the comment.
+	openIgnores  map[string]int
+	ignoreRanges []ast.IgnoreRange
+
+	// argsColumn is the source column where the arguments of the current
+	// instruction start, or 0 if their columns do not match the source.
+	argsColumn int
+
+	// MaxLineBytes limits the length of a logical line, including its
+	// continuation lines, and of each heredoc body line. Longer lines are
+	// not buffered and Parse returns a ParseError. Zero means no limit.
+	MaxLineBytes int
 }
 
 // NewParser creates a new Parser from an io.Reader.
@@ -39,24 +80,42 @@ func NewParser(r io.Reader) *Parser {
 }
 
 // Parse parses the Dockerfile and returns the AST.
+//
+// A line that cannot be parsed does not stop parsing: the error is recorded
+// and parsing continues with the next line. If any errors were recorded,
+// Parse returns the AST of the lines that were parsed together with a
+// *ParseErrors listing all of them.
 func (p *Parser) Parse(r io.Reader) (*ast.Dockerfile, error) {
 	p.lexer = NewLexer(r)
+	p.lexer.maxLineBytes = p.MaxLineBytes
 	p.inlineIgnores = make(map[int][]string)
+	p.openIgnores = make(map[string]int)
+	p.ignoreRanges = nil
 	p.errors = nil
 
 	dockerfile := &ast.Dockerfile{
-		Stages:        []ast.Stage{},
-		Instructions:  []ast.Instruction{},
-		Comments:      []ast.Comment{},
-		InlineIgnores: make(map[int][]string),
+		Stages:           []ast.Stage{},
+		Instructions:     []ast.Instruction{},
+		Comments:         []ast.Comment{},
+		InlineIgnores:    make(map[int][]string),
+		EscapeChar:       '\\',
+		ParserDirectives: make(map[string]string),
 	}
 
 	var currentStage *ast.Stage
 	stageIndex := 0
 
+	// Parser directives are only recognized before the first instruction,
+	// empty line or ordinary comment
+	inDirectives := true
+
 	for {
 		p.currentToken = p.lexer.NextToken()
 
+		if inDirectives && (p.currentToken.Type != TokenComment || !p.parseDirective(dockerfile, p.currentToken)) {
+			inDirectives = false
+		}
+
 		switch p.currentToken.Type {
 		case TokenEOF:
 			// Finalize last stage if exists
@@ -64,17 +123,20 @@ func (p *Parser) Parse(r io.Reader) (*ast.Dockerfile, error) {
 				dockerfile.Stages = append(dockerfile.Stages, *currentStage)
 			}
 			dockerfile.InlineIgnores = p.inlineIgnores
+			dockerfile.IgnoreRanges = p.ignoreRanges
+			p.reportOpenIgnores(dockerfile)
 			if len(p.errors) > 0 {
-				return dockerfile, &p.errors[0]
+				return dockerfile, &ParseErrors{Errors: p.errors}
 			}
 			return dockerfile, nil
 
 		case TokenError:
-			return nil, &ParseError{
+			p.errors = append(p.errors, ParseError{
 				Line:    p.currentToken.Line,
 				Column:  p.currentToken.Column,
 				Message: p.currentToken.Value,
-			}
+			})
+			p.skipToNextLine(p.currentToken.Line)
 
 		case TokenComment:
 			comment := ast.Comment{
@@ -82,7 +144,7 @@ func (p *Parser) Parse(r io.Reader) (*ast.Dockerfile, error) {
 				Text:    p.currentToken.Value,
 			}
 			dockerfile.Comments = append(dockerfile.Comments, comment)
-			p.parseInlineIgnore(p.currentToken.Value, p.currentToken.Line)
+			p.parseInlineIgnore(dockerfile, p.currentToken.Value, p.currentToken.Line)
 
 		case TokenNewline:
 			// Skip empty lines
@@ -95,7 +157,7 @@ func (p *Parser) Parse(r io.Reader) (*ast.Dockerfile, error) {
 					Line:    p.currentToken.Line,
 					Message: err.Error(),
 				})
-				p.skipToNextLine()
+				p.skipToNextLine(p.currentToken.Line)
 				continue
 			}
 
@@ -130,24 +192,29 @@ func (p *Parser) Parse(r io.Reader) (*ast.Dockerfile, error) {
 				Column:  p.currentToken.Column,
 				Message: fmt.Sprintf("unexpected argument without instruction: %s", p.currentToken.Value),
 			})
-			p.skipToNextLine()
+			p.skipToNextLine(p.currentToken.Line)
 		}
 	}
 }
 
+// inlineIgnorePattern matches inline ignore comments such as
+// "# docker-lint ignore: DL3006, DL3007" and the ignore-start and ignore-end
+// comments of ignore ranges. The kind of comment is in group 1.
+var inlineIgnorePattern = regexp.MustCompile(`(?i)#\s*docker-lint\s+ignore(|-start|-end):\s*(.+)`)
+
 // parseInlineIgnore extracts rule IDs from inline ignore comments.
 // Format: # docker-lint ignore: RULE_ID[, RULE_ID...]
-func (p *Parser) parseInlineIgnore(comment string, line int) {
-	// Pattern: # docker-lint ignore: DL3006, DL3007
-	re := regexp.MustCompile(`(?i)#\s*docker-lint\s+ignore:\s*(.+)`)
-	matches := re.FindStringSubmatch(comment)
-	if len(matches) < 2 {
+//
+// The ignore-start and ignore-end forms open and close a range of lines in
+// which the rules are ignored; unmatched comments are reported as warnings.
+func (p *Parser) parseInlineIgnore(dockerfile *ast.Dockerfile, comment string, line int) {
+	matches := inlineIgnorePattern.FindStringSubmatch(comment)
+	if len(matches) < 3 {
 		return
 	}
 
 	// Parse comma-separated rule IDs
-	rulesPart := matches[1]
-	rules := strings.Split(rulesPart, ",")
+	rules := strings.Split(matches[2], ",")
 	var ruleIDs []string
 	for _, rule := range rules {
 		rule = strings.TrimSpace(rule)
@@ -155,19 +222,131 @@ func (p *Parser) parseInlineIgnore(comment string, line int) {
 			ruleIDs = append(ruleIDs, rule)
 		}
 	}
+	if len(ruleIDs) == 0 {
+		return
+	}
 
-	if len(ruleIDs) > 0 {
+	switch strings.ToLower(matches[1]) {
+	case "":
 		// The ignore applies to the next line
 		p.inlineIgnores[line+1] = append(p.inlineIgnores[line+1], ruleIDs...)
+
+	case "-start":
+		for _, ruleID := range ruleIDs {
+			if start, ok := p.openIgnores[ruleID]; ok {
+				dockerfile.Warnings = append(dockerfile.Warnings, ast.ParseWarning{
+					LineNum: line,
+					Message: fmt.Sprintf("ignore-start for %s is ignored: a range opened on line %d is still open", ruleID, start),
+				})
+				continue
+			}
+			p.openIgnores[ruleID] = line
+		}
+
+	case "-end":
+		// Rules opened by the same ignore-start comment share a range
+		var starts []int
+		closed := make(map[int][]string)
+		for _, ruleID := range ruleIDs {
+			start, ok := p.openIgnores[ruleID]
+			if !ok {
+				dockerfile.Warnings = append(dockerfile.Warnings, ast.ParseWarning{
+					LineNum: line,
+					Message: fmt.Sprintf("ignore-end for %s has no matching ignore-start", ruleID),
+				})
+				continue
+			}
+			delete(p.openIgnores, ruleID)
+			if _, ok := closed[start]; !ok {
+				starts = append(starts, start)
+			}
+			closed[start] = append(closed[start], ruleID)
+		}
+		for _, start := range starts {
+			p.ignoreRanges = append(p.ignoreRanges, ast.IgnoreRange{Start: start, End: line, RuleIDs: closed[start]})
+		}
 	}
 }
 
-// skipToNextLine advances the lexer to the next line.
-func (p *Parser) skipToNextLine() {
+// reportOpenIgnores adds a warning for each ignore-start comment without a
+// matching ignore-end. Unclosed ranges do not ignore anything.
+func (p *Parser) reportOpenIgnores(dockerfile *ast.Dockerfile) {
+	ruleIDs := make([]string, 0, len(p.openIgnores))
+	for ruleID := range p.openIgnores {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Slice(ruleIDs, func(i, j int) bool {
+		if p.openIgnores[ruleIDs[i]] != p.openIgnores[ruleIDs[j]] {
+			return p.openIgnores[ruleIDs[i]] < p.openIgnores[ruleIDs[j]]
+		}
+		return ruleIDs[i] < ruleIDs[j]
+	})
+
+	for _, ruleID := range ruleIDs {
+		dockerfile.Warnings = append(dockerfile.Warnings, ast.ParseWarning{
+			LineNum: p.openIgnores[ruleID],
+			Message: fmt.Sprintf("ignore-start for %s has no matching ignore-end and is ignored", ruleID),
+		})
+	}
+}
+
+// directivePattern matches a parser directive such as "# escape=`".
+var directivePattern = regexp.MustCompile(`^#\s*([A-Za-z]+)\s*=\s*(\S*)\s*$`)
+
+// knownDirectives contains the parser directives recognized by Docker.
+var knownDirectives = map[string]bool{
+	"syntax": true,
+	"escape": true,
+	"check":  true,
+}
+
+// parseDirective records a parser directive comment in the Dockerfile and
+// applies the escape directive to the lexer. It returns false if the comment
+// is not a parser directive, which ends the directives section.
+func (p *Parser) parseDirective(dockerfile *ast.Dockerfile, tok Token) bool {
+	matches := directivePattern.FindStringSubmatch(tok.Value)
+	if matches == nil {
+		return false
+	}
+	name, value := strings.ToLower(matches[1]), matches[2]
+	if !knownDirectives[name] {
+		return false
+	}
+
+	if _, ok := dockerfile.ParserDirectives[name]; ok {
+		dockerfile.Warnings = append(dockerfile.Warnings, ast.ParseWarning{
+			LineNum: tok.Line,
+			Message: fmt.Sprintf("duplicate parser directive %q is ignored", name),
+		})
+		return true
+	}
+	dockerfile.ParserDirectives[name] = value
+
+	if name == "escape" {
+		if value != "\\" && value != "`" {
+			dockerfile.Warnings = append(dockerfile.Warnings, ast.ParseWarning{
+				LineNum: tok.Line,
+				Message: fmt.Sprintf("invalid escape character %q: must be \\ or `; using \\", value),
+			})
+			return true
+		}
+		dockerfile.EscapeChar = rune(value[0])
+		p.lexer.SetEscapeChar(dockerfile.EscapeChar)
+	}
+	return true
+}
+
+// skipToNextLine discards the remaining tokens of the logical line ending on
+// the given line, so that parsing resumes with the next line.
+func (p *Parser) skipToNextLine(line int) {
 	for {
-		tok := p.lexer.NextToken()
-		if tok.Type == TokenNewline || tok.Type == TokenEOF {
-			break
+		tok := p.lexer.PeekToken()
+		if tok.Type == TokenEOF || tok.Line != line {
+			return
+		}
+		p.lexer.NextToken()
+		if tok.Type == TokenNewline {
+			return
 		}
 	}
 }
@@ -178,12 +357,22 @@ func (p *Parser) parseInstruction() (ast.Instruction, error) {
 	line := p.currentToken.Line
 	col := p.currentToken.Column
 
-	// Get the argument token
-	argToken := p.lexer.NextToken()
+	// Get the argument token. Other tokens are left for the caller, since
+	// they may start the next line.
+	argToken := p.lexer.PeekToken()
 	var args string
-	if argToken.Type == TokenArgument {
+	p.argsColumn = 0
+	switch argToken.Type {
+	case TokenArgument:
+		p.lexer.NextToken()
 		args = argToken.Value
-	} else if argToken.Type != TokenNewline && argToken.Type != TokenEOF {
+		if !p.lexer.shifted {
+			p.argsColumn = argToken.Column
+		}
+	case TokenNewline:
+		p.lexer.NextToken()
+	case TokenEOF:
+	default:
 		return nil, fmt.Errorf("expected argument after %s", instrType)
 	}
 
@@ -192,6 +381,14 @@ func (p *Parser) parseInstruction() (ast.Instruction, error) {
 		rawText = instrType + " " + args
 	}
 
+	if heredocInstructions[instrType] && len(findHeredocs(args)) > 0 {
+		bodyToken := p.lexer.NextToken()
+		if bodyToken.Type != TokenHeredoc {
+			return nil, fmt.Errorf("%s", bodyToken.Value)
+		}
+		return p.parseHeredocInstruction(instrType, line, rawText, args, bodyToken.Value)
+	}
+
 	switch instrType {
 	case "FROM":
 		return p.parseFrom(line, rawText, args)
@@ -228,17 +425,60 @@ func (p *Parser) parseInstruction() (ast.Instruction, error) {
 	case "ONBUILD":
 		return p.parseOnbuild(line, rawText, args)
 	case "MAINTAINER":
-		// Deprecated but still valid - treat as a generic instruction
-		return &ast.LabelInstruction{
-			LineNum: line,
-			RawText: rawText,
-			Labels:  map[string]string{"maintainer": args},
-		}, nil
+		return p.parseMaintainer(line, rawText, args)
 	default:
 		return nil, fmt.Errorf("unknown instruction: %s at line %d, column %d", instrType, line, col)
 	}
 }
 
+// argColumn returns the source column of the byte at offset in the arguments
+// of the current instruction, or 0 if it is unknown.
+func (p *Parser) argColumn(offset int) int {
+	if p.argsColumn == 0 {
+		return 0
+	}
+	return p.argsColumn + offset
+}
+
+// parseHeredocInstruction parses a RUN, COPY or ADD instruction whose argument
+// line starts a heredoc with the given body.
+func (p *Parser) parseHeredocInstruction(instrType string, line int, rawText, args, body string) (ast.Instruction, error) {
+	delimiter := findHeredocs(args)[0].delimiter
+	rawText += "\n" + body
+	if body != "" {
+		rawText += "\n"
+	}
+	rawText += delimiter
+
+	switch instrType {
+	case "RUN":
+		instr, err := p.parseRun(line, rawText, args)
+		if err != nil {
+			return nil, err
+		}
+		instr.Command += "\n" + body
+		instr.IsHeredoc = true
+		instr.HeredocDelimiter = delimiter
+		return instr, nil
+	case "COPY":
+		instr, err := p.parseCopy(line, rawText, args)
+		if err != nil {
+			return nil, err
+		}
+		instr.HeredocDelimiter = delimiter
+		instr.HeredocContent = body
+		return instr, nil
+	default:
+		instr, err := p.parseAdd(line, rawText, args)
+		if err != nil {
+			return nil, err
+		}
+		instr.HeredocDelimiter = delimiter
+		instr.HeredocContent = body
+		return instr, nil
+	}
+}
+
 // parseFrom parses a FROM instruction.
 // Format: FROM [--platform=<platform>] <image>[:<tag>|@<digest>] [AS <name>]
 func (p *Parser) parseFrom(line int, rawText, args string) (*ast.FromInstruction, error) {
@@ -271,6 +511,7 @@ func (p *Parser) parseFrom(line int, rawText, args string) (*ast.FromInstruction
 
 	// Parse image reference
 	imageRef := parts[idx]
+	refOffset := strings.Index(args, imageRef)
 	idx++
 
 	// Check for digest (@sha256:...)
@@ -285,6 +526,9 @@ func (p *Parser) parseFrom(line int, rawText, args string) (*ast.FromInstruction
 		tagParts := strings.SplitN(imageRef, ":", 2)
 		instr.Image = tagParts[0]
 		instr.Tag = tagParts[1]
+		if refOffset >= 0 {
+			instr.TagColumn = p.argColumn(refOffset + len(instr.Image) + 1)
+		}
 	} else {
 		instr.Image = imageRef
 	}
@@ -302,18 +546,83 @@ func (p *Parser) parseFrom(line int, rawText, args string) (*ast.FromInstruction
 }
 
 // parseRun parses a RUN instruction.
+// Format: RUN [--mount=<options>]... [--network=<type>] [--security=<mode>] <command>
 func (p *Parser) parseRun(line int, rawText, args string) (*ast.RunInstruction, error) {
 	instr := &ast.RunInstruction{
 		LineNum: line,
 		RawText: rawText,
-		Command: args,
-		Shell:   !isExecForm(args),
 	}
+	instr.Command = splitRunFlags(instr, args)
+	instr.Shell = !isExecForm(instr.Command)
 	return instr, nil
 }
 
+// splitRunFlags parses the leading BuildKit flags of a RUN instruction into
+// instr and returns the command body that follows them.
+func splitRunFlags(instr *ast.RunInstruction, args string) string {
+	rest := strings.TrimSpace(args)
+
+	for strings.HasPrefix(rest, "--") {
+		end := strings.IndexAny(rest, " \t")
+		if end == -1 {
+			end = len(rest)
+		}
+		name, value, _ := strings.Cut(rest[2:end], "=")
+		switch strings.ToLower(name) {
+		case "mount":
+			instr.Mounts = append(instr.Mounts, parseRunMount(value))
+		case "network":
+			instr.Network = value
+		case "security":
+			instr.Security = value
+		default:
+			// Not a RUN flag; the command itself starts with --
+			return rest
+		}
+		rest = strings.TrimSpace(rest[end:])
+	}
+
+	return rest
+}
+
+// parseRunMount parses the comma-separated options of a --mount flag.
+func parseRunMount(spec string) ast.RunMount {
+	var mount ast.RunMount
+
+	for _, opt := range strings.Split(spec, ",") {
+		key, value, hasValue := strings.Cut(opt, "=")
+		switch strings.ToLower(key) {
+		case "type":
+			mount.Type = value
+		case "id":
+			mount.ID = value
+		case "source", "src":
+			mount.Source = value
+		case "target", "dst", "destination":
+			mount.Target = value
+		case "from":
+			mount.From = value
+		case "readonly", "ro":
+			if hasValue {
+				mount.ReadOnly = value
+			} else {
+				mount.ReadOnly = "true"
+			}
+		case "readwrite", "rw":
+			mount.ReadOnly = "false"
+		default:
+			if opt != "" {
+				mount.Options = append(mount.Options, opt)
+			}
+		}
+	}
+
+	return mount
+}
+
 // parseCopy parses a COPY instruction.
-// Format: COPY [--from=<name>] [--chown=<user>:<group>] <src>... <dest>
+// Format: COPY [--from=<name>] [--chown=<user>:<group>] [--chmod=<perms>] [--link] <src>... <dest>
+// or COPY [--from=<name>] [--chown=<user>:<group>] [--chmod=<perms>] [--link] ["<src>", ... "<dest>"]
 func (p *Parser) parseCopy(line int, rawText, args string) (*ast.CopyInstruction, error) {
 	if args == "" {
 		return nil, fmt.Errorf("COPY requires source and destination arguments")
@@ -336,6 +645,12 @@ func (p *Parser) parseCopy(line int, rawText, args string) (*ast.CopyInstruction
 		} else if strings.HasPrefix(parts[idx], "--chown=") {
 			instr.Chown = strings.TrimPrefix(parts[idx], "--chown=")
 			idx++
+		} else if strings.HasPrefix(parts[idx], "--chmod=") {
+			instr.Chmod = strings.TrimPrefix(parts[idx], "--chmod=")
+			idx++
+		} else if link, ok := parseLinkFlag(parts[idx]); ok {
+			instr.Link = link
+			idx++
 		} else if strings.HasPrefix(parts[idx], "--") {
 			// Skip other flags
 			idx++
@@ -344,10 +659,11 @@ func (p *Parser) parseCopy(line int, rawText, args string) (*ast.CopyInstruction
 		}
 	}
 
-	// Remaining parts are sources and destination
-	for idx < len(parts) {
-		sources = append(sources, parts[idx])
-		idx++
+	// Remaining parts are sources and destination, in plain or JSON form
+	if rest := strings.Join(parts[idx:], " "); isExecForm(rest) {
+		sources = parseExecForm(rest)
+	} else {
+		sources = parts[idx:]
 	}
 
 	if len(sources) < 2 {
@@ -360,8 +676,22 @@ func (p *Parser) parseCopy(line int, rawText, args string) (*ast.CopyInstruction
 	return instr, nil
 }
 
+// parseLinkFlag parses a --link or --link=<bool> flag. It reports false for
+// other arguments.
+func parseLinkFlag(arg string) (link, ok bool) {
+	if arg == "--link" {
+		return true, true
+	}
+	if value, found := strings.CutPrefix(arg, "--link="); found {
+		link, err := strconv.ParseBool(value)
+		return link && err == nil, true
+	}
+	return false, false
+}
+
 // parseAdd parses an ADD instruction.
-// Format: ADD [--chown=<user>:<group>] <src>... <dest>
+// Format: ADD [--chown=<user>:<group>] [--chmod=<perms>] [--link] <src>... <dest>
+// or ADD [--chown=<user>:<group>] [--chmod=<perms>] [--link] ["<src>", ... "<dest>"]
 func (p *Parser) parseAdd(line int, rawText, args string) (*ast.AddInstruction, error) {
 	if args == "" {
 		return nil, fmt.Errorf("ADD requires source and destination arguments")
@@ -381,6 +711,12 @@ func (p *Parser) parseAdd(line int, rawText, args string) (*ast.AddInstruction,
 		if strings.HasPrefix(parts[idx], "--chown=") {
 			instr.Chown = strings.TrimPrefix(parts[idx], "--chown=")
 			idx++
+		} else if strings.HasPrefix(parts[idx], "--chmod=") {
+			instr.Chmod = strings.TrimPrefix(parts[idx], "--chmod=")
+			idx++
+		} else if link, ok := parseLinkFlag(parts[idx]); ok {
+			instr.Link = link
+			idx++
 		} else if strings.HasPrefix(parts[idx], "--") {
 			// Skip other flags
 			idx++
@@ -389,10 +725,11 @@ func (p *Parser) parseAdd(line int, rawText, args string) (*ast.AddInstruction,
 		}
 	}
 
-	// Remaining parts are sources and destination
-	for idx < len(parts) {
-		sources = append(sources, parts[idx])
-		idx++
+	// Remaining parts are sources and destination, in plain or JSON form
+	if rest := strings.Join(parts[idx:], " "); isExecForm(rest) {
+		sources = parseExecForm(rest)
+	} else {
+		sources = parts[idx:]
 	}
 
 	if len(sources) < 2 {
@@ -417,25 +754,71 @@ func (p *Parser) parseEnv(line int, rawText, args string) (*ast.EnvInstruction,
 		RawText: rawText,
 	}
 
-	// Check for key=value format
-	if strings.Contains(args, "=") {
-		eqIdx := strings.Index(args, "=")
-		instr.Key = strings.TrimSpace(args[:eqIdx])
-		instr.Value = strings.TrimSpace(args[eqIdx+1:])
+	parts := splitArgs(args)
+	if len(parts) > 0 && strings.Contains(parts[0], "=") {
+		// key=value format, with one or more pairs
+		for _, part := range parts {
+			key, value, ok := strings.Cut(part, "=")
+			if !ok && len(instr.Pairs) > 0 {
+				// Not a pair; keep it with the previous value
+				last := &instr.Pairs[len(instr.Pairs)-1]
+				last.Value += " " + part
+				continue
+			}
+			instr.Pairs = append(instr.Pairs, ast.EnvPair{Key: key, Value: value})
+		}
 	} else {
 		// Old format: ENV key value
-		parts := splitArgs(args)
+		pair := ast.EnvPair{}
 		if len(parts) >= 1 {
-			instr.Key = parts[0]
+			pair.Key = parts[0]
 		}
 		if len(parts) >= 2 {
-			instr.Value = strings.Join(parts[1:], " ")
+			pair.Value = strings.Join(parts[1:], " ")
+		}
+		instr.Pairs = []ast.EnvPair{pair}
+	}
+
+	// Locate each key after the previous one, so repeated keys get their own column
+	offset := 0
+	for i := range instr.Pairs {
+		index := indexWord(args[offset:], instr.Pairs[i].Key)
+		if index < 0 {
+			break
 		}
+		offset += index
+		instr.Pairs[i].Column = p.argColumn(offset)
+		offset += len(instr.Pairs[i].Key)
 	}
 
+	instr.Key = instr.Pairs[0].Key
+	instr.Value = instr.Pairs[0].Value
 	return instr, nil
 }
 
+// indexWord returns the index of the first occurrence of word in s that starts
+// a whitespace-separated argument and is followed by '=', whitespace or the
+// end of s, or -1 if there is none.
+func indexWord(s, word string) int {
+	for offset := 0; offset <= len(s); {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return -1
+		}
+		start, end := offset+i, offset+i+len(word)
+		if (start == 0 || isBlank(s[start-1])) && (end == len(s) || s[end] == '=' || isBlank(s[end])) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+// isBlank reports whether ch separates arguments.
+func isBlank(ch byte) bool {
+	return ch == ' ' || ch == '\t'
+}
+
 // parseArg parses an ARG instruction.
 // Format: ARG <name>[=<default value>]
 func (p *Parser) parseArg(line int, rawText, args string) (*ast.ArgInstruction, error) {
@@ -518,10 +901,11 @@ func (p *Parser) parseLabel(line int, rawText, args string) (*ast.LabelInstructi
 	}
 
 	// Parse key=value pairs
-	pairs := parseKeyValuePairs(args)
+	pairs, keys := parseKeyValuePairs(args)
 	for k, v := range pairs {
 		instr.Labels[k] = v
 	}
+	instr.Keys = keys
 
 	return instr, nil
 }
@@ -663,6 +1047,21 @@ func (p *Parser) parseStopsignal(line int, rawText, args string) (*ast.Stopsigna
 	return instr, nil
 }
 
+// parseMaintainer parses a deprecated MAINTAINER instruction.
+// Format: MAINTAINER <name>
+func (p *Parser) parseMaintainer(line int, rawText, args string) (*ast.MaintainerInstruction, error) {
+	if args == "" {
+		return nil, fmt.Errorf("MAINTAINER requires a name")
+	}
+
+	instr := &ast.MaintainerInstruction{
+		LineNum:    line,
+		RawText:    rawText,
+		Maintainer: args,
+	}
+	return instr, nil
+}
+
 // parseOnbuild parses an ONBUILD instruction.
 // Format: ONBUILD <INSTRUCTION>
 func (p *Parser) parseOnbuild(line int, rawText, args string) (*ast.OnbuildInstruction, error) {
@@ -691,8 +1090,10 @@ func (p *Parser) parseOnbuild(line int, rawText, args string) (*ast.OnbuildInstr
 		innerRaw = instrType + " " + instrArgs
 	}
 
-	// Create a temporary parser state to parse the inner instruction
+	// Create a temporary parser state to parse the inner instruction. The
+	// inner arguments are rejoined, so their columns are unknown.
 	savedToken := p.currentToken
+	p.argsColumn = 0
 	p.currentToken = Token{Type: TokenInstruction, Value: instrType, Line: line}
 
 	var innerInstr ast.Instruction
@@ -825,9 +1226,11 @@ func parseExecForm(s string) []string {
 	return result
 }
 
-// parseKeyValuePairs parses key=value pairs from a string.
-func parseKeyValuePairs(s string) map[string]string {
+// parseKeyValuePairs parses key=value pairs from a string. It also returns
+// the keys in the order they appear, including keys that are repeated.
+func parseKeyValuePairs(s string) (map[string]string, []string) {
 	result := make(map[string]string)
+	var keys []string
 	parts := splitArgs(s)
 
 	for _, part := range parts {
@@ -838,10 +1241,11 @@ func parseKeyValuePairs(s string) map[string]string {
 			// Remove surrounding quotes from value
 			value = strings.Trim(value, "\"'")
 			result[key] = value
+			keys = append(keys, key)
 		}
 	}
 
-	return result
+	return result, keys
 }
 
 // ParseString is a convenience function to parse a Dockerfile from a string.
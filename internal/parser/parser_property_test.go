@@ -129,11 +129,15 @@ func genFromInstruction() gopter.Gen {
 
 // genRunInstruction generates a random RUN instruction.
 func genRunInstruction() gopter.Gen {
-	return genCommand().Map(func(cmd string) ast.Instruction {
+	return gopter.CombineGens(
+		genCommand(),
+		gen.OneConstOf("", "default", "none", "host"),
+	).Map(func(vals []interface{}) ast.Instruction {
 		return &ast.RunInstruction{
 			LineNum: 1,
-			Command: cmd,
+			Command: vals[0].(string),
 			Shell:   true,
+			Network: vals[1].(string),
 		}
 	})
 }
@@ -339,15 +343,19 @@ func instructionsEquivalent(a, b ast.Instruction) bool {
 
 	case *ast.RunInstruction:
 		bi := b.(*ast.RunInstruction)
-		return ai.Command == bi.Command && ai.Shell == bi.Shell
+		return ai.Command == bi.Command && ai.Shell == bi.Shell && reflect.DeepEqual(ai.Mounts, bi.Mounts) &&
+			ai.Network == bi.Network && ai.Security == bi.Security &&
+			ai.IsHeredoc == bi.IsHeredoc && ai.HeredocDelimiter == bi.HeredocDelimiter
 
 	case *ast.CopyInstruction:
 		bi := b.(*ast.CopyInstruction)
-		return reflect.DeepEqual(ai.Sources, bi.Sources) && ai.Dest == bi.Dest && ai.From == bi.From && ai.Chown == bi.Chown
+		return reflect.DeepEqual(ai.Sources, bi.Sources) && ai.Dest == bi.Dest && ai.From == bi.From && ai.Chown == bi.Chown && ai.Chmod == bi.Chmod && ai.Link == bi.Link &&
+			ai.HeredocDelimiter == bi.HeredocDelimiter && ai.HeredocContent == bi.HeredocContent
 
 	case *ast.AddInstruction:
 		bi := b.(*ast.AddInstruction)
-		return reflect.DeepEqual(ai.Sources, bi.Sources) && ai.Dest == bi.Dest && ai.Chown == bi.Chown
+		return reflect.DeepEqual(ai.Sources, bi.Sources) && ai.Dest == bi.Dest && ai.Chown == bi.Chown && ai.Chmod == bi.Chmod && ai.Link == bi.Link &&
+			ai.HeredocDelimiter == bi.HeredocDelimiter && ai.HeredocContent == bi.HeredocContent
 
 	case *ast.EnvInstruction:
 		bi := b.(*ast.EnvInstruction)
@@ -398,6 +406,10 @@ func instructionsEquivalent(a, b ast.Instruction) bool {
 		bi := b.(*ast.StopsignalInstruction)
 		return ai.Signal == bi.Signal
 
+	case *ast.MaintainerInstruction:
+		bi := b.(*ast.MaintainerInstruction)
+		return ai.Maintainer == bi.Maintainer
+
 	case *ast.OnbuildInstruction:
 		bi := b.(*ast.OnbuildInstruction)
 		if ai.Instruction == nil && bi.Instruction == nil {
@@ -2,6 +2,8 @@
 package parser
 
 import (
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -122,6 +124,64 @@ func TestParseAllInstructionTypes(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:         "COPY with --chown and --chmod",
+			input:        "FROM alpine\nCOPY --chown=app --chmod=755 run.sh /usr/local/bin/",
+			expectedType: ast.InstrCOPY,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				c := instr.(*ast.CopyInstruction)
+				if c.Chown != "app" || c.Chmod != "755" || c.Dest != "/usr/local/bin/" {
+					t.Errorf("Chown = %q, Chmod = %q, Dest = %q", c.Chown, c.Chmod, c.Dest)
+				}
+			},
+		},
+		{
+			name:         "ADD with --chmod",
+			input:        "FROM alpine\nADD --chmod=644 config.tar /etc/app/",
+			expectedType: ast.InstrADD,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				a := instr.(*ast.AddInstruction)
+				if a.Chmod != "644" || len(a.Sources) != 1 || a.Sources[0] != "config.tar" {
+					t.Errorf("Chmod = %q, Sources = %v", a.Chmod, a.Sources)
+				}
+			},
+		},
+		{
+			name:         "COPY with all flags",
+			input:        "FROM alpine\nCOPY --from=build --chown=app:app --chmod=755 --link /x /y",
+			expectedType: ast.InstrCOPY,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				c := instr.(*ast.CopyInstruction)
+				if c.From != "build" || c.Chown != "app:app" || c.Chmod != "755" || !c.Link {
+					t.Errorf("From = %q, Chown = %q, Chmod = %q, Link = %v", c.From, c.Chown, c.Chmod, c.Link)
+				}
+				if len(c.Sources) != 1 || c.Sources[0] != "/x" || c.Dest != "/y" {
+					t.Errorf("Sources = %v, Dest = %q", c.Sources, c.Dest)
+				}
+			},
+		},
+		{
+			name:         "COPY with --link=false",
+			input:        "FROM alpine\nCOPY --link=false app /app",
+			expectedType: ast.InstrCOPY,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				c := instr.(*ast.CopyInstruction)
+				if c.Link || len(c.Sources) != 1 || c.Sources[0] != "app" {
+					t.Errorf("Link = %v, Sources = %v", c.Link, c.Sources)
+				}
+			},
+		},
+		{
+			name:         "ADD with --link",
+			input:        "FROM alpine\nADD --link --chmod=644 config.tar /etc/app/",
+			expectedType: ast.InstrADD,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				a := instr.(*ast.AddInstruction)
+				if !a.Link || a.Chmod != "644" || a.Dest != "/etc/app/" {
+					t.Errorf("Link = %v, Chmod = %q, Dest = %q", a.Link, a.Chmod, a.Dest)
+				}
+			},
+		},
 		{
 			name:         "ADD simple",
 			input:        "FROM alpine\nADD src /app",
@@ -142,6 +202,36 @@ func TestParseAllInstructionTypes(t *testing.T) {
 				if e.Key != "NODE_ENV" || e.Value != "production" {
 					t.Errorf("Key=Value = %q=%q, want NODE_ENV=production", e.Key, e.Value)
 				}
+				if len(e.Pairs) != 1 || e.Pairs[0] != (ast.EnvPair{Key: "NODE_ENV", Value: "production", Column: 5}) {
+					t.Errorf("Pairs = %v, want one pair", e.Pairs)
+				}
+			},
+		},
+		{
+			name:         "ENV two pairs",
+			input:        "FROM alpine\nENV NODE_ENV=production PORT=3000",
+			expectedType: ast.InstrENV,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				e := instr.(*ast.EnvInstruction)
+				want := []ast.EnvPair{{Key: "NODE_ENV", Value: "production", Column: 5}, {Key: "PORT", Value: "3000", Column: 25}}
+				if !reflect.DeepEqual(e.Pairs, want) {
+					t.Errorf("Pairs = %v, want %v", e.Pairs, want)
+				}
+				if e.Key != "NODE_ENV" || e.Value != "production" {
+					t.Errorf("Key=Value = %q=%q, want the first pair", e.Key, e.Value)
+				}
+			},
+		},
+		{
+			name:         "ENV three pairs with quoted values",
+			input:        "FROM alpine\nENV GREETING=\"hello world\" NAME='app' EMPTY=",
+			expectedType: ast.InstrENV,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				e := instr.(*ast.EnvInstruction)
+				want := []ast.EnvPair{{Key: "GREETING", Value: `"hello world"`, Column: 5}, {Key: "NAME", Value: "'app'", Column: 28}, {Key: "EMPTY", Value: "", Column: 39}}
+				if !reflect.DeepEqual(e.Pairs, want) {
+					t.Errorf("Pairs = %v, want %v", e.Pairs, want)
+				}
 			},
 		},
 		{
@@ -246,6 +336,21 @@ func TestParseAllInstructionTypes(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:         "LABEL repeated key",
+			input:        "FROM alpine\nLABEL version=1.0 maintainer=dev version=2.0",
+			expectedType: ast.InstrLABEL,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				l := instr.(*ast.LabelInstruction)
+				if l.Labels["version"] != "2.0" {
+					t.Errorf("Labels[version] = %q, want 2.0", l.Labels["version"])
+				}
+				want := []string{"version", "maintainer", "version"}
+				if !reflect.DeepEqual(l.Keys, want) {
+					t.Errorf("Keys = %v, want %v", l.Keys, want)
+				}
+			},
+		},
 		{
 			name:         "VOLUME shell form",
 			input:        "FROM alpine\nVOLUME /data",
@@ -362,6 +467,17 @@ func TestParseAllInstructionTypes(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:         "MAINTAINER",
+			input:        "FROM alpine\nMAINTAINER Jane Doe <jane@example.com>",
+			expectedType: ast.InstrMAINTAINER,
+			validate: func(t *testing.T, instr ast.Instruction) {
+				m := instr.(*ast.MaintainerInstruction)
+				if m.Maintainer != "Jane Doe <jane@example.com>" {
+					t.Errorf("Maintainer = %q, want %q", m.Maintainer, "Jane Doe <jane@example.com>")
+				}
+			},
+		},
 		{
 			name:         "ONBUILD",
 			input:        "FROM alpine\nONBUILD RUN echo building",
@@ -567,6 +683,16 @@ func TestParseErrorReporting(t *testing.T) {
 			input:       "FROM alpine\nINVALID command",
 			expectError: true,
 		},
+		{
+			name:        "Line starting with a symbol",
+			input:       "FROM alpine\n=1 B",
+			expectError: true,
+		},
+		{
+			name:        "Line starting with a quote",
+			input:       "FROM alpine\n\"quoted\nline\"",
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -595,11 +721,78 @@ RUN echo world`
 	}
 
 	// Error should be a ParseError with line information
-	if pe, ok := err.(*ParseError); ok {
-		if pe.Line != 3 {
-			t.Errorf("ParseError.Line = %d, want 3", pe.Line)
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("error = %v, want *ParseError", err)
+	}
+	if pe.Line != 3 {
+		t.Errorf("ParseError.Line = %d, want 3", pe.Line)
+	}
+}
+
+// TestParseErrorRecovery tests that parsing continues after an error and
+// reports all errors along with the instructions that were parsed.
+func TestParseErrorRecovery(t *testing.T) {
+	input := `FROM alpine:3.18
+INVALID instruction
+RUN echo hello
+COPY .
+USER app
+`
+
+	df, err := ParseString(input)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	parseErrs, ok := err.(*ParseErrors)
+	if !ok {
+		t.Fatalf("error = %T, want *ParseErrors", err)
+	}
+	var lines []int
+	for _, pe := range parseErrs.Errors {
+		lines = append(lines, pe.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 4}) {
+		t.Errorf("error lines = %v, want [2 4]", lines)
+	}
+	for _, want := range []string{"line 2: ", "line 4: COPY requires"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() = %q, want it to contain %q", err.Error(), want)
 		}
 	}
+
+	if df == nil {
+		t.Fatal("expected a partial AST, got nil")
+	}
+	var types []ast.InstructionType
+	for _, instr := range df.Instructions {
+		types = append(types, instr.Type())
+	}
+	want := []ast.InstructionType{ast.InstrFROM, ast.InstrRUN, ast.InstrUSER}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("instructions = %v, want %v", types, want)
+	}
+	if len(df.Stages) != 1 || len(df.Stages[0].Instructions) != 3 {
+		t.Errorf("stages = %+v, want one stage with 3 instructions", df.Stages)
+	}
+}
+
+// TestParseErrorRecoveryMissingArgument tests that an instruction without
+// arguments does not swallow the next line.
+func TestParseErrorRecoveryMissingArgument(t *testing.T) {
+	df, err := ParseString("FROM\nFROM alpine:3.18\nWORKDIR\nRUN echo hello\n")
+
+	var parseErrs *ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs.Errors) != 2 {
+		t.Fatalf("error = %v, want 2 parse errors", err)
+	}
+	if parseErrs.Errors[0].Line != 1 || parseErrs.Errors[1].Line != 3 {
+		t.Errorf("errors = %+v, want errors on lines 1 and 3", parseErrs.Errors)
+	}
+	if len(df.Instructions) != 2 {
+		t.Errorf("got %d instructions, want FROM and RUN", len(df.Instructions))
+	}
 }
 
 // TestParseEmptyDockerfile tests parsing an empty Dockerfile.
@@ -732,6 +925,95 @@ FROM ubuntu`
 	}
 }
 
+// TestParseIgnoreRanges tests parsing of ignore-start and ignore-end comments.
+func TestParseIgnoreRanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantRanges   []ast.IgnoreRange
+		wantWarnings []int
+	}{
+		{
+			name: "single rule",
+			input: `FROM ubuntu:22.04
+# docker-lint ignore-start: DL3009
+RUN apt-get update && \
+    apt-get install -y curl
+# docker-lint ignore-end: DL3009`,
+			wantRanges: []ast.IgnoreRange{{Start: 2, End: 5, RuleIDs: []string{"DL3009"}}},
+		},
+		{
+			name: "several rules closed together",
+			input: `FROM ubuntu:22.04
+# docker-lint ignore-start: DL3009, DL3013
+RUN apt-get update && apt-get install -y curl
+# DOCKER-LINT IGNORE-END: DL3013, DL3009`,
+			wantRanges: []ast.IgnoreRange{{Start: 2, End: 4, RuleIDs: []string{"DL3013", "DL3009"}}},
+		},
+		{
+			name: "rules closed separately",
+			input: `FROM ubuntu:22.04
+# docker-lint ignore-start: DL3009, DL3013
+RUN apt-get update && apt-get install -y curl
+# docker-lint ignore-end: DL3013
+RUN apt-get install -y git
+# docker-lint ignore-end: DL3009`,
+			wantRanges: []ast.IgnoreRange{
+				{Start: 2, End: 4, RuleIDs: []string{"DL3013"}},
+				{Start: 2, End: 6, RuleIDs: []string{"DL3009"}},
+			},
+		},
+		{
+			name: "nested start keeps the first",
+			input: `FROM ubuntu:22.04
+# docker-lint ignore-start: DL3009
+# docker-lint ignore-start: DL3009
+RUN apt-get update && apt-get install -y curl
+# docker-lint ignore-end: DL3009`,
+			wantRanges:   []ast.IgnoreRange{{Start: 2, End: 5, RuleIDs: []string{"DL3009"}}},
+			wantWarnings: []int{3},
+		},
+		{
+			name: "end without start",
+			input: `FROM ubuntu:22.04
+RUN apt-get update && apt-get install -y curl
+# docker-lint ignore-end: DL3009`,
+			wantWarnings: []int{3},
+		},
+		{
+			name: "start without end",
+			input: `FROM ubuntu:22.04
+# docker-lint ignore-start: DL3013
+# docker-lint ignore-start: DL3009
+RUN apt-get update && apt-get install -y curl`,
+			wantWarnings: []int{2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(df.IgnoreRanges, tt.wantRanges) {
+				t.Errorf("IgnoreRanges = %+v, want %+v", df.IgnoreRanges, tt.wantRanges)
+			}
+			var lines []int
+			for _, w := range df.Warnings {
+				lines = append(lines, w.LineNum)
+			}
+			if !reflect.DeepEqual(lines, tt.wantWarnings) {
+				t.Errorf("warnings on lines %v, want %v: %+v", lines, tt.wantWarnings, df.Warnings)
+			}
+			if len(df.InlineIgnores) != 0 {
+				t.Errorf("InlineIgnores = %v, want none", df.InlineIgnores)
+			}
+		})
+	}
+}
+
 // TestParseCommentPreservation tests that comments are preserved in the AST.
 func TestParseCommentPreservation(t *testing.T) {
 	input := `# Build stage comment
@@ -783,6 +1065,48 @@ CMD ["./app"]`
 }
 
 // TestParseMultiLineContinuation tests parsing of multi-line instructions.
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		tagColumn     int
+		envKeyColumns []int
+	}{
+		{"FROM tag", "FROM ubuntu:latest", 13, nil},
+		{"FROM with platform and alias", "FROM --platform=linux/amd64 node:20 AS build", 34, nil},
+		{"FROM indented", "  from\tnode:20", 13, nil},
+		{"FROM continued", "FROM \\\n  ubuntu:latest", 0, nil},
+		{"ENV pairs", "FROM alpine\nENV A=1 API_TOKEN=x", 0, []int{5, 9}},
+		{"ENV repeated key", "FROM alpine\nENV A=A A=2", 0, []int{5, 9}},
+		{"ENV old format", "FROM alpine\nENV   SECRET value", 0, []int{7}},
+		{"ENV after escape", "FROM alpine\nENV A=a\\ b B=1", 0, []int{0, 0}},
+		{"ONBUILD ENV", "FROM alpine\nONBUILD ENV A=1", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			if from := ast.FindFrom(df); len(from) > 0 && from[0].TagColumn != tt.tagColumn {
+				t.Errorf("TagColumn = %d, want %d", from[0].TagColumn, tt.tagColumn)
+			}
+
+			var columns []int
+			for _, env := range ast.FindInstructions[*ast.EnvInstruction](df) {
+				for _, pair := range env.Pairs {
+					columns = append(columns, pair.Column)
+				}
+			}
+			if !reflect.DeepEqual(columns, tt.envKeyColumns) {
+				t.Errorf("ENV key columns = %v, want %v", columns, tt.envKeyColumns)
+			}
+		})
+	}
+}
+
 func TestParseMultiLineContinuation(t *testing.T) {
 	input := `FROM alpine
 RUN apt-get update && \
@@ -810,6 +1134,323 @@ RUN apt-get update && \
 	}
 }
 
+// TestParseEscapeDirective tests the escape parser directive.
+func TestParseEscapeDirective(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedEscape   rune
+		expectedCommands []string
+		expectedWarnings int
+	}{
+		{
+			name: "backtick escape with Windows paths",
+			input: "# escape=`\n" +
+				"FROM mcr.microsoft.com/windows/servercore:ltsc2022\n" +
+				"COPY app c:\\\n" +
+				"RUN mkdir c:\\temp `\n" +
+				"    && dir c:\\temp",
+			expectedEscape:   '`',
+			expectedCommands: []string{"mkdir c:\\temp      && dir c:\\temp"},
+		},
+		{
+			name: "backslash escape",
+			input: "# escape=\\\n" +
+				"FROM alpine\n" +
+				"RUN apk add \\\n" +
+				"    curl",
+			expectedEscape:   '\\',
+			expectedCommands: []string{"apk add      curl"},
+		},
+		{
+			name: "directive with syntax and spaces",
+			input: "# syntax=docker/dockerfile:1\n" +
+				"#escape = `\n" +
+				"FROM alpine\n" +
+				"RUN echo a `\n" +
+				"    b",
+			expectedEscape:   '`',
+			expectedCommands: []string{"echo a      b"},
+		},
+		{
+			name: "invalid escape character",
+			input: "# escape=|\n" +
+				"FROM alpine\n" +
+				"RUN echo a \\\n" +
+				"    b",
+			expectedEscape:   '\\',
+			expectedCommands: []string{"echo a      b"},
+			expectedWarnings: 1,
+		},
+		{
+			name: "directive after instruction is ignored",
+			input: "FROM alpine\n" +
+				"# escape=`\n" +
+				"RUN echo a \\\n" +
+				"    b",
+			expectedEscape:   '\\',
+			expectedCommands: []string{"echo a      b"},
+		},
+		{
+			name: "directive after comment is ignored",
+			input: "# build the app\n" +
+				"# escape=`\n" +
+				"FROM alpine\n" +
+				"RUN echo a \\\n" +
+				"    b",
+			expectedEscape:   '\\',
+			expectedCommands: []string{"echo a      b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if df.EscapeChar != tt.expectedEscape {
+				t.Errorf("EscapeChar = %q, want %q", df.EscapeChar, tt.expectedEscape)
+			}
+			if len(df.Warnings) != tt.expectedWarnings {
+				t.Errorf("len(Warnings) = %d, want %d: %+v", len(df.Warnings), tt.expectedWarnings, df.Warnings)
+			}
+
+			var commands []string
+			for _, run := range ast.FindRun(df) {
+				commands = append(commands, run.Command)
+			}
+			if !reflect.DeepEqual(commands, tt.expectedCommands) {
+				t.Errorf("commands = %q, want %q", commands, tt.expectedCommands)
+			}
+		})
+	}
+
+	t.Run("directives are recorded", func(t *testing.T) {
+		df, err := ParseString("# syntax=docker/dockerfile:1\n# ESCAPE=`\n# escape=\\\n\nFROM alpine")
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+		want := map[string]string{"syntax": "docker/dockerfile:1", "escape": "`"}
+		if !reflect.DeepEqual(df.ParserDirectives, want) {
+			t.Errorf("ParserDirectives = %v, want %v", df.ParserDirectives, want)
+		}
+		if len(df.Warnings) != 1 || df.Warnings[0].LineNum != 3 {
+			t.Errorf("expected a duplicate directive warning on line 3, got %+v", df.Warnings)
+		}
+		if len(df.Comments) != 3 {
+			t.Errorf("len(Comments) = %d, want 3", len(df.Comments))
+		}
+	})
+}
+
+// TestParseRunMounts tests parsing of BuildKit RUN --mount, --network and --security flags.
+func TestParseRunMounts(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedMounts  []ast.RunMount
+		expectedNetwork string
+		expectedSecure  string
+		expectedCommand string
+	}{
+		{
+			name:            "cache mount",
+			input:           "RUN --mount=type=cache,target=/root/.npm npm install",
+			expectedMounts:  []ast.RunMount{{Type: "cache", Target: "/root/.npm"}},
+			expectedCommand: "npm install",
+		},
+		{
+			name:            "secret mount",
+			input:           "RUN --mount=type=secret,id=mysecret cat /run/secrets/mysecret",
+			expectedMounts:  []ast.RunMount{{Type: "secret", ID: "mysecret"}},
+			expectedCommand: "cat /run/secrets/mysecret",
+		},
+		{
+			name:            "bind mount",
+			input:           "RUN --mount=type=bind,source=.,target=/app go build ./...",
+			expectedMounts:  []ast.RunMount{{Type: "bind", Source: ".", Target: "/app"}},
+			expectedCommand: "go build ./...",
+		},
+		{
+			name:  "multiple mounts",
+			input: "RUN --mount=type=cache,target=/go/pkg/mod --mount=type=bind,from=builder,src=/out,dst=/in,ro go build",
+			expectedMounts: []ast.RunMount{
+				{Type: "cache", Target: "/go/pkg/mod"},
+				{Type: "bind", From: "builder", Source: "/out", Target: "/in", ReadOnly: "true"},
+			},
+			expectedCommand: "go build",
+		},
+		{
+			name:            "network and security flags",
+			input:           "RUN --mount=type=cache,target=/root/.cache --network=none --security=insecure go test ./...",
+			expectedMounts:  []ast.RunMount{{Type: "cache", Target: "/root/.cache"}},
+			expectedNetwork: "none",
+			expectedSecure:  "insecure",
+			expectedCommand: "go test ./...",
+		},
+		{
+			name:            "network flag before mount",
+			input:           "RUN --network=host --mount=type=cache,target=/root/.cache go build",
+			expectedMounts:  []ast.RunMount{{Type: "cache", Target: "/root/.cache"}},
+			expectedNetwork: "host",
+			expectedCommand: "go build",
+		},
+		{
+			name:            "other options",
+			input:           "RUN --mount=type=cache,target=/var/cache/apt,sharing=locked,uid=1000 --mount=type=secret,id=tok,required,env=TOKEN apt-get update",
+			expectedMounts:  []ast.RunMount{{Type: "cache", Target: "/var/cache/apt", Options: []string{"sharing=locked", "uid=1000"}}, {Type: "secret", ID: "tok", Options: []string{"required", "env=TOKEN"}}},
+			expectedCommand: "apt-get update",
+		},
+		{
+			name:            "no mounts",
+			input:           "RUN echo --mount=type=cache",
+			expectedMounts:  nil,
+			expectedCommand: "echo --mount=type=cache",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := ParseString("FROM alpine\n" + tt.input)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			run, ok := df.Instructions[1].(*ast.RunInstruction)
+			if !ok {
+				t.Fatalf("expected *ast.RunInstruction, got %T", df.Instructions[1])
+			}
+			if !reflect.DeepEqual(run.Mounts, tt.expectedMounts) {
+				t.Errorf("Mounts = %+v, want %+v", run.Mounts, tt.expectedMounts)
+			}
+			if run.Network != tt.expectedNetwork {
+				t.Errorf("Network = %q, want %q", run.Network, tt.expectedNetwork)
+			}
+			if run.Security != tt.expectedSecure {
+				t.Errorf("Security = %q, want %q", run.Security, tt.expectedSecure)
+			}
+			if run.Command != tt.expectedCommand {
+				t.Errorf("Command = %q, want %q", run.Command, tt.expectedCommand)
+			}
+		})
+	}
+}
+
+// TestParseCopyExecForm tests parsing of COPY and ADD in JSON form.
+func TestParseHeredoc(t *testing.T) {
+	t.Run("basic RUN heredoc", func(t *testing.T) {
+		input := "FROM alpine\nRUN <<EOF\napt-get update\napt-get install -y curl\nEOF\nUSER app"
+		df, err := ParseString(input)
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+		if len(df.Instructions) != 3 {
+			t.Fatalf("expected 3 instructions, got %d", len(df.Instructions))
+		}
+
+		run := df.Instructions[1].(*ast.RunInstruction)
+		if !run.IsHeredoc || run.HeredocDelimiter != "EOF" {
+			t.Errorf("RUN heredoc = %v %q, want true EOF", run.IsHeredoc, run.HeredocDelimiter)
+		}
+		if run.Command != "<<EOF\napt-get update\napt-get install -y curl" {
+			t.Errorf("Command = %q", run.Command)
+		}
+		if user := df.Instructions[2]; user.Line() != 6 {
+			t.Errorf("USER line = %d, want 6", user.Line())
+		}
+	})
+
+	t.Run("indented heredoc with <<-", func(t *testing.T) {
+		input := "FROM alpine\nRUN --mount=type=cache,target=/var/cache/apk sh <<-SCRIPT\n\tapk add git\n\tSCRIPT\n"
+		df, err := ParseString(input)
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+
+		run := df.Instructions[1].(*ast.RunInstruction)
+		if !run.IsHeredoc || run.HeredocDelimiter != "SCRIPT" || len(run.Mounts) != 1 {
+			t.Errorf("RUN = %+v, want heredoc SCRIPT with one mount", run)
+		}
+		if run.Command != "sh <<-SCRIPT\n\tapk add git" {
+			t.Errorf("Command = %q", run.Command)
+		}
+	})
+
+	t.Run("COPY heredoc", func(t *testing.T) {
+		input := "FROM alpine\nCOPY <<EOF /etc/app.conf\nport=8080\n# not a Dockerfile comment\nEOF\n"
+		df, err := ParseString(input)
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+		if len(df.Comments) != 0 {
+			t.Errorf("heredoc body parsed as comments: %v", df.Comments)
+		}
+
+		cp := df.Instructions[1].(*ast.CopyInstruction)
+		if cp.HeredocDelimiter != "EOF" || cp.HeredocContent != "port=8080\n# not a Dockerfile comment" || cp.Dest != "/etc/app.conf" {
+			t.Errorf("COPY = %+v", cp)
+		}
+	})
+
+	t.Run("here-string and shift are not heredocs", func(t *testing.T) {
+		df, err := ParseString("FROM alpine\nRUN cat <<< \"text\" && echo $((1<<2))\nUSER app")
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+		if run := df.Instructions[1].(*ast.RunInstruction); run.IsHeredoc {
+			t.Errorf("unexpected heredoc in %q", run.Command)
+		}
+	})
+
+	t.Run("format round trip", func(t *testing.T) {
+		input := "FROM alpine\nRUN <<EOF\nset -e\nmake\nEOF\nCOPY <<CONF /app.conf\nx=1\nCONF"
+		df, err := ParseString(input)
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+		if got := Format(df); got != input {
+			t.Errorf("Format() =\n%s\nwant:\n%s", got, input)
+		}
+	})
+
+	errorTests := []struct {
+		name  string
+		input string
+	}{
+		{"unterminated heredoc", "FROM alpine\nRUN <<EOF\necho hi\n"},
+		{"nested heredocs", "FROM alpine\nRUN <<ONE <<TWO\necho one\nONE\necho two\nTWO\n"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseString(tt.input); err == nil {
+				t.Error("expected a parse error")
+			}
+		})
+	}
+}
+
+func TestParseCopyExecForm(t *testing.T) {
+	input := `FROM alpine
+COPY --chown=app ["my file.txt", "b.txt", "/app"]
+ADD ["a.tar.gz", "/opt/"]`
+
+	df, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	cp := df.Instructions[1].(*ast.CopyInstruction)
+	if !reflect.DeepEqual(cp.Sources, []string{"my file.txt", "b.txt"}) || cp.Dest != "/app" || cp.Chown != "app" {
+		t.Errorf("COPY = %+v, want sources [my file.txt b.txt], dest /app, chown app", cp)
+	}
+
+	add := df.Instructions[2].(*ast.AddInstruction)
+	if !reflect.DeepEqual(add.Sources, []string{"a.tar.gz"}) || add.Dest != "/opt/" {
+		t.Errorf("ADD = %+v, want sources [a.tar.gz], dest /opt/", add)
+	}
+}
+
 // TestParseNewParser tests the NewParser constructor.
 func TestParseNewParser(t *testing.T) {
 	input := "FROM alpine"
@@ -828,6 +1469,105 @@ func TestParseNewParser(t *testing.T) {
 	}
 }
 
+// TestParseMaxLineBytes tests that Parse rejects lines over Parser.MaxLineBytes.
+func TestParseMaxLineBytes(t *testing.T) {
+	long := strings.Repeat("a", 5000)
+
+	tests := []struct {
+		name     string
+		input    string
+		max      int
+		wantLine int                 // 0 means no error expected
+		wantLast ast.InstructionType // last instruction parsed after the error, if checked
+	}{
+		{
+			name:  "no limit",
+			input: "FROM alpine\nRUN echo " + long + "\n",
+		},
+		{
+			name:  "under limit",
+			input: "FROM alpine\nRUN echo hello\n",
+			max:   64,
+		},
+		{
+			name:     "physical line over limit",
+			input:    "FROM alpine\nRUN echo " + long + "\nUSER app\n",
+			max:      1024,
+			wantLine: 2,
+			wantLast: ast.InstrUSER,
+		},
+		{
+			name:     "continuation lines over limit",
+			input:    "FROM alpine\nRUN echo " + strings.Repeat("a", 40) + " \\\n    " + strings.Repeat("b", 40) + "\n",
+			max:      64,
+			wantLine: 2,
+		},
+		{
+			name:     "overlong line inside continuation",
+			input:    "FROM alpine\nRUN echo \\\n    " + strings.Repeat("a", 60) + " \\\n    && echo b\nUSER app\n",
+			max:      50,
+			wantLine: 2,
+			wantLast: ast.InstrUSER,
+		},
+		{
+			name:     "overlong first line of continuation",
+			input:    "FROM alpine\nRUN echo " + long + " \\\n    && echo b \\\n    && echo c\nUSER app\n",
+			max:      50,
+			wantLine: 2,
+			wantLast: ast.InstrUSER,
+		},
+		{
+			name:     "overlong comment is not continued",
+			input:    "FROM alpine\n# " + long + " \\\nUSER app\nRUN echo b\n",
+			max:      50,
+			wantLine: 2,
+			wantLast: ast.InstrRUN,
+		},
+		{
+			// Heredoc errors are reported on the instruction line
+			name:     "heredoc body line over limit",
+			input:    "FROM alpine\nRUN <<EOF\necho " + long + "\necho b\nEOF\nUSER app\n",
+			max:      1024,
+			wantLine: 2,
+			wantLast: ast.InstrUSER,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{MaxLineBytes: tt.max}
+			df, err := p.Parse(strings.NewReader(tt.input))
+
+			if tt.wantLine == 0 {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				return
+			}
+
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Parse() error = %v, want *ParseError", err)
+			}
+			if pe.Line != tt.wantLine {
+				t.Errorf("ParseError.Line = %d, want %d", pe.Line, tt.wantLine)
+			}
+			if !strings.Contains(pe.Message, "maximum length") {
+				t.Errorf("ParseError.Message = %q, want it to mention the maximum length", pe.Message)
+			}
+
+			// The rest of the logical line is skipped, not parsed on its own
+			var pes *ParseErrors
+			if errors.As(err, &pes) && len(pes.Errors) != 1 {
+				t.Errorf("Parse() returned %d errors, want 1: %v", len(pes.Errors), err)
+			}
+			if last := df.Instructions[len(df.Instructions)-1]; tt.wantLast != "" && last.Type() != tt.wantLast {
+				t.Errorf("last instruction = %s, want %s", last.Type(), tt.wantLast)
+			}
+		})
+	}
+}
+
 // TestParseComplexDockerfile tests parsing a realistic, complex Dockerfile.
 func TestParseComplexDockerfile(t *testing.T) {
 	input := `# syntax=docker/dockerfile:1
@@ -902,3 +1642,98 @@ func TestParseRawText(t *testing.T) {
 		t.Errorf("Raw() = %q, want %q", raw, input)
 	}
 }
+
+// BenchmarkParseLargeDockerfile parses a generated 10k-instruction Dockerfile.
+func BenchmarkParseLargeDockerfile(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("FROM alpine:3.18\n")
+	for i := 0; i < 10000; i++ {
+		switch i % 4 {
+		case 0:
+			sb.WriteString("RUN apk add --no-cache curl && \\\n    echo step " + strings.Repeat("x", 64) + "\n")
+		case 1:
+			sb.WriteString("ENV KEY_" + strings.Repeat("A", i%8+1) + "=value\n")
+		case 2:
+			sb.WriteString("COPY src/ /app/src/\n")
+		default:
+			sb.WriteString("# generated step\nWORKDIR /app\n")
+		}
+	}
+	input := sb.String()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := &Parser{MaxLineBytes: 4096}
+		if _, err := p.Parse(strings.NewReader(input)); err != nil {
+			b.Fatalf("Parse() error = %v", err)
+		}
+	}
+}
+
+// benchmarkDockerfile is a realistic 50-line multi-stage Dockerfile with
+// continuations, comments, flags and exec-form instructions.
+const benchmarkDockerfile = `# syntax=docker/dockerfile:1
+ARG GO_VERSION=1.22
+
+# Build stage
+FROM golang:${GO_VERSION}-alpine AS build
+WORKDIR /src
+RUN apk add --no-cache git ca-certificates \
+    && update-ca-certificates
+COPY go.mod go.sum ./
+RUN --mount=type=cache,target=/go/pkg/mod go mod download
+COPY . .
+ARG VERSION=dev
+ENV CGO_ENABLED=0 GOOS=linux
+RUN --mount=type=cache,target=/root/.cache/go-build \
+    go build -ldflags "-s -w -X main.version=${VERSION}" -o /out/server ./cmd/server
+
+# Assets stage
+FROM node:20-alpine AS assets
+WORKDIR /web
+COPY web/package.json web/package-lock.json ./
+RUN npm ci --omit=dev
+COPY web/ ./
+RUN npm run build
+
+# Runtime stage
+FROM alpine:3.18
+LABEL org.opencontainers.image.title="server" \
+      org.opencontainers.image.source="https://github.com/example/server" \
+      org.opencontainers.image.licenses="MIT"
+RUN apk add --no-cache tzdata \
+    && addgroup -S app \
+    && adduser -S -G app -h /app app
+ENV TZ=UTC \
+    APP_HOME=/app \
+    APP_PORT=8080
+WORKDIR /app
+COPY --from=build --chown=app:app /out/server /app/server
+COPY --from=assets --chown=app:app /web/dist /app/static
+COPY --chmod=644 config/app.yaml /etc/app/app.yaml
+VOLUME ["/app/data"]
+EXPOSE 8080
+USER app
+HEALTHCHECK --interval=30s --timeout=3s \
+    CMD wget -qO- http://localhost:8080/healthz || exit 1
+STOPSIGNAL SIGTERM
+SHELL ["/bin/sh", "-c"]
+ONBUILD COPY . /app/src
+ENTRYPOINT ["/app/server"]
+CMD ["--config", "/etc/app/app.yaml"]
+# end of file
+`
+
+// BenchmarkParseString parses a realistic 50-line Dockerfile.
+// Target: <200µs per parse, about 4µs per line.
+func BenchmarkParseString(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkDockerfile)))
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseString(benchmarkDockerfile); err != nil {
+			b.Fatalf("ParseString() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/devblac/docker-lint/internal/ast"
+)
+
+// FixResult is the outcome of applying fixes to a Dockerfile.
+type FixResult struct {
+	// Source is the patched Dockerfile source.
+	Source []byte
+
+	// Fixed is the number of findings fixed.
+	Fixed int
+
+	// Unfixable explains, for each finding that has to be fixed by hand or
+	// whose edits no longer match the source, why it was left alone.
+	Unfixable []error
+}
+
+// AutoFixer applies the edits returned by a Fixer to Dockerfile source text.
+// Only the edited text changes, so comments, blank lines and line
+// continuations are preserved.
+type AutoFixer struct {
+	fixer Fixer
+}
+
+// NewAutoFixer creates an AutoFixer for the given Fixer.
+func NewAutoFixer(fixer Fixer) *AutoFixer {
+	return &AutoFixer{fixer: fixer}
+}
+
+// Apply fixes findings in source, the text dockerfile was parsed from.
+// Findings are fixed from the bottom of the file up, so the line numbers of
+// findings not yet fixed stay valid. A finding is fixed completely or not at
+// all.
+func (a *AutoFixer) Apply(source []byte, dockerfile *ast.Dockerfile, findings []ast.Finding) *FixResult {
+	result := &FixResult{}
+
+	escape := '\\'
+	if dockerfile != nil && dockerfile.EscapeChar != 0 {
+		escape = dockerfile.EscapeChar
+	}
+
+	sorted := append([]ast.Finding(nil), findings...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Line > sorted[j].Line })
+
+	lines := strings.SplitAfter(string(source), "\n")
+	for _, finding := range sorted {
+		fixes, err := a.fixer.Fix(finding, dockerfile)
+		if err != nil {
+			result.Unfixable = append(result.Unfixable, fmt.Errorf("line %d: %s: %w", finding.Line, finding.RuleID, err))
+			continue
+		}
+		if len(fixes) == 0 {
+			continue
+		}
+
+		patched, err := applyFixes(lines, fixes, escape)
+		if err != nil {
+			result.Unfixable = append(result.Unfixable, fmt.Errorf("line %d: %s: %w", finding.Line, finding.RuleID, err))
+			continue
+		}
+		lines = patched
+		result.Fixed++
+	}
+
+	result.Source = []byte(strings.Join(lines, ""))
+	return result
+}
+
+// applyFixes applies fixes to a copy of lines, in descending line order.
+func applyFixes(lines []string, fixes []Fix, escape rune) ([]string, error) {
+	patched := append([]string(nil), lines...)
+
+	sorted := append([]Fix(nil), fixes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Line > sorted[j].Line })
+
+	for _, fix := range sorted {
+		if fix.Line < 1 || fix.Line > len(patched) {
+			return nil, fmt.Errorf("line %d is out of range", fix.Line)
+		}
+		start, end := instructionRange(patched, fix.Line-1, escape)
+
+		if fix.OldText == "" {
+			patched[end] = appendToLine(patched[end], fix.NewText)
+			continue
+		}
+
+		keywordStart := len(patched[start]) - len(strings.TrimLeft(patched[start], " \t"))
+		keywordEnd := keywordStart + strings.IndexAny(patched[start][keywordStart:]+" ", " \t\r\n")
+		if fix.Keyword {
+			if !strings.EqualFold(patched[start][keywordStart:keywordEnd], fix.OldText) {
+				return nil, fmt.Errorf("keyword %q not found", fix.OldText)
+			}
+			patched[start] = patched[start][:keywordStart] + fix.NewText + patched[start][keywordEnd:]
+			continue
+		}
+
+		// Only the arguments are searched, so an argument spelled like the
+		// keyword is edited rather than the keyword, and the other way round
+		line, pos := -1, -1
+		for i := start; i <= end && pos < 0; i++ {
+			offset := 0
+			if i == start {
+				offset = keywordEnd
+			}
+			if p := findWord(patched[i][offset:], fix.OldText); p >= 0 {
+				line, pos = i, offset+p
+			}
+		}
+		if pos < 0 {
+			return nil, fmt.Errorf("%q not found", fix.OldText)
+		}
+		patched[line] = patched[line][:pos] + fix.NewText + patched[line][pos+len(fix.OldText):]
+	}
+
+	return patched, nil
+}
+
+// instructionRange returns the indexes of the first and last physical lines
+// of the instruction that lines[i] belongs to, following line continuations.
+func instructionRange(lines []string, i int, escape rune) (int, int) {
+	continues := func(line string) bool {
+		trimmed := strings.TrimRight(line, " \t\r\n")
+		return !strings.HasPrefix(strings.TrimSpace(trimmed), "#") && strings.HasSuffix(trimmed, string(escape))
+	}
+
+	start, end := i, i
+	for start > 0 && continues(lines[start-1]) {
+		start--
+	}
+	for end < len(lines)-1 && continues(lines[end]) {
+		end++
+	}
+	return start, end
+}
+
+// appendToLine appends text to a line, before its line ending. Trailing
+// blanks are dropped.
+func appendToLine(line, text string) string {
+	content := strings.TrimRight(line, " \t\r\n")
+	return content + text + strings.TrimLeft(line[len(content):], " \t")
+}
+
+// findWord returns the index of the first occurrence of word in s that is not
+// part of a longer word, or -1.
+func findWord(s, word string) int {
+	for offset := 0; offset < len(s); {
+		pos := strings.Index(s[offset:], word)
+		if pos < 0 {
+			return -1
+		}
+		pos += offset
+		end := pos + len(word)
+		if (pos == 0 || isWordBoundary(s[pos-1])) && (end == len(s) || isWordBoundary(s[end])) {
+			return pos
+		}
+		offset = pos + 1
+	}
+	return -1
+}
+
+// isWordBoundary reports whether c separates words in a Dockerfile line.
+func isWordBoundary(c byte) bool {
+	return strings.IndexByte(" \t\r\n;&|()\"'", c) >= 0
+}
+
+// instructionAt returns the instruction starting on line, or nil.
+func instructionAt(dockerfile *ast.Dockerfile, line int) ast.Instruction {
+	if dockerfile == nil {
+		return nil
+	}
+	for _, instr := range dockerfile.Instructions {
+		if instr.Line() == line {
+			return instr
+		}
+	}
+	return nil
+}
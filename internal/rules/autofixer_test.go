@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
+)
+
+// fixContent parses content, runs rule on it and applies the rule's fixes.
+func fixContent(t *testing.T, rule interface {
+	Rule
+	Fixer
+}, content string) *FixResult {
+	t.Helper()
+	df, err := parser.ParseString(content)
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+	return NewAutoFixer(rule).Apply([]byte(content), df, rule.Check(df))
+}
+
+// fixerFunc adapts a function to the Fixer interface.
+type fixerFunc func(ast.Finding, *ast.Dockerfile) ([]Fix, error)
+
+func (f fixerFunc) Fix(finding ast.Finding, df *ast.Dockerfile) ([]Fix, error) { return f(finding, df) }
+
+func TestAutoFixer_Apply(t *testing.T) {
+	source := "FROM alpine:3.18\n" +
+		"WORKDIR app\n" +
+		"RUN apk update \\\n" +
+		"    && apk add curl  \n" +
+		"\n" +
+		"# keep me\n" +
+		"RUN echo app\n"
+
+	fixes := map[int][]Fix{
+		2: {{Line: 2, OldText: "app", NewText: "/app"}},
+		3: {{Line: 3, NewText: " && rm -rf /var/cache/apk/*"}, {Line: 3, OldText: "curl", NewText: "curl git"}},
+		7: {{Line: 7, OldText: "missing", NewText: "x"}},
+	}
+	fixer := fixerFunc(func(f ast.Finding, _ *ast.Dockerfile) ([]Fix, error) {
+		if f.Line == 1 {
+			return nil, errors.New("fix by hand")
+		}
+		return fixes[f.Line], nil
+	})
+
+	findings := []ast.Finding{{RuleID: "T1", Line: 2}, {RuleID: "T1", Line: 3}, {RuleID: "T2", Line: 1}, {RuleID: "T3", Line: 7}, {RuleID: "T4", Line: 5}}
+	result := NewAutoFixer(fixer).Apply([]byte(source), nil, findings)
+
+	expected := "FROM alpine:3.18\n" +
+		"WORKDIR /app\n" +
+		"RUN apk update \\\n" +
+		"    && apk add curl git && rm -rf /var/cache/apk/*\n" +
+		"\n" +
+		"# keep me\n" +
+		"RUN echo app\n"
+	if string(result.Source) != expected {
+		t.Errorf("Source =\n%s\nwant\n%s", result.Source, expected)
+	}
+	if result.Fixed != 2 {
+		t.Errorf("Fixed = %d, want 2", result.Fixed)
+	}
+	if len(result.Unfixable) != 2 {
+		t.Fatalf("Unfixable = %v, want 2 errors", result.Unfixable)
+	}
+	for _, want := range []string{"line 7: T3: \"missing\" not found", "line 1: T2: fix by hand"} {
+		found := false
+		for _, err := range result.Unfixable {
+			found = found || err.Error() == want
+		}
+		if !found {
+			t.Errorf("Unfixable = %v, want %q", result.Unfixable, want)
+		}
+	}
+}
+
+func TestAutoFixer_Keyword(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		fix      Fix
+		expected string
+	}{
+		{"keyword spelled like an argument", "ADD ADD /x/\n", Fix{Line: 1, OldText: "ADD", NewText: "COPY", Keyword: true}, "COPY ADD /x/\n"},
+		{"lowercase keyword", "  add app.tar /app/\n", Fix{Line: 1, OldText: "ADD", NewText: "COPY", Keyword: true}, "  COPY app.tar /app/\n"},
+		{"argument spelled like the keyword", "ADD ADD /x/\n", Fix{Line: 1, OldText: "ADD", NewText: "app"}, "ADD app /x/\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixer := fixerFunc(func(ast.Finding, *ast.Dockerfile) ([]Fix, error) {
+				return []Fix{tt.fix}, nil
+			})
+			result := NewAutoFixer(fixer).Apply([]byte(tt.source), nil, []ast.Finding{{RuleID: "T1", Line: 1}})
+			if string(result.Source) != tt.expected {
+				t.Errorf("Source = %q, want %q", result.Source, tt.expected)
+			}
+		})
+	}
+
+	fixer := fixerFunc(func(ast.Finding, *ast.Dockerfile) ([]Fix, error) {
+		return []Fix{{Line: 1, OldText: "ADD", NewText: "COPY", Keyword: true}}, nil
+	})
+	result := NewAutoFixer(fixer).Apply([]byte("COPY ADD /x/\n"), nil, []ast.Finding{{RuleID: "T1", Line: 1}})
+	if len(result.Unfixable) != 1 || result.Unfixable[0].Error() != "line 1: T1: keyword \"ADD\" not found" {
+		t.Errorf("Unfixable = %v, want the keyword mismatch", result.Unfixable)
+	}
+}
+
+func TestAutoFixer_EscapeDirective(t *testing.T) {
+	source := "# escape=`\nFROM mcr.microsoft.com/windows/servercore:ltsc2022\nRUN apt-get install -y curl `\n    git\n"
+	result := fixContent(t, &CacheNotCleanedRule{}, source)
+
+	if !strings.HasSuffix(string(result.Source), "    git && rm -rf /var/lib/apt/lists/*\n") {
+		t.Errorf("cleanup not appended to the last line of the RUN:\n%s", result.Source)
+	}
+}
+
+func TestFindWord(t *testing.T) {
+	tests := []struct {
+		s, word string
+		want    int
+	}{
+		{"WORKDIR app", "app", 8},
+		{"WORKDIR D", "D", 8},
+		{"RUN pip install x && pip3 install y", "pip3 install", 21},
+		{`WORKDIR "app"`, "app", 9},
+		{"RUN install-app", "app", -1},
+	}
+
+	for _, tt := range tests {
+		if got := findWord(tt.s, tt.word); got != tt.want {
+			t.Errorf("findWord(%q, %q) = %d, want %d", tt.s, tt.word, got, tt.want)
+		}
+	}
+}
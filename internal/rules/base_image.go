@@ -2,6 +2,8 @@
 package rules
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/devblac/docker-lint/internal/ast"
@@ -32,11 +34,36 @@ type MissingTagRule struct{}
 func (r *MissingTagRule) ID() string             { return RuleMissingTag }
 func (r *MissingTagRule) Name() string           { return "Missing explicit image tag" }
 func (r *MissingTagRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *MissingTagRule) Category() string       { return CategoryBestPractice }
 
 func (r *MissingTagRule) Description() string {
 	return "Always tag the version of an image explicitly to ensure reproducible builds"
 }
 
+func (r *MissingTagRule) LongDescription() string {
+	return "An image without a tag or digest resolves to 'latest', which changes whenever the publisher pushes a new version. Builds then pick up new operating system releases and language runtimes without any change to the Dockerfile.\n\n" +
+		"Pin a version tag, or a digest for fully reproducible builds."
+}
+
+func (r *MissingTagRule) BadExample() string {
+	return "FROM ubuntu"
+}
+
+func (r *MissingTagRule) GoodExample() string {
+	return "FROM ubuntu:22.04"
+}
+
+func (r *MissingTagRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#from",
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *MissingTagRule) DocURL() string {
+	return "https://github.com/hadolint/hadolint/wiki/DL3006"
+}
+
 func (r *MissingTagRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
@@ -46,18 +73,7 @@ func (r *MissingTagRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 			continue
 		}
 
-		// Skip scratch image (special case, no tag needed)
-		if strings.ToLower(from.Image) == "scratch" {
-			continue
-		}
-
-		// Skip if using digest (more specific than tag)
-		if from.Digest != "" {
-			continue
-		}
-
-		// Check if tag is missing (empty)
-		if from.Tag == "" {
+		if isUntaggedImage(from.Image, from.Tag, from.Digest) {
 			findings = append(findings, ast.Finding{
 				RuleID:     r.ID(),
 				Severity:   r.Severity(),
@@ -72,17 +88,52 @@ func (r *MissingTagRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	return findings
 }
 
+// Fix does not pick a tag: which version to pin is the author's decision, and
+// 'latest' is what the build already uses. It returns an error asking for one.
+func (r *MissingTagRule) Fix(finding ast.Finding, dockerfile *ast.Dockerfile) ([]Fix, error) {
+	from, ok := instructionAt(dockerfile, finding.Line).(*ast.FromInstruction)
+	if !ok || !isUntaggedImage(from.Image, from.Tag, from.Digest) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("choose a tag for image '%s', e.g. '%s:<version>'", from.Image, from.Image)
+}
+
 // LatestTagRule checks for FROM instructions using the 'latest' tag (DL3007).
 type LatestTagRule struct{}
 
 func (r *LatestTagRule) ID() string             { return RuleLatestTag }
 func (r *LatestTagRule) Name() string           { return "Using 'latest' tag" }
 func (r *LatestTagRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *LatestTagRule) Category() string       { return CategoryBestPractice }
 
 func (r *LatestTagRule) Description() string {
 	return "Using 'latest' tag can lead to unpredictable builds as the image may change"
 }
 
+func (r *LatestTagRule) LongDescription() string {
+	return "The 'latest' tag is a moving target: it points to whatever the publisher pushed last. Two builds of the same Dockerfile can produce different images, and a rebuild can break without any change to the Dockerfile.\n\n" +
+		"Pin a version tag, or a digest for fully reproducible builds."
+}
+
+func (r *LatestTagRule) BadExample() string {
+	return "FROM node:latest"
+}
+
+func (r *LatestTagRule) GoodExample() string {
+	return "FROM node:20.11-alpine"
+}
+
+func (r *LatestTagRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#from",
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *LatestTagRule) DocURL() string {
+	return "https://github.com/hadolint/hadolint/wiki/DL3007"
+}
+
 func (r *LatestTagRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
@@ -108,7 +159,7 @@ func (r *LatestTagRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 				RuleID:     r.ID(),
 				Severity:   r.Severity(),
 				Line:       from.Line(),
-				Column:     1,
+				Column:     max(from.TagColumn, 1),
 				Message:    "Using 'latest' tag for image '" + from.Image + "' is not recommended",
 				Suggestion: "Pin to a specific version like '" + from.Image + ":<version>' for reproducible builds",
 			})
@@ -119,16 +170,100 @@ func (r *LatestTagRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 }
 
 // LargeBaseImageRule checks for large base images without slim variants (DL3008).
-type LargeBaseImageRule struct{}
+type LargeBaseImageRule struct {
+	// images replaces largeBaseImages when the large_images option is set.
+	images map[string]string
+}
 
 func (r *LargeBaseImageRule) ID() string             { return RuleLargeBaseImage }
 func (r *LargeBaseImageRule) Name() string           { return "Large base image" }
 func (r *LargeBaseImageRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *LargeBaseImageRule) Category() string       { return CategoryPerformance }
 
 func (r *LargeBaseImageRule) Description() string {
 	return "Consider using a smaller base image variant to reduce image size"
 }
 
+func (r *LargeBaseImageRule) LongDescription() string {
+	return "The default variants of many official images include compilers, documentation and tools that applications rarely need at run time. They make images larger, slower to pull and expose more packages to vulnerabilities.\n\n" +
+		"Most official images publish -slim or -alpine variants. Use them for the final stage, or build in a full image and copy the result into a smaller one."
+}
+
+func (r *LargeBaseImageRule) BadExample() string {
+	return "FROM python:3.12"
+}
+
+func (r *LargeBaseImageRule) GoodExample() string {
+	return "FROM python:3.12-slim"
+}
+
+func (r *LargeBaseImageRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+		"https://docs.docker.com/build/building/multi-stage/",
+	}
+}
+
+func (r *LargeBaseImageRule) Options() []RuleOption {
+	return []RuleOption{largeImagesOption}
+}
+
+func (r *LargeBaseImageRule) Configure(options map[string]string) error {
+	images, err := configureLargeImages(options)
+	if err != nil {
+		return err
+	}
+	if images != nil {
+		r.images = images
+	}
+	return nil
+}
+
+// largeImagesOption is the option of DL3008 and DL4010 that replaces the
+// built-in list of large images.
+var largeImagesOption = RuleOption{Key: "large_images", Description: "Comma-separated image names, without registry or repository path, to report instead of the built-in list, e.g. ubuntu,node,corp-base"}
+
+// configureLargeImages returns the images set by the large_images option, or
+// nil if the option is not set.
+func configureLargeImages(options map[string]string) (map[string]string, error) {
+	var images map[string]string
+	for key, value := range options {
+		if key != largeImagesOption.Key {
+			return nil, fmt.Errorf("unknown option %q", key)
+		}
+
+		images = make(map[string]string)
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			alternative, known := largeBaseImages[name]
+			if !known {
+				alternative = "a -slim or -alpine variant"
+			}
+			images[name] = alternative
+		}
+		if len(images) == 0 {
+			return nil, fmt.Errorf("large_images must list at least one image")
+		}
+	}
+	return images, nil
+}
+
+// largeImageAlternative returns the suggested alternative when a FROM uses a
+// large image from images, or largeBaseImages if images is nil.
+func largeImageAlternative(from *ast.FromInstruction, images map[string]string) (string, bool) {
+	if images == nil {
+		images = largeBaseImages
+	}
+	alternative, isLarge := images[extractBaseImageName(from.Image)]
+	if !isLarge || isSlimVariant(from.Tag) {
+		return "", false
+	}
+	return alternative, true
+}
+
 func (r *LargeBaseImageRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
@@ -138,21 +273,12 @@ func (r *LargeBaseImageRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 			continue
 		}
 
-		// Extract base image name (without registry prefix)
-		imageName := extractBaseImageName(from.Image)
-
-		// Check if it's a known large base image
-		alternative, isLarge := largeBaseImages[imageName]
+		// Known large images are fine in their slim/alpine variants
+		alternative, isLarge := largeImageAlternative(from, r.images)
 		if !isLarge {
 			continue
 		}
 
-		// Check if already using a slim/alpine variant
-		tag := strings.ToLower(from.Tag)
-		if isSlimVariant(tag) {
-			continue
-		}
-
 		findings = append(findings, ast.Finding{
 			RuleID:     r.ID(),
 			Severity:   r.Severity(),
@@ -166,6 +292,192 @@ func (r *LargeBaseImageRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	return findings
 }
 
+// FinalStageLargeImageRule checks whether the final build stage, which
+// produces the shipped image, is based on a large image (DL4010).
+type FinalStageLargeImageRule struct {
+	// images replaces largeBaseImages when the large_images option is set.
+	images map[string]string
+}
+
+func (r *FinalStageLargeImageRule) ID() string             { return RuleFinalStageLargeImage }
+func (r *FinalStageLargeImageRule) Name() string           { return "Large image in final stage" }
+func (r *FinalStageLargeImageRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *FinalStageLargeImageRule) Category() string       { return CategoryPerformance }
+
+func (r *FinalStageLargeImageRule) Description() string {
+	return "Base the final stage on a small image; large images belong in builder stages"
+}
+
+func (r *FinalStageLargeImageRule) LongDescription() string {
+	return "Only the final stage of a multi-stage build ends up in the shipped image. A large image such as golang or ubuntu is a fine place to compile, but shipping it adds hundreds of megabytes of compilers and tools that the application does not use and that widen its attack surface.\n\n" +
+		"Build in a large image and copy the result into a final stage based on a -slim, -alpine or distroless image. Unlike DL3008, this rule ignores builder stages; ignore DL3008 to only report the final stage."
+}
+
+func (r *FinalStageLargeImageRule) BadExample() string {
+	return "FROM golang:1.22 AS build\n" +
+		"RUN go build -o /app .\n" +
+		"FROM golang:1.22\n" +
+		"COPY --from=build /app /app"
+}
+
+func (r *FinalStageLargeImageRule) GoodExample() string {
+	return "FROM golang:1.22 AS build\n" +
+		"RUN go build -o /app .\n" +
+		"FROM gcr.io/distroless/static-debian12\n" +
+		"COPY --from=build /app /app"
+}
+
+func (r *FinalStageLargeImageRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/multi-stage/",
+	}
+}
+
+func (r *FinalStageLargeImageRule) Options() []RuleOption {
+	return []RuleOption{largeImagesOption}
+}
+
+func (r *FinalStageLargeImageRule) Configure(options map[string]string) error {
+	images, err := configureLargeImages(options)
+	if err != nil {
+		return err
+	}
+	if images != nil {
+		r.images = images
+	}
+	return nil
+}
+
+func (r *FinalStageLargeImageRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	stage, ok := dockerfile.StageByIndex(len(dockerfile.Stages) - 1)
+	if !ok || stage.FromInstr == nil {
+		return nil
+	}
+
+	// A final stage built FROM an earlier stage ships that stage's image
+	for {
+		parent, ok := dockerfile.StageByName(stage.FromInstr.Image)
+		if !ok || parent.Index >= stage.Index || parent.FromInstr == nil {
+			break
+		}
+		stage = parent
+	}
+
+	from := stage.FromInstr
+	alternative, isLarge := largeImageAlternative(from, r.images)
+	if !isLarge {
+		return nil
+	}
+
+	return []ast.Finding{{
+		RuleID:     r.ID(),
+		Severity:   r.Severity(),
+		Line:       from.Line(),
+		Column:     1,
+		Message:    "Final stage is based on large image '" + from.Image + "'",
+		Suggestion: "Build in '" + from.Image + "' and copy the result into a final stage based on " + alternative,
+	}}
+}
+
+// predefinedBuildArgs are the build arguments that can be used in FROM
+// without an ARG declaration.
+var predefinedBuildArgs = map[string]bool{
+	"BUILDPLATFORM":  true,
+	"BUILDOS":        true,
+	"BUILDARCH":      true,
+	"BUILDVARIANT":   true,
+	"TARGETPLATFORM": true,
+	"TARGETOS":       true,
+	"TARGETARCH":     true,
+	"TARGETVARIANT":  true,
+	"HTTP_PROXY":     true,
+	"http_proxy":     true,
+	"HTTPS_PROXY":    true,
+	"https_proxy":    true,
+	"FTP_PROXY":      true,
+	"ftp_proxy":      true,
+	"NO_PROXY":       true,
+	"no_proxy":       true,
+	"ALL_PROXY":      true,
+	"all_proxy":      true,
+}
+
+// variablePattern matches $NAME and ${NAME} references, and ${NAME:-word}
+// style references whose modifier is captured in group 2. The name is in
+// group 1 or group 3.
+var variablePattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)([:+-][^}]*)?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// UndeclaredArgInFromRule checks for FROM instructions that reference build
+// arguments not declared before the first FROM (DL4009).
+type UndeclaredArgInFromRule struct{}
+
+func (r *UndeclaredArgInFromRule) ID() string             { return RuleUndeclaredArgInFrom }
+func (r *UndeclaredArgInFromRule) Name() string           { return "Undeclared ARG in FROM" }
+func (r *UndeclaredArgInFromRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *UndeclaredArgInFromRule) Category() string       { return CategoryCorrectness }
+
+func (r *UndeclaredArgInFromRule) Description() string {
+	return "Variables used in FROM must be declared with ARG before the first FROM; otherwise they expand to an empty string"
+}
+
+func (r *UndeclaredArgInFromRule) LongDescription() string {
+	return "FROM can only use build arguments declared before the first FROM instruction, or the predefined platform and proxy arguments. An undeclared variable expands to an empty string, so FROM golang:${GO_VERSION} silently becomes FROM golang: and the build fails or uses an unexpected image.\n\n" +
+		"An ARG declared inside a build stage is only in scope for that stage and cannot be used in a later FROM."
+}
+
+func (r *UndeclaredArgInFromRule) BadExample() string {
+	return "FROM golang:${GO_VERSION}-alpine"
+}
+
+func (r *UndeclaredArgInFromRule) GoodExample() string {
+	return "ARG GO_VERSION=1.22\n" +
+		"FROM golang:${GO_VERSION}-alpine"
+}
+
+func (r *UndeclaredArgInFromRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#understand-how-arg-and-from-interact",
+		"https://docs.docker.com/reference/dockerfile/#automatic-platform-args-in-the-global-scope",
+	}
+}
+
+func (r *UndeclaredArgInFromRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Only ARGs in the global scope, before the first FROM, apply to FROM lines
+	declared := make(map[string]bool)
+	seenFrom := false
+	for _, instr := range dockerfile.Instructions {
+		switch v := instr.(type) {
+		case *ast.ArgInstruction:
+			if !seenFrom {
+				declared[v.Name] = true
+			}
+		case *ast.FromInstruction:
+			seenFrom = true
+			reported := make(map[string]bool)
+			for _, match := range variablePattern.FindAllStringSubmatch(v.Raw(), -1) {
+				name := match[1] + match[3]
+				// ${NAME:-default} has a value even when NAME is not declared
+				if match[2] != "" || declared[name] || predefinedBuildArgs[name] || reported[name] {
+					continue
+				}
+				reported[name] = true
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       v.Line(),
+					Column:     1,
+					Message:    "FROM uses build argument '" + name + "' that is not declared before the first FROM; it expands to an empty string",
+					Suggestion: "Declare 'ARG " + name + "=<default>' before the first FROM instruction",
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
 // extractBaseImageName extracts the image name without registry prefix.
 // e.g., "docker.io/library/ubuntu" -> "ubuntu"
 // e.g., "gcr.io/project/ubuntu" -> "ubuntu"
@@ -176,6 +488,51 @@ func extractBaseImageName(image string) string {
 	return strings.ToLower(name)
 }
 
+// commonImages are well-known Docker Hub images that are not in largeBaseImages.
+var commonImages = map[string]bool{
+	"alpine":    true,
+	"busybox":   true,
+	"nginx":     true,
+	"httpd":     true,
+	"caddy":     true,
+	"traefik":   true,
+	"redis":     true,
+	"postgres":  true,
+	"mysql":     true,
+	"mariadb":   true,
+	"mongo":     true,
+	"docker":    true,
+	"bash":      true,
+	"gcc":       true,
+	"maven":     true,
+	"gradle":    true,
+	"dotnet":    true,
+	"archlinux": true,
+}
+
+// isKnownImageName reports whether name is a well-known Docker Hub image.
+func isKnownImageName(name string) bool {
+	name = strings.ToLower(name)
+	_, large := largeBaseImages[name]
+	return large || commonImages[name]
+}
+
+// isUntaggedImage reports whether an image reference has neither a tag nor a
+// digest, so that it defaults to 'latest'. The scratch image needs neither.
+func isUntaggedImage(image, tag, digest string) bool {
+	return strings.ToLower(image) != "scratch" && tag == "" && digest == ""
+}
+
+// splitImageRef splits an image reference like registry:5000/app:1.0@sha256:abc
+// into its image name, tag and digest.
+func splitImageRef(ref string) (image, tag, digest string) {
+	image, digest, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, tag = image[:i], image[i+1:]
+	}
+	return image, tag, digest
+}
+
 // isSlimVariant checks if the tag indicates a slim/alpine/minimal variant.
 func isSlimVariant(tag string) bool {
 	if tag == "" {
@@ -196,4 +553,6 @@ func init() {
 	RegisterDefault(&MissingTagRule{})
 	RegisterDefault(&LatestTagRule{})
 	RegisterDefault(&LargeBaseImageRule{})
+	RegisterDefault(&UndeclaredArgInFromRule{})
+	RegisterDefault(&FinalStageLargeImageRule{})
 }
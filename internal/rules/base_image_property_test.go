@@ -194,8 +194,78 @@ func TestLatestTagDetectionCompleteness(t *testing.T) {
 	properties.TestingRun(t)
 }
 
+// **Feature: docker-lint, Property: Final Stage Large Image Detection**
+//
+// Property: For any multi-stage Dockerfile, DL4010 SHALL only consider the final
+// stage: large images in builder stages never produce a finding, and a final stage
+// based on a large image produces exactly one.
+func TestFinalStageLargeImageDetection(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.MaxSize = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	rule := &FinalStageLargeImageRule{}
+
+	properties.Property("large images in builder stages only produce no findings", prop.ForAll(
+		func(builders []string, final string) bool {
+			findings := rule.Check(buildStagesDockerfile(builders, final))
+			return len(findings) == 0
+		},
+		genLargeImageSlice(1, 4),
+		genSmallFinalImage(),
+	))
+
+	properties.Property("a large final image produces exactly one finding", prop.ForAll(
+		func(builders []string, final string) bool {
+			findings := rule.Check(buildStagesDockerfile(builders, final))
+			return len(findings) == 1 && findings[0].Line == len(builders)+1
+		},
+		genLargeImageSlice(0, 4),
+		genLargeImage(),
+	))
+
+	properties.TestingRun(t)
+}
+
+// buildStagesDockerfile builds a Dockerfile with one builder stage per image
+// in builders, followed by a final stage based on final.
+func buildStagesDockerfile(builders []string, final string) *ast.Dockerfile {
+	df := &ast.Dockerfile{}
+	for i, image := range append(append([]string(nil), builders...), final) {
+		from := &ast.FromInstruction{LineNum: i + 1, Image: image, Tag: "1.0"}
+		if i < len(builders) {
+			from.Alias = "builder" + string(rune('a'+i))
+		}
+		df.Instructions = append(df.Instructions, from)
+		df.Stages = append(df.Stages, ast.Stage{Name: from.Alias, FromInstr: from, Instructions: []ast.Instruction{from}, Index: i})
+	}
+	return df
+}
+
 // Generator helpers
 
+// genLargeImage generates images in the built-in list of large images
+func genLargeImage() gopter.Gen {
+	return gen.OneConstOf("ubuntu", "debian", "golang", "node", "python", "openjdk")
+}
+
+// genSmallFinalImage generates images that are not in the list of large images
+func genSmallFinalImage() gopter.Gen {
+	return gen.OneConstOf("alpine", "busybox", "gcr.io/distroless/static-debian12", "scratch", "nginx")
+}
+
+// genLargeImageSlice generates a slice of large image names
+func genLargeImageSlice(minSize, maxSize int) gopter.Gen {
+	return gen.SliceOfN(maxSize, genLargeImage()).FlatMap(func(v interface{}) gopter.Gen {
+		images := v.([]string)
+		return gen.IntRange(minSize, maxSize).Map(func(n int) []string {
+			return images[:n]
+		})
+	}, reflect.TypeOf([]string{}))
+}
+
 // genNonScratchImageName generates image names that are not "scratch"
 func genNonScratchImageName() gopter.Gen {
 	return gen.OneConstOf(
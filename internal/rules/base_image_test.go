@@ -1,17 +1,21 @@
 package rules
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
 )
 
 func TestBaseImageRulesRegistered(t *testing.T) {
 	// Verify all base image rules are registered
 	expectedRules := []string{
-		RuleMissingTag,     // DL3006
-		RuleLatestTag,      // DL3007
-		RuleLargeBaseImage, // DL3008
+		RuleMissingTag,           // DL3006
+		RuleLatestTag,            // DL3007
+		RuleLargeBaseImage,       // DL3008
+		RuleUndeclaredArgInFrom,  // DL4009
+		RuleFinalStageLargeImage, // DL4010
 	}
 
 	for _, ruleID := range expectedRules {
@@ -81,6 +85,18 @@ func TestMissingTagRule(t *testing.T) {
 	}
 }
 
+func TestMissingTagRule_Fix(t *testing.T) {
+	content := "FROM ubuntu\nRUN echo hi\n"
+	result := fixContent(t, &MissingTagRule{}, content)
+
+	if result.Fixed != 0 || string(result.Source) != content {
+		t.Errorf("untagged image should not be fixed, got:\n%s", result.Source)
+	}
+	if len(result.Unfixable) != 1 || !strings.Contains(result.Unfixable[0].Error(), "choose a tag for image 'ubuntu'") {
+		t.Errorf("Unfixable = %v, want a request to choose a tag", result.Unfixable)
+	}
+}
+
 func TestLatestTagRule(t *testing.T) {
 	rule := &LatestTagRule{}
 
@@ -149,6 +165,88 @@ func TestLatestTagRule(t *testing.T) {
 	}
 }
 
+func TestLatestTagRule_Column(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		column int
+	}{
+		{"points at the tag", "FROM ubuntu:latest", 13},
+		{"after platform flag", "FROM --platform=linux/amd64 node:latest AS build", 34},
+		{"continued line falls back to 1", "FROM \\\n  ubuntu:latest", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.from + "\n")
+			if err != nil {
+				t.Fatalf("Failed to parse Dockerfile: %v", err)
+			}
+			findings := (&LatestTagRule{}).Check(df)
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d", len(findings))
+			}
+			if findings[0].Column != tt.column {
+				t.Errorf("Column = %d, want %d", findings[0].Column, tt.column)
+			}
+		})
+	}
+}
+
+func TestFinalStageLargeImageRule(t *testing.T) {
+	rule := &FinalStageLargeImageRule{}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedCount int
+		line          int
+	}{
+		{"single large stage", "FROM ubuntu:22.04\n", 1, 1},
+		{"single slim stage", "FROM python:3.12-slim\n", 0, 0},
+		{"large builder, small final", "FROM golang:1.22 AS build\nRUN go build -o /app .\nFROM alpine:3.19\nCOPY --from=build /app /app\n", 0, 0},
+		{"large final", "FROM alpine:3.19 AS assets\nFROM node:20\nCOPY --from=assets /a /a\n", 1, 2},
+		{"final built from large stage", "FROM ubuntu:22.04 AS base\nRUN apt-get update\nFROM base\n", 1, 1},
+		{"final built from small stage", "FROM golang:1.22 AS build\nFROM alpine:3.19 AS base\nFROM base\n", 0, 0},
+		{"registry prefix", "FROM docker.io/library/debian:12\n", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("Failed to parse Dockerfile: %v", err)
+			}
+			findings := rule.Check(df)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.expectedCount > 0 && (findings[0].Line != tt.line || findings[0].Severity != ast.SeverityError) {
+				t.Errorf("finding = %+v, want an error on line %d", findings[0], tt.line)
+			}
+		})
+	}
+}
+
+func TestFinalStageLargeImageRule_Configure(t *testing.T) {
+	rule := &FinalStageLargeImageRule{}
+	if err := rule.Configure(map[string]string{"large_images": "corp-base"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	df, err := parser.ParseString("FROM golang:1.22 AS build\nFROM registry.example.com/corp-base:2\n")
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+	if findings := rule.Check(df); len(findings) != 1 {
+		t.Errorf("expected 1 finding, got %d", len(findings))
+	}
+
+	if err := rule.Configure(map[string]string{"images": "x"}); err == nil {
+		t.Error("Configure() with an unknown option: error = nil")
+	}
+}
+
 func TestLargeBaseImageRule(t *testing.T) {
 	rule := &LargeBaseImageRule{}
 
@@ -235,6 +333,95 @@ func TestLargeBaseImageRule(t *testing.T) {
 	}
 }
 
+func TestLargeBaseImageRule_Configure(t *testing.T) {
+	rule := &LargeBaseImageRule{}
+	if err := rule.Configure(map[string]string{"large_images": "Ubuntu, corp-base"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		image         string
+		tag           string
+		expectedCount int
+	}{
+		{"listed built-in image", "ubuntu", "22.04", 1},
+		{"listed custom image", "registry.example.com/platform/corp-base", "1.4", 1},
+		{"listed image with slim tag", "corp-base", "1.4-slim", 0},
+		{"built-in image not listed", "python", "3.12", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.FromInstruction{LineNum: 1, Image: tt.image, Tag: tt.tag},
+				},
+			}
+			if got := len(rule.Check(dockerfile)); got != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, got)
+			}
+		})
+	}
+
+	for _, options := range []map[string]string{
+		{"large_image": "ubuntu"},
+		{"large_images": " , "},
+	} {
+		if err := (&LargeBaseImageRule{}).Configure(options); err == nil {
+			t.Errorf("Configure(%v): expected error", options)
+		}
+	}
+}
+
+func TestUndeclaredArgInFromRule(t *testing.T) {
+	rule := &UndeclaredArgInFromRule{}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedCount int
+	}{
+		{"no variables", "FROM golang:1.22-alpine", 0},
+		{"declared with braces", "ARG GO_VERSION=1.22\nFROM golang:${GO_VERSION}-alpine", 0},
+		{"declared without default", "ARG BASE\nFROM $BASE", 0},
+		{"undeclared with braces", "FROM golang:${GO_VERSION}-alpine", 1},
+		{"undeclared without braces", "FROM $BASE_IMAGE", 1},
+		{"undeclared with default value", "FROM golang:${GO_VERSION:-1.22}-alpine", 0},
+		{"predefined platform args", "FROM --platform=$BUILDPLATFORM golang:1.22 AS build\nFROM --platform=${TARGETPLATFORM} alpine:3.18", 0},
+		{"two undeclared args", "FROM ${REGISTRY}/app:${VERSION}", 2},
+		{"same arg twice is reported once", "FROM ${IMAGE}:${IMAGE}", 1},
+		{"declared inside a stage is out of scope", "FROM alpine:3.18 AS base\nARG VERSION=1.0\nFROM app:${VERSION}", 1},
+		{"declared after FROM", "FROM app:${VERSION}\nARG VERSION=1.0", 1},
+		{"each FROM is checked", "FROM ${BASE}\nFROM ${BASE}", 2},
+		{"global ARG redeclared in a stage", "ARG VERSION=1.0\nFROM alpine:3.18\nARG VERSION\nFROM app:${VERSION}", 0},
+		{"declared between stages is out of scope", "ARG BASE=alpine\nFROM ${BASE}:3.18\nARG TAG=1.0\nFROM app:${TAG}", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countFindings(t, rule, tt.content); got != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, got)
+			}
+		})
+	}
+
+	t.Run("message names the argument", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Instructions: []ast.Instruction{
+				&ast.FromInstruction{LineNum: 3, RawText: "FROM golang:${GO_VERSION}", Image: "golang", Tag: "${GO_VERSION}"},
+			},
+		}
+		findings := rule.Check(dockerfile)
+		if len(findings) != 1 || findings[0].Line != 3 || !strings.Contains(findings[0].Message, "'GO_VERSION'") {
+			t.Errorf("expected one finding on line 3 naming GO_VERSION, got %+v", findings)
+		}
+		if len(findings) == 1 && findings[0].Severity != ast.SeverityError {
+			t.Errorf("Severity = %s, want error: the variable expands to an empty string", findings[0].Severity)
+		}
+	})
+}
+
 func TestExtractBaseImageName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -257,6 +444,33 @@ func TestExtractBaseImageName(t *testing.T) {
 	}
 }
 
+func TestSplitImageRef(t *testing.T) {
+	tests := []struct {
+		input  string
+		image  string
+		tag    string
+		digest string
+	}{
+		{"nginx", "nginx", "", ""},
+		{"nginx:1.25", "nginx", "1.25", ""},
+		{"ghcr.io/org/tool", "ghcr.io/org/tool", "", ""},
+		{"localhost:5000/app", "localhost:5000/app", "", ""},
+		{"localhost:5000/app:v2", "localhost:5000/app", "v2", ""},
+		{"alpine@sha256:abc", "alpine", "", "sha256:abc"},
+		{"alpine:3.18@sha256:abc", "alpine", "3.18", "sha256:abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			image, tag, digest := splitImageRef(tt.input)
+			if image != tt.image || tag != tt.tag || digest != tt.digest {
+				t.Errorf("splitImageRef(%q) = %q, %q, %q, want %q, %q, %q",
+					tt.input, image, tag, digest, tt.image, tt.tag, tt.digest)
+			}
+		})
+	}
+}
+
 func TestIsSlimVariant(t *testing.T) {
 	tests := []struct {
 		tag      string
@@ -3,7 +3,12 @@ package rules
 
 import (
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/devblac/docker-lint/internal/ast"
 )
@@ -11,29 +16,64 @@ import (
 // wildcardChars contains characters that indicate wildcard patterns
 var wildcardChars = []string{"*", "?", "["}
 
+// validPortProtocols contains the protocols accepted by EXPOSE
+var validPortProtocols = map[string]bool{"tcp": true, "udp": true, "sctp": true}
+
+// knownSignals contains the signal names accepted by STOPSIGNAL, without the
+// SIG prefix. Real-time signals are matched by realtimeSignalPattern.
+var knownSignals = map[string]bool{
+	"ABRT": true, "ALRM": true, "BUS": true, "CHLD": true, "CLD": true,
+	"CONT": true, "FPE": true, "HUP": true, "ILL": true, "INT": true,
+	"IO": true, "IOT": true, "KILL": true, "PIPE": true, "POLL": true,
+	"PROF": true, "PWR": true, "QUIT": true, "SEGV": true, "STKFLT": true,
+	"STOP": true, "SYS": true, "TERM": true, "TRAP": true, "TSTP": true,
+	"TTIN": true, "TTOU": true, "URG": true, "USR1": true, "USR2": true,
+	"VTALRM": true, "WINCH": true, "XCPU": true, "XFSZ": true,
+}
+
+// realtimeSignalPattern matches real-time signal names such as RTMIN+3
+var realtimeSignalPattern = regexp.MustCompile(`^RT(?:MIN|MAX)(?:[+-]\d{1,2})?$`)
+
 // MultipleCMDRule checks for multiple CMD instructions in a Dockerfile (DL3001).
 type MultipleCMDRule struct{}
 
 func (r *MultipleCMDRule) ID() string             { return RuleMultipleCMD }
 func (r *MultipleCMDRule) Name() string           { return "Multiple CMD instructions" }
 func (r *MultipleCMDRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *MultipleCMDRule) Category() string       { return CategoryCorrectness }
 
 func (r *MultipleCMDRule) Description() string {
 	return "Only the last CMD instruction takes effect; multiple CMD instructions are likely a mistake"
 }
 
+func (r *MultipleCMDRule) LongDescription() string {
+	return "A stage can only have one CMD. When it contains several, Docker silently uses the last one and ignores the others, so the earlier ones are dead code that usually points to a misunderstanding of how the container starts.\n\n" +
+		"To start several processes, run a script or a process supervisor as the single CMD."
+}
+
+func (r *MultipleCMDRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"CMD [\"./migrate\"]\n" +
+		"CMD [\"./server\"]"
+}
+
+func (r *MultipleCMDRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"CMD [\"./server\"]"
+}
+
+func (r *MultipleCMDRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#cmd",
+	}
+}
+
 func (r *MultipleCMDRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
 	// Check each stage separately
 	for _, stage := range dockerfile.Stages {
-		var cmdInstrs []*ast.CmdInstruction
-
-		for _, instr := range stage.Instructions {
-			if cmd, ok := instr.(*ast.CmdInstruction); ok {
-				cmdInstrs = append(cmdInstrs, cmd)
-			}
-		}
+		cmdInstrs := ast.FindInstructionsByStage[*ast.CmdInstruction](stage)
 
 		// If more than one CMD, report all but the last
 		if len(cmdInstrs) > 1 {
@@ -45,6 +85,8 @@ func (r *MultipleCMDRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 					Column:     1,
 					Message:    "Multiple CMD instructions found; only the last one will take effect",
 					Suggestion: "Remove duplicate CMD instructions and keep only the final one",
+					StageIndex: stage.Index,
+					StageName:  stage.Name,
 				})
 			}
 		}
@@ -59,23 +101,41 @@ type MultipleEntrypointRule struct{}
 func (r *MultipleEntrypointRule) ID() string             { return RuleMultipleEntrypoint }
 func (r *MultipleEntrypointRule) Name() string           { return "Multiple ENTRYPOINT instructions" }
 func (r *MultipleEntrypointRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *MultipleEntrypointRule) Category() string       { return CategoryCorrectness }
 
 func (r *MultipleEntrypointRule) Description() string {
 	return "Only the last ENTRYPOINT instruction takes effect; multiple ENTRYPOINT instructions are likely a mistake"
 }
 
+func (r *MultipleEntrypointRule) LongDescription() string {
+	return "A stage can only have one ENTRYPOINT. When it contains several, Docker silently uses the last one and ignores the others.\n\n" +
+		"Keep a single ENTRYPOINT and pass default arguments to it with CMD."
+}
+
+func (r *MultipleEntrypointRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"ENTRYPOINT [\"./setup\"]\n" +
+		"ENTRYPOINT [\"./server\"]"
+}
+
+func (r *MultipleEntrypointRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"ENTRYPOINT [\"./server\"]\n" +
+		"CMD [\"--port\", \"8080\"]"
+}
+
+func (r *MultipleEntrypointRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#entrypoint",
+	}
+}
+
 func (r *MultipleEntrypointRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
 	// Check each stage separately
 	for _, stage := range dockerfile.Stages {
-		var entrypointInstrs []*ast.EntrypointInstruction
-
-		for _, instr := range stage.Instructions {
-			if ep, ok := instr.(*ast.EntrypointInstruction); ok {
-				entrypointInstrs = append(entrypointInstrs, ep)
-			}
-		}
+		entrypointInstrs := ast.FindInstructionsByStage[*ast.EntrypointInstruction](stage)
 
 		// If more than one ENTRYPOINT, report all but the last
 		if len(entrypointInstrs) > 1 {
@@ -101,11 +161,34 @@ type RelativeWorkdirRule struct{}
 func (r *RelativeWorkdirRule) ID() string             { return RuleRelativeWorkdir }
 func (r *RelativeWorkdirRule) Name() string           { return "WORKDIR with relative path" }
 func (r *RelativeWorkdirRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *RelativeWorkdirRule) Category() string       { return CategoryBestPractice }
 
 func (r *RelativeWorkdirRule) Description() string {
 	return "Use absolute paths in WORKDIR to avoid confusion about the current directory"
 }
 
+func (r *RelativeWorkdirRule) LongDescription() string {
+	return "A relative WORKDIR is resolved against the previous WORKDIR, which may have been set by the base image. The resulting directory then depends on instructions that are not visible in the Dockerfile.\n\n" +
+		"Absolute paths make the working directory obvious to readers and stable across base image updates. This rule can be fixed automatically with --fix."
+}
+
+func (r *RelativeWorkdirRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"WORKDIR app"
+}
+
+func (r *RelativeWorkdirRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"WORKDIR /app"
+}
+
+func (r *RelativeWorkdirRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#workdir",
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
 func (r *RelativeWorkdirRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
@@ -133,6 +216,20 @@ func (r *RelativeWorkdirRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	return findings
 }
 
+// Fix makes a relative WORKDIR path absolute by anchoring it at the root.
+func (r *RelativeWorkdirRule) Fix(finding ast.Finding, dockerfile *ast.Dockerfile) ([]Fix, error) {
+	workdir, ok := instructionAt(dockerfile, finding.Line).(*ast.WorkdirInstruction)
+	if !ok || workdir.Path == "" || isAbsolutePath(workdir.Path) {
+		return nil, nil
+	}
+
+	return []Fix{{
+		Line:    workdir.Line(),
+		OldText: workdir.Path,
+		NewText: "/" + strings.TrimPrefix(workdir.Path, "./"),
+	}}, nil
+}
+
 // isAbsolutePath checks if a path is absolute or starts with a variable.
 func isAbsolutePath(path string) bool {
 	// Empty path is not absolute
@@ -158,95 +255,325 @@ func isAbsolutePath(path string) bool {
 	return false
 }
 
-// MissingHealthcheckRule checks for Dockerfiles without HEALTHCHECK instruction (DL5000).
-type MissingHealthcheckRule struct{}
+// RunCdRule checks for RUN instructions that change directory with cd (DL3004).
+type RunCdRule struct{}
 
-func (r *MissingHealthcheckRule) ID() string             { return RuleMissingHealthcheck }
-func (r *MissingHealthcheckRule) Name() string           { return "Missing HEALTHCHECK" }
-func (r *MissingHealthcheckRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *RunCdRule) ID() string             { return RuleRunCd }
+func (r *RunCdRule) Name() string           { return "RUN cd instead of WORKDIR" }
+func (r *RunCdRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *RunCdRule) Category() string       { return CategoryBestPractice }
 
-func (r *MissingHealthcheckRule) Description() string {
-	return "Add a HEALTHCHECK instruction to enable container health monitoring"
+func (r *RunCdRule) Description() string {
+	return "Use WORKDIR to change directories; cd in RUN does not persist to later instructions"
 }
 
-func (r *MissingHealthcheckRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+func (r *RunCdRule) LongDescription() string {
+	return "Every RUN instruction starts a new shell, so cd only affects the commands in the same RUN. Later instructions run in the WORKDIR again, which is easy to forget when the Dockerfile is edited.\n\n" +
+		"WORKDIR sets the directory for all following instructions and for the running container."
+}
+
+func (r *RunCdRule) BadExample() string {
+	return "FROM golang:1.22-alpine\n" +
+		"RUN cd /src && go build ./..."
+}
+
+func (r *RunCdRule) GoodExample() string {
+	return "FROM golang:1.22-alpine\n" +
+		"WORKDIR /src\n" +
+		"RUN go build ./..."
+}
+
+func (r *RunCdRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#workdir",
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *RunCdRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
-	// Check if any stage has a HEALTHCHECK
-	hasHealthcheck := false
 	for _, instr := range dockerfile.Instructions {
-		if _, ok := instr.(*ast.HealthcheckInstruction); ok {
-			hasHealthcheck = true
-			break
+		run, ok := instr.(*ast.RunInstruction)
+		if !ok {
+			continue
 		}
-	}
 
-	// If no HEALTHCHECK found, report at the last stage's FROM or last instruction
-	if !hasHealthcheck && len(dockerfile.Stages) > 0 {
-		// Report on the last stage (the one that produces the final image)
-		lastStage := dockerfile.Stages[len(dockerfile.Stages)-1]
-		line := 1
-		if lastStage.FromInstr != nil {
-			line = lastStage.FromInstr.Line()
-		}
-		if len(lastStage.Instructions) > 0 {
-			line = lastStage.Instructions[len(lastStage.Instructions)-1].Line()
-		}
+		// Track subshell nesting so intentional (cd x && ...) groups are skipped
+		depth := 0
+		for _, segment := range splitShellCommands(run.Command) {
+			inSubshell := depth > 0 || strings.HasPrefix(segment, "(")
+			depth += strings.Count(segment, "(") - strings.Count(segment, ")")
+			if inSubshell {
+				continue
+			}
 
-		findings = append(findings, ast.Finding{
-			RuleID:     r.ID(),
-			Severity:   r.Severity(),
-			Line:       line,
-			Column:     1,
-			Message:    "No HEALTHCHECK instruction found",
-			Suggestion: "Add 'HEALTHCHECK CMD <command>' to enable container health monitoring",
-		})
+			path, ok := cdTarget(segment)
+			if !ok {
+				continue
+			}
+
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       run.Line(),
+				Column:     1,
+				Message:    "RUN uses 'cd " + path + "' to change directory",
+				Suggestion: "Use 'WORKDIR " + path + "' instead of cd in RUN instructions",
+			})
+		}
 	}
 
 	return findings
 }
 
-// WildcardCopyRule checks for wildcard patterns in COPY/ADD sources (DL5001).
-type WildcardCopyRule struct{}
+// cdTarget returns the directory argument of a 'cd <path>' command.
+// It returns false for 'cd' without an argument and for 'cd -'.
+func cdTarget(segment string) (string, bool) {
+	fields := strings.Fields(segment)
+	if len(fields) < 2 || fields[0] != "cd" {
+		return "", false
+	}
+	if fields[1] == "-" {
+		return "", false
+	}
+	return fields[1], true
+}
 
-func (r *WildcardCopyRule) ID() string             { return RuleWildcardCopy }
-func (r *WildcardCopyRule) Name() string           { return "Wildcard in COPY/ADD source" }
-func (r *WildcardCopyRule) Severity() ast.Severity { return ast.SeverityInfo }
+// InvalidPortRule checks for EXPOSE instructions with invalid ports or protocols (DL3005).
+type InvalidPortRule struct{}
 
-func (r *WildcardCopyRule) Description() string {
-	return "Wildcard patterns in COPY/ADD may include unnecessary files, increasing build context size"
+func (r *InvalidPortRule) ID() string             { return RuleInvalidPort }
+func (r *InvalidPortRule) Name() string           { return "Invalid EXPOSE port" }
+func (r *InvalidPortRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *InvalidPortRule) Category() string       { return CategoryCorrectness }
+
+func (r *InvalidPortRule) Description() string {
+	return "EXPOSE ports must be numbers in the range 1-65535 with a tcp, udp or sctp protocol"
 }
 
-func (r *WildcardCopyRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+func (r *InvalidPortRule) LongDescription() string {
+	return "EXPOSE accepts a port number between 1 and 65535 or a range of them, optionally followed by /tcp, /udp or /sctp. Anything else fails the build or documents a port the container cannot listen on."
+}
+
+func (r *InvalidPortRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"EXPOSE 80800/http"
+}
+
+func (r *InvalidPortRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"EXPOSE 8080/tcp"
+}
+
+func (r *InvalidPortRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#expose",
+	}
+}
+
+func (r *InvalidPortRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
 	for _, instr := range dockerfile.Instructions {
-		switch v := instr.(type) {
-		case *ast.CopyInstruction:
-			// Skip COPY --from (multi-stage copies from other stages)
-			if v.From != "" {
+		expose, ok := instr.(*ast.ExposeInstruction)
+		if !ok {
+			continue
+		}
+
+		for _, port := range expose.Ports {
+			// Skip variables that are resolved at build time
+			if strings.Contains(port, "$") {
 				continue
 			}
-			if hasWildcard(v.Sources) {
+
+			number, protocol, hasProtocol := strings.Cut(port, "/")
+
+			if !isValidPortOrRange(number) {
 				findings = append(findings, ast.Finding{
 					RuleID:     r.ID(),
 					Severity:   r.Severity(),
-					Line:       v.Line(),
+					Line:       expose.Line(),
 					Column:     1,
-					Message:    "COPY uses wildcard pattern which may include unnecessary files",
-					Suggestion: "Consider using explicit file paths or a .dockerignore file to exclude unnecessary files",
+					Message:    "EXPOSE port '" + port + "' is not a valid port number",
+					Suggestion: "Use a port number between 1 and 65535, e.g. 'EXPOSE 8080'",
 				})
+				continue
 			}
 
-		case *ast.AddInstruction:
-			if hasWildcard(v.Sources) {
+			if hasProtocol && !validPortProtocols[strings.ToLower(protocol)] {
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   ast.SeverityWarning,
+					Line:       expose.Line(),
+					Column:     1,
+					Message:    "EXPOSE port '" + port + "' uses unknown protocol '" + protocol + "'",
+					Suggestion: "Use 'tcp' or 'udp' as the protocol, e.g. '" + number + "/tcp'",
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// isValidPortOrRange checks if a string is a port number or a port range
+// (e.g. 8000-8010) within 1-65535.
+func isValidPortOrRange(s string) bool {
+	start, end, isRange := strings.Cut(s, "-")
+	if !isRange {
+		return isValidPort(s)
+	}
+	if !isValidPort(start) || !isValidPort(end) {
+		return false
+	}
+	startNum, _ := strconv.Atoi(start)
+	endNum, _ := strconv.Atoi(end)
+	return startNum <= endNum
+}
+
+// isValidPort checks if a string is a port number within 1-65535.
+func isValidPort(s string) bool {
+	n, err := strconv.Atoi(s)
+	if err != nil || strings.HasPrefix(s, "+") {
+		return false
+	}
+	return n >= 1 && n <= 65535
+}
+
+// InvalidStopSignalRule checks for STOPSIGNAL instructions with an unknown
+// signal (DL3034).
+type InvalidStopSignalRule struct{}
+
+func (r *InvalidStopSignalRule) ID() string             { return RuleInvalidStopSignal }
+func (r *InvalidStopSignalRule) Name() string           { return "Invalid STOPSIGNAL" }
+func (r *InvalidStopSignalRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *InvalidStopSignalRule) Category() string       { return CategoryCorrectness }
+
+func (r *InvalidStopSignalRule) Description() string {
+	return "STOPSIGNAL must be a signal number between 1 and 64 or a signal name such as SIGTERM"
+}
+
+func (r *InvalidStopSignalRule) LongDescription() string {
+	return "STOPSIGNAL sets the signal sent to the container's main process when it is stopped. It accepts a signal number between 1 and 64 or a signal name, with or without the SIG prefix, such as SIGTERM, TERM or SIGRTMIN+3. An unknown signal fails the build or makes docker stop fall back to killing the container after the timeout."
+}
+
+func (r *InvalidStopSignalRule) BadExample() string {
+	return "FROM nginx:1.25\n" +
+		"STOPSIGNAL SIGSHUTDOWN"
+}
+
+func (r *InvalidStopSignalRule) GoodExample() string {
+	return "FROM nginx:1.25\n" +
+		"STOPSIGNAL SIGQUIT"
+}
+
+func (r *InvalidStopSignalRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#stopsignal",
+	}
+}
+
+func (r *InvalidStopSignalRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		stop, ok := instr.(*ast.StopsignalInstruction)
+		if !ok {
+			continue
+		}
+
+		// Skip variables that are resolved at build time
+		if strings.Contains(stop.Signal, "$") || isValidSignal(stop.Signal) {
+			continue
+		}
+
+		findings = append(findings, ast.Finding{
+			RuleID:     r.ID(),
+			Severity:   r.Severity(),
+			Line:       stop.Line(),
+			Column:     1,
+			Message:    "STOPSIGNAL '" + stop.Signal + "' is not a known signal",
+			Suggestion: "Use a signal name such as SIGTERM or SIGQUIT, or a signal number between 1 and 64",
+		})
+	}
+
+	return findings
+}
+
+// isValidSignal checks if a string is a signal number within 1-64 or a known
+// signal name, with or without the SIG prefix.
+func isValidSignal(s string) bool {
+	if n, err := strconv.Atoi(s); err == nil && !strings.HasPrefix(s, "+") {
+		return n >= 1 && n <= 64
+	}
+	name := strings.TrimPrefix(strings.ToUpper(s), "SIG")
+	return knownSignals[name] || realtimeSignalPattern.MatchString(name)
+}
+
+// PipefailRule checks for RUN instructions that pipe commands without pipefail (DL3028).
+type PipefailRule struct{}
+
+func (r *PipefailRule) ID() string             { return RulePipefail }
+func (r *PipefailRule) Name() string           { return "RUN with pipe but no pipefail" }
+func (r *PipefailRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *PipefailRule) Category() string       { return CategoryCorrectness }
+
+func (r *PipefailRule) Description() string {
+	return "Set the SHELL option -o pipefail before RUN with a pipe so failures of earlier commands fail the build"
+}
+
+func (r *PipefailRule) LongDescription() string {
+	return "The exit status of a pipeline is the exit status of its last command. If an earlier command such as curl fails, the RUN still succeeds and the build continues with incomplete output.\n\n" +
+		"Set SHELL [\"/bin/bash\", \"-o\", \"pipefail\", \"-c\"] before the RUN so a failure anywhere in the pipeline fails the build."
+}
+
+func (r *PipefailRule) BadExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN curl -fsSL https://example.com/install.sh | bash"
+}
+
+func (r *PipefailRule) GoodExample() string {
+	return "FROM debian:12-slim\n" +
+		"SHELL [\"/bin/bash\", \"-o\", \"pipefail\", \"-c\"]\n" +
+		"RUN curl -fsSL https://example.com/install.sh | bash"
+}
+
+func (r *PipefailRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#shell",
+	}
+}
+
+func (r *PipefailRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Check each stage separately; SHELL does not carry over to later stages
+	for _, stage := range dockerfile.Stages {
+		pipefail := false
+		posixShell := true
+
+		for _, instr := range stage.Instructions {
+			switch v := instr.(type) {
+			case *ast.ShellInstruction:
+				pipefail = hasPipefailOption(v.Shell)
+				posixShell = len(v.Shell) == 0 || !isPowerShell(v.Shell[0])
+
+			case *ast.RunInstruction:
+				if !v.Shell || pipefail || !posixShell || !hasUnquotedPipe(v.Command) {
+					continue
+				}
+				if strings.Contains(v.Command, "pipefail") {
+					// e.g. RUN set -o pipefail && curl ... | tar ...
+					continue
+				}
 				findings = append(findings, ast.Finding{
 					RuleID:     r.ID(),
 					Severity:   r.Severity(),
 					Line:       v.Line(),
 					Column:     1,
-					Message:    "ADD uses wildcard pattern which may include unnecessary files",
-					Suggestion: "Consider using explicit file paths or a .dockerignore file to exclude unnecessary files",
+					Message:    "RUN uses a pipe without pipefail; a failure before the last command is ignored",
+					Suggestion: "Add 'SHELL [\"/bin/bash\", \"-o\", \"pipefail\", \"-c\"]' before this RUN instruction",
 				})
 			}
 		}
@@ -255,25 +582,1338 @@ func (r *WildcardCopyRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	return findings
 }
 
-// hasWildcard checks if any source path contains wildcard characters.
-func hasWildcard(sources []string) bool {
-	for _, source := range sources {
-		// Get just the filename part for checking
-		base := filepath.Base(source)
-		for _, wc := range wildcardChars {
-			if strings.Contains(base, wc) {
-				return true
-			}
+// hasPipefailOption reports whether a SHELL command line enables pipefail,
+// e.g. ["/bin/bash", "-o", "pipefail", "-c"] or ["/bin/bash", "-euo", "pipefail", "-c"].
+func hasPipefailOption(shell []string) bool {
+	for i := 1; i < len(shell); i++ {
+		prev := shell[i-1]
+		if shell[i] == "pipefail" && strings.HasPrefix(prev, "-") && strings.HasSuffix(prev, "o") {
+			return true
+		}
+	}
+	return false
+}
+
+// isPowerShell reports whether a SHELL executable is PowerShell, where pipes
+// have different semantics.
+func isPowerShell(executable string) bool {
+	name := strings.ToLower(executable)
+	return strings.Contains(name, "powershell") || strings.Contains(name, "pwsh")
+}
+
+// hasUnquotedPipe reports whether a shell command contains a pipe operator
+// outside of quotes. The || operator is not a pipe.
+func hasUnquotedPipe(cmd string) bool {
+	for _, pipeline := range shellPipelines(cmd) {
+		if len(pipeline) > 1 {
+			return true
 		}
 	}
 	return false
 }
 
+// shellPipelines splits a shell command line into its pipelines, breaking on
+// '&&', '||' and ';' outside of quotes, and each pipeline into its commands,
+// breaking on '|'. Quoted content is kept as-is, and a backslash outside
+// single quotes escapes the next character. Commands are trimmed but may be
+// empty, as in a trailing pipe.
+func shellPipelines(cmd string) [][]string {
+	var pipelines [][]string
+	var pipeline []string
+	var current strings.Builder
+	inDoubleQuote := false
+	inSingleQuote := false
+
+	endCommand := func() {
+		pipeline = append(pipeline, strings.TrimSpace(current.String()))
+		current.Reset()
+	}
+	endPipeline := func() {
+		endCommand()
+		if len(pipeline) > 1 || pipeline[0] != "" {
+			pipelines = append(pipelines, pipeline)
+		}
+		pipeline = nil
+	}
+
+	for i := 0; i < len(cmd); i++ {
+		ch := cmd[i]
+
+		switch {
+		case ch == '\\' && !inSingleQuote:
+			// Keep the escaped character with its backslash
+			current.WriteByte(ch)
+			if i+1 < len(cmd) {
+				i++
+				current.WriteByte(cmd[i])
+			}
+			continue
+		case ch == '"' && !inSingleQuote:
+			inDoubleQuote = !inDoubleQuote
+		case ch == '\'' && !inDoubleQuote:
+			inSingleQuote = !inSingleQuote
+		case inDoubleQuote || inSingleQuote:
+			// Quoted content is not interpreted
+		case ch == ';':
+			endPipeline()
+			continue
+		case ch == '&' && i+1 < len(cmd) && cmd[i+1] == '&':
+			endPipeline()
+			i++
+			continue
+		case ch == '|':
+			if i+1 < len(cmd) && cmd[i+1] == '|' {
+				endPipeline()
+				i++
+			} else {
+				endCommand()
+			}
+			continue
+		}
+
+		current.WriteByte(ch)
+	}
+	endPipeline()
+
+	return pipelines
+}
+
+// DuplicateEnvRule checks for ENV keys set more than once within a stage (DL3029).
+type DuplicateEnvRule struct{}
+
+func (r *DuplicateEnvRule) ID() string             { return RuleDuplicateEnv }
+func (r *DuplicateEnvRule) Name() string           { return "Duplicate ENV key" }
+func (r *DuplicateEnvRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *DuplicateEnvRule) Category() string       { return CategoryCorrectness }
+
+func (r *DuplicateEnvRule) Description() string {
+	return "Setting the same ENV key twice in a stage is usually a mistake; only the last value takes effect"
+}
+
+func (r *DuplicateEnvRule) LongDescription() string {
+	return "When an ENV key is set more than once in a stage, only the last value takes effect for later instructions and the running container. The earlier value is usually a mistake.\n\n" +
+		"ENV instructions that extend an earlier value, such as PATH=/opt/bin:$PATH, are not reported."
+}
+
+func (r *DuplicateEnvRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"ENV APP_ENV=development\n" +
+		"ENV APP_ENV=production"
+}
+
+func (r *DuplicateEnvRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"ENV APP_ENV=production"
+}
+
+func (r *DuplicateEnvRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#env",
+	}
+}
+
+func (r *DuplicateEnvRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Check each stage separately; ENV does not cross FROM boundaries
+	for _, stage := range dockerfile.Stages {
+		type envAssignment struct {
+			ast.EnvPair
+			line int
+		}
+		var envs []envAssignment
+		for _, instr := range stage.Instructions {
+			if env, ok := instr.(*ast.EnvInstruction); ok {
+				for _, pair := range env.AllPairs() {
+					if pair.Key != "" {
+						envs = append(envs, envAssignment{pair, env.Line()})
+					}
+				}
+			}
+		}
+
+		// Report each ENV key that is set again later, at the next redefinition
+		for i, env := range envs {
+			for _, later := range envs[i+1:] {
+				if later.Key != env.Key {
+					continue
+				}
+				// ENV PATH=/opt/bin:$PATH builds on the earlier value
+				if referencesVariable(later.Value, env.Key) {
+					break
+				}
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       env.line,
+					Column:     1,
+					Message:    "ENV key '" + env.Key + "' is set again on line " + intToString(later.line) + "; this value is overwritten",
+					Suggestion: "Remove the duplicate ENV instruction or use a different key",
+				})
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+// referencesVariable reports whether value references $name or ${name}.
+func referencesVariable(value, name string) bool {
+	if strings.Contains(value, "${"+name+"}") || strings.Contains(value, "${"+name+":") {
+		return true
+	}
+	for idx := strings.Index(value, "$"+name); idx != -1; {
+		end := idx + 1 + len(name)
+		if end == len(value) || !isVariableChar(value[end]) {
+			return true
+		}
+		next := strings.Index(value[end:], "$"+name)
+		if next == -1 {
+			break
+		}
+		idx = end + next
+	}
+	return false
+}
+
+// isVariableChar reports whether c can appear in a shell variable name.
+func isVariableChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// UnusedArgRule checks for ARG instructions whose build argument is never
+// referenced (DL3021).
+type UnusedArgRule struct{}
+
+func (r *UnusedArgRule) ID() string             { return RuleUnusedArg }
+func (r *UnusedArgRule) Name() string           { return "Unused ARG" }
+func (r *UnusedArgRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *UnusedArgRule) Category() string       { return CategoryBestPractice }
+
+func (r *UnusedArgRule) Description() string {
+	return "A build argument that is declared but never referenced is dead code that confuses readers"
+}
+
+func (r *UnusedArgRule) LongDescription() string {
+	return "An ARG declared inside a build stage is in scope for the rest of that stage. If no later instruction of the stage references it as $NAME or ${NAME}, the declaration has no effect and readers are left wondering which --build-arg matters.\n\n" +
+		"An ARG declared before the first FROM is only in scope for FROM instructions and later global ARG defaults, unless a stage redeclares it with ARG NAME; each of these counts as a use.\n\n" +
+		"Build arguments are also exported to the environment of RUN instructions, so a script can read one without the Dockerfile mentioning it. Reference the argument explicitly, or ignore the rule for that line. The proxy arguments, such as HTTP_PROXY, are never reported."
+}
+
+func (r *UnusedArgRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"ARG APP_VERSION=1.0.0\n" +
+		"RUN echo building"
+}
+
+func (r *UnusedArgRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"ARG APP_VERSION=1.0.0\n" +
+		"RUN echo building ${APP_VERSION}"
+}
+
+func (r *UnusedArgRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#arg",
+		"https://docs.docker.com/reference/dockerfile/#understand-how-arg-and-from-interact",
+	}
+}
+
+func (r *UnusedArgRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Global ARGs, before the first FROM, are used by FROM instructions, later
+	// global ARG defaults and redeclarations inside a stage
+	var global []*ast.ArgInstruction
+	used := make(map[string]bool)
+	seenFrom := false
+	for _, instr := range dockerfile.Instructions {
+		arg, isArg := instr.(*ast.ArgInstruction)
+		switch {
+		case isArg && !seenFrom:
+			markReferences(used, arg.Default)
+			global = append(global, arg)
+		case isArg:
+			used[arg.Name] = true
+		case instr.Type() == ast.InstrFROM:
+			seenFrom = true
+			markReferences(used, instr.Raw())
+		}
+	}
+	for _, arg := range global {
+		if !used[arg.Name] && !isProxyArg(arg.Name) {
+			findings = append(findings, r.finding(arg, "is declared before the first FROM but not used by any FROM or stage"))
+		}
+	}
+
+	// A stage ARG is in scope for the rest of its stage
+	for _, stage := range dockerfile.Stages {
+		for i, instr := range stage.Instructions {
+			arg, ok := instr.(*ast.ArgInstruction)
+			if !ok || arg.Name == "" || isProxyArg(arg.Name) {
+				continue
+			}
+			referenced := make(map[string]bool)
+			for _, later := range stage.Instructions[i+1:] {
+				markReferences(referenced, later.Raw())
+			}
+			if !referenced[arg.Name] {
+				findings = append(findings, r.finding(arg, "is never referenced in its stage"))
+			}
+		}
+	}
+
+	return findings
+}
+
+func (r *UnusedArgRule) finding(arg *ast.ArgInstruction, reason string) ast.Finding {
+	return ast.Finding{
+		RuleID:     r.ID(),
+		Severity:   r.Severity(),
+		Line:       arg.Line(),
+		Column:     1,
+		Message:    "Build argument '" + arg.Name + "' " + reason,
+		Suggestion: "Remove the unused ARG, or reference it as ${" + arg.Name + "}",
+	}
+}
+
+// markReferences adds the names of the variables referenced in text to names.
+func markReferences(names map[string]bool, text string) {
+	for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+		names[match[1]+match[3]] = true
+	}
+}
+
+// isProxyArg reports whether name is one of the predefined proxy arguments,
+// which tools read from the environment without a reference.
+func isProxyArg(name string) bool {
+	return predefinedBuildArgs[name] && strings.HasSuffix(strings.ToUpper(name), "_PROXY")
+}
+
+// DuplicateLabelRule checks for LABEL keys set more than once within a stage (DL3018).
+type DuplicateLabelRule struct{}
+
+func (r *DuplicateLabelRule) ID() string             { return RuleDuplicateLabel }
+func (r *DuplicateLabelRule) Name() string           { return "Duplicate LABEL key" }
+func (r *DuplicateLabelRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *DuplicateLabelRule) Category() string       { return CategoryCorrectness }
+
+func (r *DuplicateLabelRule) Description() string {
+	return "Setting the same LABEL key twice in an instruction or stage is usually a mistake; only the last value takes effect"
+}
+
+func (r *DuplicateLabelRule) LongDescription() string {
+	return "When a LABEL key is set more than once in a stage, only the last value ends up in the image. The earlier value is usually a leftover from a copy and paste or a merge.\n\n" +
+		"Set each key once, preferably in a single LABEL instruction."
+}
+
+func (r *DuplicateLabelRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"LABEL version=1.0 maintainer=team@example.com\n" +
+		"LABEL version=1.1"
+}
+
+func (r *DuplicateLabelRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"LABEL version=1.1 maintainer=team@example.com"
+}
+
+func (r *DuplicateLabelRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#label",
+	}
+}
+
+func (r *DuplicateLabelRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Check each stage separately; a new FROM starts a new set of labels
+	for _, stage := range dockerfile.Stages {
+		firstLine := make(map[string]int)
+		for _, instr := range stage.Instructions {
+			label, ok := instr.(*ast.LabelInstruction)
+			if !ok {
+				continue
+			}
+
+			// Report the second and later occurrences of each key
+			for _, key := range labelKeys(label) {
+				line, seen := firstLine[key]
+				if !seen {
+					firstLine[key] = label.Line()
+					continue
+				}
+
+				message := "LABEL key '" + key + "' is already set on line " + intToString(line)
+				if line == label.Line() {
+					message = "LABEL key '" + key + "' is set more than once in this instruction"
+				}
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       label.Line(),
+					Column:     1,
+					Message:    message + "; only the last value takes effect",
+					Suggestion: "Consolidate the labels into a single LABEL instruction with each key set once",
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// labelKeys returns the keys of a LABEL instruction in declaration order.
+// Instructions built without Keys fall back to the sorted Labels keys.
+func labelKeys(label *ast.LabelInstruction) []string {
+	if len(label.Keys) > 0 {
+		return label.Keys
+	}
+	keys := make([]string, 0, len(label.Labels))
+	for key := range label.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DeprecatedMaintainerRule checks for the deprecated MAINTAINER instruction (DL3030).
+type DeprecatedMaintainerRule struct{}
+
+func (r *DeprecatedMaintainerRule) ID() string             { return RuleDeprecatedMaint }
+func (r *DeprecatedMaintainerRule) Name() string           { return "Deprecated MAINTAINER" }
+func (r *DeprecatedMaintainerRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *DeprecatedMaintainerRule) Category() string       { return CategoryBestPractice }
+
+func (r *DeprecatedMaintainerRule) Description() string {
+	return "MAINTAINER is deprecated; use LABEL maintainer=... instead"
+}
+
+func (r *DeprecatedMaintainerRule) LongDescription() string {
+	return "MAINTAINER is deprecated. A LABEL sets the same information as image metadata that tools can query."
+}
+
+func (r *DeprecatedMaintainerRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"MAINTAINER team@example.com"
+}
+
+func (r *DeprecatedMaintainerRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"LABEL maintainer=team@example.com"
+}
+
+func (r *DeprecatedMaintainerRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#maintainer-deprecated",
+	}
+}
+
+func (r *DeprecatedMaintainerRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		maintainer, ok := instr.(*ast.MaintainerInstruction)
+		if !ok {
+			continue
+		}
+
+		findings = append(findings, ast.Finding{
+			RuleID:     r.ID(),
+			Severity:   r.Severity(),
+			Line:       maintainer.Line(),
+			Column:     1,
+			Message:    "MAINTAINER instruction is deprecated",
+			Suggestion: "Use 'LABEL maintainer=\"" + maintainer.Maintainer + "\"' instead",
+		})
+	}
+
+	return findings
+}
+
+// MissingHealthcheckRule checks for Dockerfiles without HEALTHCHECK instruction (DL5000).
+type MissingHealthcheckRule struct{}
+
+func (r *MissingHealthcheckRule) ID() string             { return RuleMissingHealthcheck }
+func (r *MissingHealthcheckRule) Name() string           { return "Missing HEALTHCHECK" }
+func (r *MissingHealthcheckRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *MissingHealthcheckRule) Category() string       { return CategoryBestPractice }
+
+func (r *MissingHealthcheckRule) Description() string {
+	return "Add a HEALTHCHECK instruction to enable container health monitoring"
+}
+
+func (r *MissingHealthcheckRule) LongDescription() string {
+	return "A HEALTHCHECK lets Docker and orchestrators tell a running container that is stuck from a healthy one, and restart or stop routing traffic to it."
+}
+
+func (r *MissingHealthcheckRule) BadExample() string {
+	return "FROM nginx:1.25-alpine\n" +
+		"COPY site /usr/share/nginx/html"
+}
+
+func (r *MissingHealthcheckRule) GoodExample() string {
+	return "FROM nginx:1.25-alpine\n" +
+		"COPY site /usr/share/nginx/html\n" +
+		"HEALTHCHECK CMD [\"wget\", \"-qO-\", \"http://localhost/\"]"
+}
+
+func (r *MissingHealthcheckRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#healthcheck",
+	}
+}
+
+func (r *MissingHealthcheckRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Check if any stage has a HEALTHCHECK
+	hasHealthcheck := false
+	for _, instr := range dockerfile.Instructions {
+		if _, ok := instr.(*ast.HealthcheckInstruction); ok {
+			hasHealthcheck = true
+			break
+		}
+	}
+
+	// If no HEALTHCHECK found, report at the last stage's FROM or last instruction
+	if !hasHealthcheck && len(dockerfile.Stages) > 0 {
+		// Report on the last stage (the one that produces the final image)
+		lastStage := dockerfile.Stages[len(dockerfile.Stages)-1]
+		line := 1
+		if lastStage.FromInstr != nil {
+			line = lastStage.FromInstr.Line()
+		}
+		if len(lastStage.Instructions) > 0 {
+			line = lastStage.Instructions[len(lastStage.Instructions)-1].Line()
+		}
+
+		findings = append(findings, ast.Finding{
+			RuleID:     r.ID(),
+			Severity:   r.Severity(),
+			Line:       line,
+			Column:     1,
+			Message:    "No HEALTHCHECK instruction found",
+			Suggestion: "Add 'HEALTHCHECK CMD <command>' to enable container health monitoring",
+		})
+	}
+
+	return findings
+}
+
+// ExecFormRule checks for CMD and ENTRYPOINT in shell form (DL3025).
+type ExecFormRule struct{}
+
+func (r *ExecFormRule) ID() string             { return RuleExecForm }
+func (r *ExecFormRule) Name() string           { return "CMD/ENTRYPOINT in shell form" }
+func (r *ExecFormRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *ExecFormRule) Category() string       { return CategoryBestPractice }
+
+func (r *ExecFormRule) Description() string {
+	return "Use the exec form for CMD and ENTRYPOINT so the process receives signals"
+}
+
+func (r *ExecFormRule) LongDescription() string {
+	return "In shell form, CMD and ENTRYPOINT run through /bin/sh -c, so the shell is PID 1 and the application is its child. Most shells do not forward SIGTERM, so docker stop waits for the timeout and then kills the container without a clean shutdown.\n\n" +
+		"Use the exec form, a JSON array of the program and its arguments, so the program runs as PID 1 and receives signals directly. Commands that use shell features such as pipes, redirects or variable expansion are not reported, since they need a shell."
+}
+
+func (r *ExecFormRule) BadExample() string {
+	return "FROM node:20-alpine\n" +
+		"CMD node server.js"
+}
+
+func (r *ExecFormRule) GoodExample() string {
+	return "FROM node:20-alpine\n" +
+		"CMD [\"node\", \"server.js\"]"
+}
+
+func (r *ExecFormRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#shell-and-exec-form",
+		"https://docs.docker.com/reference/dockerfile/#cmd",
+		"https://docs.docker.com/reference/dockerfile/#entrypoint",
+	}
+}
+
+func (r *ExecFormRule) DocURL() string {
+	return "https://github.com/hadolint/hadolint/wiki/DL3025"
+}
+
+func (r *ExecFormRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		var keyword string
+		var command []string
+		switch v := instr.(type) {
+		case *ast.CmdInstruction:
+			if !v.Shell {
+				continue
+			}
+			keyword, command = "CMD", v.Command
+		case *ast.EntrypointInstruction:
+			if !v.Shell {
+				continue
+			}
+			keyword, command = "ENTRYPOINT", v.Command
+		default:
+			continue
+		}
+
+		text := strings.TrimSpace(strings.Join(command, " "))
+		// Commands that need a shell are left alone
+		if text == "" || strings.ContainsAny(text, shellOperators) {
+			continue
+		}
+
+		// Quoted arguments cannot be split into an array without a shell parser
+		suggestion := "Use the exec form: " + keyword + " " + execForm(strings.Fields(text))
+		if strings.ContainsAny(text, "\"'\\") {
+			suggestion = "Use the exec form, a JSON array of the program and its arguments, e.g. " + keyword + " [\"executable\", \"arg\"]"
+		}
+
+		findings = append(findings, ast.Finding{
+			RuleID:     r.ID(),
+			Severity:   r.Severity(),
+			Line:       instr.Line(),
+			Column:     1,
+			Message:    keyword + " '" + text + "' is in shell form; the process runs under /bin/sh -c and does not receive signals such as SIGTERM",
+			Suggestion: suggestion,
+		})
+	}
+
+	return findings
+}
+
+// healthcheckCmdPattern captures the command after the CMD keyword of a HEALTHCHECK.
+var healthcheckCmdPattern = regexp.MustCompile(`(?i)\bCMD\s+(.*)$`)
+
+// shellOperators are characters that need a shell to interpret.
+const shellOperators = "|&;<>$`(){}*?"
+
+// HealthcheckShellFormRule checks for HEALTHCHECK commands in shell form (DL5003).
+type HealthcheckShellFormRule struct{}
+
+func (r *HealthcheckShellFormRule) ID() string             { return RuleHealthcheckShell }
+func (r *HealthcheckShellFormRule) Name() string           { return "HEALTHCHECK in shell form" }
+func (r *HealthcheckShellFormRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *HealthcheckShellFormRule) Category() string       { return CategoryBestPractice }
+
+func (r *HealthcheckShellFormRule) Description() string {
+	return "Use the exec form for HEALTHCHECK CMD so the check does not depend on a shell"
+}
+
+func (r *HealthcheckShellFormRule) LongDescription() string {
+	return "A HEALTHCHECK command in shell form runs through /bin/sh -c on every check. The image must contain a shell, each check starts an extra process, and the shell rather than the check command receives signals when a check times out.\n\n" +
+		"Use the exec form, a JSON array of the program and its arguments. If the check needs shell features, put it in a script and run the script in exec form."
+}
+
+func (r *HealthcheckShellFormRule) BadExample() string {
+	return "FROM nginx:1.25-alpine\n" +
+		"HEALTHCHECK CMD curl -f http://localhost/"
+}
+
+func (r *HealthcheckShellFormRule) GoodExample() string {
+	return "FROM nginx:1.25-alpine\n" +
+		"HEALTHCHECK CMD [\"curl\", \"-f\", \"http://localhost/\"]"
+}
+
+func (r *HealthcheckShellFormRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#healthcheck",
+		"https://docs.docker.com/reference/dockerfile/#shell-and-exec-form",
+	}
+}
+
+func (r *HealthcheckShellFormRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, hc := range ast.FindInstructions[*ast.HealthcheckInstruction](dockerfile) {
+		if hc.None || len(hc.Command) != 1 || isExecFormHealthcheck(hc) {
+			continue
+		}
+
+		command := strings.TrimSpace(hc.Command[0])
+		suggestion := "Use the exec form: HEALTHCHECK CMD " + execForm(strings.Fields(command))
+		if strings.ContainsAny(command, shellOperators) {
+			suggestion = "Move the check into a script and run it in exec form, e.g. HEALTHCHECK CMD [\"/usr/local/bin/healthcheck\"]"
+		}
+
+		findings = append(findings, ast.Finding{
+			RuleID:     r.ID(),
+			Severity:   r.Severity(),
+			Line:       hc.Line(),
+			Column:     1,
+			Message:    "HEALTHCHECK command '" + command + "' is in shell form and runs through /bin/sh -c",
+			Suggestion: suggestion,
+		})
+	}
+
+	return findings
+}
+
+// isExecFormHealthcheck reports whether a HEALTHCHECK command was written as a
+// JSON array, which the parser does not distinguish for a single element.
+func isExecFormHealthcheck(hc *ast.HealthcheckInstruction) bool {
+	match := healthcheckCmdPattern.FindStringSubmatch(hc.RawText)
+	return match != nil && strings.HasPrefix(strings.TrimSpace(match[1]), "[")
+}
+
+// execForm formats arguments as a JSON array for an exec form instruction.
+func execForm(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = strconv.Quote(arg)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// InvalidHealthcheckDurationRule checks for HEALTHCHECK options with invalid
+// durations or retry counts, and for timeouts longer than the interval (DL5005).
+type InvalidHealthcheckDurationRule struct{}
+
+func (r *InvalidHealthcheckDurationRule) ID() string             { return RuleHealthcheckOptions }
+func (r *InvalidHealthcheckDurationRule) Name() string           { return "Invalid HEALTHCHECK option" }
+func (r *InvalidHealthcheckDurationRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *InvalidHealthcheckDurationRule) Category() string       { return CategoryCorrectness }
+
+func (r *InvalidHealthcheckDurationRule) Description() string {
+	return "HEALTHCHECK durations need a unit, such as 30s or 1m30s, and --retries must be a whole number"
+}
+
+func (r *InvalidHealthcheckDurationRule) LongDescription() string {
+	return "The --interval, --timeout and --start-period options of HEALTHCHECK take a duration with a unit, such as 30s, 1m30s or 0s, and --retries takes a whole number. A value such as --interval=30 or --retries=abc fails the build.\n\n" +
+		"A timeout longer than the interval is reported as a warning: checks that hang until the timeout run back to back and the health status lags behind. The defaults for both are 30s, and --retries=0 uses the default of 3."
+}
+
+func (r *InvalidHealthcheckDurationRule) BadExample() string {
+	return "FROM nginx:1.25-alpine\n" +
+		"HEALTHCHECK --interval=30 --timeout=5s CMD [\"curl\", \"-f\", \"http://localhost/\"]"
+}
+
+func (r *InvalidHealthcheckDurationRule) GoodExample() string {
+	return "FROM nginx:1.25-alpine\n" +
+		"HEALTHCHECK --interval=30s --timeout=5s CMD [\"curl\", \"-f\", \"http://localhost/\"]"
+}
+
+func (r *InvalidHealthcheckDurationRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#healthcheck",
+		"https://pkg.go.dev/time#ParseDuration",
+	}
+}
+
+// defaultHealthcheckDuration is the default interval and timeout of a HEALTHCHECK.
+const defaultHealthcheckDuration = 30 * time.Second
+
+func (r *InvalidHealthcheckDurationRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, hc := range ast.FindInstructions[*ast.HealthcheckInstruction](dockerfile) {
+		if hc.None {
+			continue
+		}
+		report := func(severity ast.Severity, message, suggestion string) {
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   severity,
+				Line:       hc.Line(),
+				Column:     1,
+				Message:    message,
+				Suggestion: suggestion,
+			})
+		}
+
+		durations := make(map[string]time.Duration)
+		valid := true
+		for _, option := range []struct{ name, value string }{
+			{"interval", hc.Interval},
+			{"timeout", hc.Timeout},
+			{"start-period", hc.Start},
+		} {
+			if option.value == "" || strings.Contains(option.value, "$") {
+				continue
+			}
+			d, err := time.ParseDuration(option.value)
+			if err != nil || d < 0 {
+				suggestion := "Use a duration with a unit, e.g. --" + option.name + "=30s"
+				if _, err := strconv.Atoi(option.value); err == nil {
+					suggestion = "Add a unit: --" + option.name + "=" + option.value + "s"
+				}
+				report(r.Severity(), "HEALTHCHECK --"+option.name+"="+option.value+" is not a valid duration", suggestion)
+				valid = false
+				continue
+			}
+			durations[option.name] = d
+		}
+
+		if hc.Retries != "" && !strings.Contains(hc.Retries, "$") {
+			if n, err := strconv.Atoi(hc.Retries); err != nil || n < 0 {
+				report(r.Severity(), "HEALTHCHECK --retries="+hc.Retries+" is not a whole number", "Use the number of consecutive failures, e.g. --retries=3")
+			}
+		}
+
+		// Unset or zero durations use the default
+		interval, timeout := durations["interval"], durations["timeout"]
+		if interval == 0 {
+			interval = defaultHealthcheckDuration
+		}
+		if timeout == 0 {
+			timeout = defaultHealthcheckDuration
+		}
+		if valid && timeout > interval {
+			report(ast.SeverityWarning,
+				"HEALTHCHECK timeout "+timeout.String()+" is longer than its interval "+interval.String(),
+				"Use a timeout shorter than the interval, or a longer interval")
+		}
+	}
+
+	return findings
+}
+
+// WildcardCopyRule checks for wildcard patterns in COPY/ADD sources (DL5001).
+type WildcardCopyRule struct{}
+
+func (r *WildcardCopyRule) ID() string             { return RuleWildcardCopy }
+func (r *WildcardCopyRule) Name() string           { return "Wildcard in COPY/ADD source" }
+func (r *WildcardCopyRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *WildcardCopyRule) Category() string       { return CategoryPerformance }
+
+func (r *WildcardCopyRule) Description() string {
+	return "Wildcard patterns in COPY/ADD may include unnecessary files, increasing build context size"
+}
+
+func (r *WildcardCopyRule) LongDescription() string {
+	return "Wildcards in COPY and ADD sources match whatever is in the build context, which can include generated files, local configuration or secrets, and makes the layer change whenever any matching file does.\n\n" +
+		"List the files explicitly, or copy a directory that only contains what the image needs."
+}
+
+func (r *WildcardCopyRule) BadExample() string {
+	return "FROM golang:1.22-alpine\n" +
+		"COPY *.go /src/"
+}
+
+func (r *WildcardCopyRule) GoodExample() string {
+	return "FROM golang:1.22-alpine\n" +
+		"COPY main.go server.go /src/"
+}
+
+func (r *WildcardCopyRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#copy",
+	}
+}
+
+func (r *WildcardCopyRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	ast.WalkFunc(dockerfile, func(instr ast.Instruction) bool {
+		switch v := instr.(type) {
+		case *ast.CopyInstruction:
+			// Skip COPY --from (multi-stage copies from other stages)
+			if v.From != "" {
+				return true
+			}
+			if hasWildcard(v.Sources) {
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       v.Line(),
+					Column:     1,
+					Message:    "COPY uses wildcard pattern which may include unnecessary files",
+					Suggestion: "Consider using explicit file paths or a .dockerignore file to exclude unnecessary files",
+				})
+			}
+
+		case *ast.AddInstruction:
+			if hasWildcard(v.Sources) {
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       v.Line(),
+					Column:     1,
+					Message:    "ADD uses wildcard pattern which may include unnecessary files",
+					Suggestion: "Consider using explicit file paths or a .dockerignore file to exclude unnecessary files",
+				})
+			}
+		}
+		return true
+	})
+
+	return findings
+}
+
+// CopyWholeContextRule checks for COPY/ADD of the entire build context (DL3035).
+type CopyWholeContextRule struct{}
+
+func (r *CopyWholeContextRule) ID() string             { return RuleCopyWholeContext }
+func (r *CopyWholeContextRule) Name() string           { return "COPY/ADD of the whole build context" }
+func (r *CopyWholeContextRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *CopyWholeContextRule) Category() string       { return CategoryPerformance }
+
+func (r *CopyWholeContextRule) Description() string {
+	return "COPY . copies the entire build context; use a .dockerignore file to exclude what the image does not need"
+}
+
+func (r *CopyWholeContextRule) LongDescription() string {
+	return "A COPY or ADD whose source is . copies everything in the build context, which often includes the .git directory, node_modules, build output and local configuration. The image grows, secrets can end up in a layer, and any change to an unrelated file invalidates the cache for this and every later instruction.\n\n" +
+		"Add a .dockerignore file that excludes what the image does not need, or copy only the files and directories the build uses."
+}
+
+func (r *CopyWholeContextRule) BadExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY . /app"
+}
+
+func (r *CopyWholeContextRule) GoodExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY package.json package-lock.json /app/\n" +
+		"COPY src/ /app/src/"
+}
+
+func (r *CopyWholeContextRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/concepts/context/#dockerignore-files",
+		"https://docs.docker.com/reference/dockerfile/#copy",
+	}
+}
+
+func (r *CopyWholeContextRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	ast.WalkFunc(dockerfile, func(instr ast.Instruction) bool {
+		var sources []string
+		var instrName string
+
+		switch v := instr.(type) {
+		case *ast.CopyInstruction:
+			// Skip COPY --from (multi-stage copies from other stages)
+			if v.From != "" {
+				return true
+			}
+			sources, instrName = v.Sources, "COPY"
+		case *ast.AddInstruction:
+			sources, instrName = v.Sources, "ADD"
+		default:
+			return true
+		}
+
+		// Wildcards such as * are reported by DL5001
+		for _, source := range sources {
+			if source == "." || source == "./" {
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       instr.Line(),
+					Column:     1,
+					Message:    instrName + " source '" + source + "' copies the entire build context",
+					Suggestion: "Add a .dockerignore file that excludes .git, node_modules and build output, or copy only the files the image needs",
+				})
+				break
+			}
+		}
+		return true
+	})
+
+	return findings
+}
+
+// hasWildcard checks if any source path contains wildcard characters.
+func hasWildcard(sources []string) bool {
+	for _, source := range sources {
+		// Get just the filename part for checking
+		base := filepath.Base(source)
+		for _, wc := range wildcardChars {
+			if strings.Contains(base, wc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CopyMultipleSourcesRule checks that COPY/ADD with several sources copies into a
+// directory destination ending in a slash (DL3026).
+type CopyMultipleSourcesRule struct{}
+
+func (r *CopyMultipleSourcesRule) ID() string             { return RuleCopyMultipleSrc }
+func (r *CopyMultipleSourcesRule) Name() string           { return "COPY/ADD multiple sources to a file" }
+func (r *CopyMultipleSourcesRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *CopyMultipleSourcesRule) Category() string       { return CategoryCorrectness }
+
+func (r *CopyMultipleSourcesRule) Description() string {
+	return "When COPY/ADD has multiple sources, the destination must be a directory ending with /"
+}
+
+func (r *CopyMultipleSourcesRule) LongDescription() string {
+	return "When COPY or ADD has more than one source, the destination must be a directory and must end with /. Otherwise the build fails."
+}
+
+func (r *CopyMultipleSourcesRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"COPY go.mod go.sum /src"
+}
+
+func (r *CopyMultipleSourcesRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"COPY go.mod go.sum /src/"
+}
+
+func (r *CopyMultipleSourcesRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#copy",
+	}
+}
+
+func (r *CopyMultipleSourcesRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		var name, dest string
+		var sources []string
+
+		switch v := instr.(type) {
+		case *ast.CopyInstruction:
+			name, sources, dest = "COPY", v.Sources, v.Dest
+		case *ast.AddInstruction:
+			name, sources, dest = "ADD", v.Sources, v.Dest
+		default:
+			continue
+		}
+
+		if isDirectoryDest(dest) {
+			continue
+		}
+
+		suggestion := "Add a trailing slash to the destination, e.g. '" + dest + "/'"
+
+		if len(sources) > 1 {
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       instr.Line(),
+				Column:     1,
+				Message:    name + " with multiple sources requires destination '" + dest + "' to end with /",
+				Suggestion: suggestion,
+			})
+		} else if hasWildcard(sources) {
+			// A wildcard may match several files, which fails the build the same way
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   ast.SeverityWarning,
+				Line:       instr.Line(),
+				Column:     1,
+				Message:    name + " source '" + sources[0] + "' may match multiple files but destination '" + dest + "' does not end with /",
+				Suggestion: suggestion,
+			})
+		}
+	}
+
+	return findings
+}
+
+// isDirectoryDest reports whether a COPY/ADD destination is unambiguously a directory.
+// Destinations containing variables are treated as directories since they cannot be resolved.
+func isDirectoryDest(dest string) bool {
+	if strings.HasSuffix(dest, "/") || strings.Contains(dest, "$") {
+		return true
+	}
+	return dest == "." || dest == ".." || strings.HasSuffix(dest, "/.") || strings.HasSuffix(dest, "/..")
+}
+
+// CopyChownRule checks for COPY and ADD instructions without --chown that
+// precede a switch to a non-root USER in the same stage (DL3038).
+type CopyChownRule struct{}
+
+func (r *CopyChownRule) ID() string             { return RuleCopyChown }
+func (r *CopyChownRule) Name() string           { return "COPY without --chown before USER" }
+func (r *CopyChownRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *CopyChownRule) Category() string       { return CategoryCorrectness }
+
+func (r *CopyChownRule) Description() string {
+	return "Files copied before switching to a non-root USER are owned by root; add --chown if the user needs to write to them"
+}
+
+func (r *CopyChownRule) LongDescription() string {
+	return "COPY and ADD create files owned by root unless --chown is given. When the stage later switches to a non-root USER, the application runs as that user and cannot write to its own files, for example to a cache or upload directory under /app.\n\n" +
+		"Add --chown=<user> when the user needs write access. Files the application only reads are safer owned by root, so ignore the rule for those. COPY --from is not reported, since build artifacts are usually meant to be read-only."
+}
+
+func (r *CopyChownRule) BadExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY . /app\n" +
+		"USER node"
+}
+
+func (r *CopyChownRule) GoodExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY --chown=node . /app\n" +
+		"USER node"
+}
+
+func (r *CopyChownRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#copy---chown---chmod",
+		"https://docs.docker.com/reference/dockerfile/#user",
+	}
+}
+
+func (r *CopyChownRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, stage := range dockerfile.Stages {
+		for i, instr := range stage.Instructions {
+			switch v := instr.(type) {
+			case *ast.CopyInstruction:
+				if v.Chown != "" || v.From != "" {
+					continue
+				}
+			case *ast.AddInstruction:
+				if v.Chown != "" {
+					continue
+				}
+			default:
+				continue
+			}
+
+			user := nextNonRootUser(stage.Instructions[i+1:])
+			if user == nil {
+				continue
+			}
+			owner := user.User
+			if user.Group != "" {
+				owner += ":" + user.Group
+			}
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       instr.Line(),
+				Column:     1,
+				Message:    string(instr.Type()) + " without --chown creates root-owned files, but the stage switches to USER " + owner + " on line " + intToString(user.Line()),
+				Suggestion: "Add '--chown=" + owner + "' if the user needs to write to the copied files",
+			})
+		}
+	}
+
+	return findings
+}
+
+// nextNonRootUser returns the first USER instruction in instructions that
+// switches to a user other than root, or nil if there is none.
+func nextNonRootUser(instructions []ast.Instruction) *ast.UserInstruction {
+	for _, instr := range instructions {
+		if user, ok := instr.(*ast.UserInstruction); ok && isNonRootUser(user) {
+			return user
+		}
+	}
+	return nil
+}
+
+// isNonRootUser reports whether a USER instruction switches to a user other
+// than root. Users given by a variable may be root and are not counted.
+func isNonRootUser(user *ast.UserInstruction) bool {
+	return user.User != "" && !isRootUser(user.User) && !strings.Contains(user.User, "$")
+}
+
+// CopyChownMissingRule checks for COPY and ADD instructions without --chown
+// while a non-root USER is in effect (DL5004).
+type CopyChownMissingRule struct{}
+
+func (r *CopyChownMissingRule) ID() string             { return RuleCopyChownMissing }
+func (r *CopyChownMissingRule) Name() string           { return "COPY without --chown as non-root USER" }
+func (r *CopyChownMissingRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *CopyChownMissingRule) Category() string       { return CategoryCorrectness }
+
+func (r *CopyChownMissingRule) Description() string {
+	return "COPY creates root-owned files even after USER switches to a non-root user; add --chown=<user>:<group>"
+}
+
+func (r *CopyChownMissingRule) LongDescription() string {
+	return "USER changes the user that RUN, CMD and ENTRYPOINT run as, but not the owner of the files COPY and ADD create: without --chown they are owned by root. A COPY after USER app looks like it gives the files to app, and the application then fails with permission errors when it writes to them.\n\n" +
+		"Add --chown with the user and group of the USER instruction. COPY --from is not reported, since build artifacts are usually meant to be read-only; DL3038 reports COPY instructions before the USER switch."
+}
+
+func (r *CopyChownMissingRule) BadExample() string {
+	return "FROM node:20-alpine\n" +
+		"USER node\n" +
+		"COPY . /app"
+}
+
+func (r *CopyChownMissingRule) GoodExample() string {
+	return "FROM node:20-alpine\n" +
+		"USER node\n" +
+		"COPY --chown=node:node . /app"
+}
+
+func (r *CopyChownMissingRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#copy---chown---chmod",
+		"https://docs.docker.com/reference/dockerfile/#user",
+	}
+}
+
+func (r *CopyChownMissingRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, stage := range dockerfile.Stages {
+		// The user in effect; nil while it is root or unknown
+		var user *ast.UserInstruction
+		for _, instr := range stage.Instructions {
+			switch v := instr.(type) {
+			case *ast.UserInstruction:
+				user = nil
+				if isNonRootUser(v) {
+					user = v
+				}
+				continue
+			case *ast.CopyInstruction:
+				if v.Chown != "" || v.From != "" {
+					continue
+				}
+			case *ast.AddInstruction:
+				if v.Chown != "" {
+					continue
+				}
+			default:
+				continue
+			}
+			if user == nil {
+				continue
+			}
+
+			group := user.Group
+			if group == "" {
+				group = "<group>"
+			}
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       instr.Line(),
+				Column:     1,
+				Message:    string(instr.Type()) + " without --chown creates root-owned files although USER " + user.User + " is in effect since line " + intToString(user.Line()),
+				Suggestion: "Add '--chown=" + user.User + ":" + group + "' to give the files to the user",
+			})
+		}
+	}
+
+	return findings
+}
+
+// RelativeCopyDestRule checks for COPY and ADD instructions with a relative
+// destination in a stage that has not set a WORKDIR (DL3039).
+type RelativeCopyDestRule struct{}
+
+func (r *RelativeCopyDestRule) ID() string             { return RuleRelativeCopyDest }
+func (r *RelativeCopyDestRule) Name() string           { return "COPY to relative path without WORKDIR" }
+func (r *RelativeCopyDestRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *RelativeCopyDestRule) Category() string       { return CategoryCorrectness }
+
+func (r *RelativeCopyDestRule) Description() string {
+	return "A relative COPY/ADD destination is resolved against WORKDIR; set a WORKDIR first or use an absolute path"
+}
+
+func (r *RelativeCopyDestRule) LongDescription() string {
+	return "COPY and ADD resolve a relative destination against the working directory. Without a WORKDIR instruction that is the working directory of the base image, usually /, so COPY . . scatters the build context over the root of the file system.\n\n" +
+		"Set a WORKDIR before copying, or use an absolute destination. A stage based on an earlier stage inherits its WORKDIR. Base images that set their own WORKDIR, such as golang, are still reported, since the Dockerfile alone does not show where the files end up."
+}
+
+func (r *RelativeCopyDestRule) BadExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY . ."
+}
+
+func (r *RelativeCopyDestRule) GoodExample() string {
+	return "FROM node:20-alpine\n" +
+		"WORKDIR /app\n" +
+		"COPY . ."
+}
+
+func (r *RelativeCopyDestRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#copy",
+		"https://docs.docker.com/reference/dockerfile/#workdir",
+	}
+}
+
+func (r *RelativeCopyDestRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// hasWorkdir records, by stage index, whether a stage set a WORKDIR
+	hasWorkdir := make(map[int]bool)
+	for _, stage := range dockerfile.Stages {
+		workdir := false
+		if stage.FromInstr != nil {
+			if base, ok := dockerfile.StageByName(stage.FromInstr.Image); ok && base.Index < stage.Index {
+				workdir = hasWorkdir[base.Index]
+			}
+		}
+
+		for _, instr := range stage.Instructions {
+			var dest string
+			switch v := instr.(type) {
+			case *ast.WorkdirInstruction:
+				workdir = true
+				continue
+			case *ast.CopyInstruction:
+				dest = v.Dest
+			case *ast.AddInstruction:
+				dest = v.Dest
+			default:
+				continue
+			}
+			if workdir || !isRelativeDest(dest) {
+				continue
+			}
+
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       instr.Line(),
+				Column:     1,
+				Message:    string(instr.Type()) + " to relative destination '" + dest + "' without a WORKDIR in the stage",
+				Suggestion: "Set 'WORKDIR /app' (or another absolute path) before this instruction, or use an absolute destination",
+			})
+		}
+		hasWorkdir[stage.Index] = workdir
+	}
+
+	return findings
+}
+
+// isRelativeDest reports whether a COPY/ADD destination is a relative path.
+// Destinations starting with a variable and Windows paths such as c:\app are
+// not treated as relative.
+func isRelativeDest(dest string) bool {
+	if dest == "" || strings.HasPrefix(dest, "/") || strings.HasPrefix(dest, "$") {
+		return false
+	}
+	drive := len(dest) >= 2 && dest[1] == ':' && unicode.IsLetter(rune(dest[0]))
+	return !drive
+}
+
 // init registers the best practice rules with the default registry.
 func init() {
 	RegisterDefault(&MultipleCMDRule{})
 	RegisterDefault(&MultipleEntrypointRule{})
 	RegisterDefault(&RelativeWorkdirRule{})
+	RegisterDefault(&RunCdRule{})
+	RegisterDefault(&InvalidPortRule{})
+	RegisterDefault(&InvalidStopSignalRule{})
+	RegisterDefault(&ExecFormRule{})
+	RegisterDefault(&CopyMultipleSourcesRule{})
+	RegisterDefault(&PipefailRule{})
+	RegisterDefault(&DuplicateEnvRule{})
+	RegisterDefault(&DuplicateLabelRule{})
+	RegisterDefault(&UnusedArgRule{})
+	RegisterDefault(&DeprecatedMaintainerRule{})
 	RegisterDefault(&MissingHealthcheckRule{})
+	RegisterDefault(&HealthcheckShellFormRule{})
+	RegisterDefault(&InvalidHealthcheckDurationRule{})
 	RegisterDefault(&WildcardCopyRule{})
+	RegisterDefault(&CopyWholeContextRule{})
+	RegisterDefault(&CopyChownRule{})
+	RegisterDefault(&CopyChownMissingRule{})
+	RegisterDefault(&RelativeCopyDestRule{})
 }
@@ -1,9 +1,11 @@
 package rules
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
 )
 
 func TestBestPracticeRulesRegistered(t *testing.T) {
@@ -12,8 +14,24 @@ func TestBestPracticeRulesRegistered(t *testing.T) {
 		RuleMultipleCMD,        // DL3001
 		RuleMultipleEntrypoint, // DL3002
 		RuleRelativeWorkdir,    // DL3003
+		RuleRunCd,              // DL3004
+		RuleInvalidPort,        // DL3005
+		RuleDuplicateLabel,     // DL3018
+		RuleUnusedArg,          // DL3021
+		RuleExecForm,           // DL3025
+		RuleCopyMultipleSrc,    // DL3026
+		RulePipefail,           // DL3028
+		RuleDuplicateEnv,       // DL3029
+		RuleDeprecatedMaint,    // DL3030
+		RuleInvalidStopSignal,  // DL3034
+		RuleCopyWholeContext,   // DL3035
+		RuleCopyChown,          // DL3038
+		RuleRelativeCopyDest,   // DL3039
 		RuleMissingHealthcheck, // DL5000
 		RuleWildcardCopy,       // DL5001
+		RuleHealthcheckShell,   // DL5003
+		RuleCopyChownMissing,   // DL5004
+		RuleHealthcheckOptions, // DL5005
 	}
 
 	for _, ruleID := range expectedRules {
@@ -175,6 +193,185 @@ func TestRelativeWorkdirRule(t *testing.T) {
 	}
 }
 
+func TestRelativeWorkdirRule_Fix(t *testing.T) {
+	rule := &RelativeWorkdirRule{}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		fixed    int
+	}{
+		{"relative path", "FROM alpine:3.18\nWORKDIR app\n", "FROM alpine:3.18\nWORKDIR /app\n", 1},
+		{"dot-relative path", "FROM alpine:3.18\nWORKDIR ./src/app\n", "FROM alpine:3.18\nWORKDIR /src/app\n", 1},
+		{"path matching the keyword", "FROM alpine:3.18\nWORKDIR WORKDIR\n", "FROM alpine:3.18\nWORKDIR /WORKDIR\n", 1},
+		{"absolute path", "FROM alpine:3.18\nWORKDIR /app\n", "FROM alpine:3.18\nWORKDIR /app\n", 0},
+		{"variable path", "FROM alpine:3.18\nWORKDIR $APP_DIR\n", "FROM alpine:3.18\nWORKDIR $APP_DIR\n", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := fixContent(t, rule, tt.content)
+			if result.Fixed != tt.fixed || string(result.Source) != tt.expected {
+				t.Errorf("Fix() fixed %d:\n%s\nwant %d:\n%s", result.Fixed, result.Source, tt.fixed, tt.expected)
+			}
+		})
+	}
+
+	if fixes, err := rule.Fix(ast.Finding{Line: 2}, &ast.Dockerfile{Instructions: []ast.Instruction{&ast.UserInstruction{LineNum: 2, User: "app"}}}); len(fixes) != 0 || err != nil {
+		t.Errorf("Fix() on another instruction = %v, %v; want no fixes", fixes, err)
+	}
+}
+
+func TestRunCdRule(t *testing.T) {
+	rule := &RunCdRule{}
+
+	tests := []struct {
+		name          string
+		command       string
+		expectedCount int
+	}{
+		{
+			name:          "single cd command - warning",
+			command:       "cd /app",
+			expectedCount: 1,
+		},
+		{
+			name:          "cd chained with && - warning",
+			command:       "cd /app && make",
+			expectedCount: 1,
+		},
+		{
+			name:          "cd after semicolon - warning",
+			command:       "make deps; cd /src; make",
+			expectedCount: 1,
+		},
+		{
+			name:          "multiple cd commands - warning for each",
+			command:       "cd /build && make && cd /app && ./install.sh",
+			expectedCount: 2,
+		},
+		{
+			name:          "cd in subshell - no warning",
+			command:       "(cd /tmp && tar xzf archive.tgz) && make",
+			expectedCount: 0,
+		},
+		{
+			name:          "cd later in subshell - no warning",
+			command:       "(make && cd /tmp && ls) && echo done",
+			expectedCount: 0,
+		},
+		{
+			name:          "cd dash - no warning",
+			command:       "make && cd -",
+			expectedCount: 0,
+		},
+		{
+			name:          "cd without argument - no warning",
+			command:       "cd && ls",
+			expectedCount: 0,
+		},
+		{
+			name:          "no cd - no warning",
+			command:       "make install",
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 1, Command: tt.command},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+}
+
+func TestInvalidPortRule(t *testing.T) {
+	rule := &InvalidPortRule{}
+
+	tests := []struct {
+		name             string
+		ports            []string
+		expectedCount    int
+		expectedSeverity ast.Severity
+	}{
+		{
+			name:          "valid ports - no warning",
+			ports:         []string{"80", "8080/tcp", "53/udp", "65535"},
+			expectedCount: 0,
+		},
+		{
+			name:          "port range - no warning",
+			ports:         []string{"8000-8010", "9000-9010/udp"},
+			expectedCount: 0,
+		},
+		{
+			name:          "variable - no warning",
+			ports:         []string{"$PORT", "${PORT}/tcp"},
+			expectedCount: 0,
+		},
+		{
+			name:             "port zero - error",
+			ports:            []string{"0"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityError,
+		},
+		{
+			name:             "port above range - error",
+			ports:            []string{"70000/tcp"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityError,
+		},
+		{
+			name:             "non-numeric port - error",
+			ports:            []string{"http"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityError,
+		},
+		{
+			name:             "reversed range - error",
+			ports:            []string{"8010-8000"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityError,
+		},
+		{
+			name:             "unknown protocol - warning",
+			ports:            []string{"8080/http"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.ExposeInstruction{LineNum: 3, Ports: tt.ports},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			for _, f := range findings {
+				if f.Severity != tt.expectedSeverity {
+					t.Errorf("expected severity %s, got %s", tt.expectedSeverity, f.Severity)
+				}
+				if f.Line != 3 {
+					t.Errorf("expected finding on line 3, got %d", f.Line)
+				}
+			}
+		})
+	}
+}
+
 func TestMissingHealthcheckRule(t *testing.T) {
 	rule := &MissingHealthcheckRule{}
 
@@ -229,6 +426,123 @@ func TestMissingHealthcheckRule(t *testing.T) {
 	}
 }
 
+func TestExecFormRule(t *testing.T) {
+	rule := &ExecFormRule{}
+
+	tests := []struct {
+		name          string
+		instruction   string
+		expectedCount int
+		suggestion    string
+	}{
+		{"CMD shell form", "CMD echo hi", 1, `CMD ["echo", "hi"]`},
+		{"CMD exec form", `CMD ["./app"]`, 0, ""},
+		{"CMD needs shell", `CMD sh -c "a && b"`, 0, ""},
+		{"CMD with pipe", "CMD cat log | grep error", 0, ""},
+		{"CMD with variable", "CMD ./app --port $PORT", 0, ""},
+		{"CMD with redirect", "CMD ./app > /var/log/app.log", 0, ""},
+		{"CMD with quotes", `CMD echo "hello world"`, 1, `CMD ["executable", "arg"]`},
+		{"ENTRYPOINT shell form", "ENTRYPOINT /usr/bin/app --serve", 1, `ENTRYPOINT ["/usr/bin/app", "--serve"]`},
+		{"ENTRYPOINT exec form", `ENTRYPOINT ["/usr/bin/app"]`, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString("FROM alpine:3.18\n" + tt.instruction + "\n")
+			if err != nil {
+				t.Fatalf("Failed to parse Dockerfile: %v", err)
+			}
+			findings := rule.Check(df)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.expectedCount > 0 && !strings.Contains(findings[0].Suggestion, tt.suggestion) {
+				t.Errorf("Suggestion = %q, want it to contain %q", findings[0].Suggestion, tt.suggestion)
+			}
+		})
+	}
+}
+
+func TestHealthcheckShellFormRule(t *testing.T) {
+	rule := &HealthcheckShellFormRule{}
+
+	tests := []struct {
+		name          string
+		healthcheck   string
+		expectedCount int
+		suggestion    string
+	}{
+		{"exec form", `HEALTHCHECK CMD ["curl", "-f", "http://localhost/"]`, 0, ""},
+		{"exec form single element", `HEALTHCHECK CMD ["/healthcheck"]`, 0, ""},
+		{"shell form", "HEALTHCHECK --interval=30s CMD curl -f http://localhost/", 1, `HEALTHCHECK CMD ["curl", "-f", "http://localhost/"]`},
+		{"shell form with operators", "HEALTHCHECK CMD curl -f http://localhost/ || exit 1", 1, "script"},
+		{"NONE", "HEALTHCHECK NONE", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString("FROM nginx:1.25-alpine\n" + tt.healthcheck + "\n")
+			if err != nil {
+				t.Fatalf("Failed to parse Dockerfile: %v", err)
+			}
+			findings := rule.Check(df)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.expectedCount > 0 && !strings.Contains(findings[0].Suggestion, tt.suggestion) {
+				t.Errorf("Suggestion = %q, want it to contain %q", findings[0].Suggestion, tt.suggestion)
+			}
+		})
+	}
+}
+
+func TestCopyWholeContextRule(t *testing.T) {
+	rule := &CopyWholeContextRule{}
+
+	tests := []struct {
+		name          string
+		instruction   string
+		expectedCount int
+	}{
+		{"COPY dot", "COPY . /app", 1},
+		{"COPY dot slash", "COPY ./ /app", 1},
+		{"COPY dot dot", "COPY . .", 1},
+		{"ADD dot", "ADD . /app", 1},
+		{"COPY directory", "COPY src/ /app", 0},
+		{"COPY wildcard", "COPY * /app/", 0},
+		{"COPY from stage", "COPY --from=builder . /app", 0},
+		{"one finding per instruction", "COPY . ./ /app/", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString("FROM alpine:3.18 AS builder\n" + tt.instruction + "\n")
+			if err != nil {
+				t.Fatalf("Failed to parse Dockerfile: %v", err)
+			}
+			findings := rule.Check(df)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+}
+
+func TestCopyWholeContextRule_NotReportedByDL4006(t *testing.T) {
+	df, err := parser.ParseString("FROM alpine:3.18\nCOPY . /app\n")
+	if err != nil {
+		t.Fatalf("Failed to parse Dockerfile: %v", err)
+	}
+
+	var findings []ast.Finding
+	for _, rule := range []Rule{&CopyWholeContextRule{}, &CopyGitDirRule{}} {
+		findings = append(findings, rule.Check(df)...)
+	}
+	if len(findings) != 1 || findings[0].RuleID != RuleCopyWholeContext {
+		t.Errorf("expected one DL3035 finding, got %v", findings)
+	}
+}
+
 func TestWildcardCopyRule(t *testing.T) {
 	rule := &WildcardCopyRule{}
 
@@ -302,3 +616,667 @@ func TestWildcardCopyRule(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyMultipleSourcesRule(t *testing.T) {
+	rule := &CopyMultipleSourcesRule{}
+
+	tests := []struct {
+		name             string
+		instruction      ast.Instruction
+		expectedCount    int
+		expectedSeverity ast.Severity
+	}{
+		{
+			name:             "COPY multiple sources without trailing slash",
+			instruction:      &ast.CopyInstruction{LineNum: 3, Sources: []string{"a.txt", "b.txt"}, Dest: "/app"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityError,
+		},
+		{
+			name:             "ADD multiple sources without trailing slash",
+			instruction:      &ast.AddInstruction{LineNum: 3, Sources: []string{"a.tar", "b.tar"}, Dest: "/opt"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityError,
+		},
+		{
+			name:          "COPY multiple sources with trailing slash",
+			instruction:   &ast.CopyInstruction{LineNum: 3, Sources: []string{"a.txt", "b.txt"}, Dest: "/app/"},
+			expectedCount: 0,
+		},
+		{
+			name:          "COPY multiple sources to current directory",
+			instruction:   &ast.CopyInstruction{LineNum: 3, Sources: []string{"package.json", "yarn.lock"}, Dest: "."},
+			expectedCount: 0,
+		},
+		{
+			name:          "COPY multiple sources to variable destination",
+			instruction:   &ast.CopyInstruction{LineNum: 3, Sources: []string{"a.txt", "b.txt"}, Dest: "$APP_HOME"},
+			expectedCount: 0,
+		},
+		{
+			name:             "COPY wildcard source without trailing slash",
+			instruction:      &ast.CopyInstruction{LineNum: 3, Sources: []string{"*.json"}, Dest: "/app"},
+			expectedCount:    1,
+			expectedSeverity: ast.SeverityWarning,
+		},
+		{
+			name:          "COPY single source to file",
+			instruction:   &ast.CopyInstruction{LineNum: 3, Sources: []string{"app.conf"}, Dest: "/etc/app.conf"},
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{tt.instruction},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.expectedCount > 0 {
+				if findings[0].Severity != tt.expectedSeverity {
+					t.Errorf("expected severity %v, got %v", tt.expectedSeverity, findings[0].Severity)
+				}
+				if findings[0].Line != 3 {
+					t.Errorf("expected line 3, got %d", findings[0].Line)
+				}
+			}
+		})
+	}
+}
+
+func TestPipefailRule(t *testing.T) {
+	rule := &PipefailRule{}
+
+	bashPipefail := &ast.ShellInstruction{LineNum: 2, Shell: []string{"/bin/bash", "-o", "pipefail", "-c"}}
+
+	tests := []struct {
+		name          string
+		instructions  []ast.Instruction
+		expectedCount int
+	}{
+		{
+			name: "pipe without pipefail",
+			instructions: []ast.Instruction{
+				&ast.RunInstruction{LineNum: 3, Command: "curl -sSL https://example.com/app.tar.gz | tar -xz", Shell: true},
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "pipe after SHELL with pipefail",
+			instructions: []ast.Instruction{
+				bashPipefail,
+				&ast.RunInstruction{LineNum: 3, Command: "curl -sSL https://example.com/app.tar.gz | tar -xz", Shell: true},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "combined shell flags",
+			instructions: []ast.Instruction{
+				&ast.ShellInstruction{LineNum: 2, Shell: []string{"/bin/bash", "-euo", "pipefail", "-c"}},
+				&ast.RunInstruction{LineNum: 3, Command: "cat file | grep x", Shell: true},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "SHELL without pipefail",
+			instructions: []ast.Instruction{
+				&ast.ShellInstruction{LineNum: 2, Shell: []string{"/bin/bash", "-c"}},
+				&ast.RunInstruction{LineNum: 3, Command: "cat file | grep x", Shell: true},
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "pipefail set in command",
+			instructions: []ast.Instruction{
+				&ast.RunInstruction{LineNum: 3, Command: "set -o pipefail && cat file | grep x", Shell: true},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "logical or is not a pipe",
+			instructions: []ast.Instruction{
+				&ast.RunInstruction{LineNum: 3, Command: "test -f /app || exit 1", Shell: true},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "pipe inside quotes",
+			instructions: []ast.Instruction{
+				&ast.RunInstruction{LineNum: 3, Command: "echo 'a | b' && grep \"x|y\" file", Shell: true},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "exec form RUN",
+			instructions: []ast.Instruction{
+				&ast.RunInstruction{LineNum: 3, Command: `["sh", "-c", "cat file | grep x"]`, Shell: false},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "PowerShell",
+			instructions: []ast.Instruction{
+				&ast.ShellInstruction{LineNum: 2, Shell: []string{"powershell", "-Command"}},
+				&ast.RunInstruction{LineNum: 3, Command: "Get-ChildItem | Select-Object Name", Shell: true},
+			},
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{{Index: 0, Instructions: tt.instructions}},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+
+	t.Run("SHELL does not carry over to the next stage", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{
+				{Index: 0, Instructions: []ast.Instruction{bashPipefail}},
+				{Index: 1, Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 5, Command: "cat file | grep x", Shell: true},
+				}},
+			},
+		}
+		findings := rule.Check(dockerfile)
+		if len(findings) != 1 || findings[0].Line != 5 {
+			t.Errorf("expected 1 finding on line 5, got %+v", findings)
+		}
+	})
+}
+
+func TestDuplicateEnvRule(t *testing.T) {
+	rule := &DuplicateEnvRule{}
+
+	tests := []struct {
+		name          string
+		envs          [][2]string
+		expectedLines []int
+	}{
+		{"no duplicates", [][2]string{{"A", "1"}, {"B", "2"}}, nil},
+		{"duplicate key", [][2]string{{"A", "1"}, {"B", "2"}, {"A", "3"}}, []int{1}},
+		{"key set three times", [][2]string{{"A", "1"}, {"A", "2"}, {"A", "3"}}, []int{1, 2}},
+		{"keys are case-sensitive", [][2]string{{"path", "1"}, {"PATH", "2"}}, nil},
+		{"later value extends earlier", [][2]string{{"PATH", "/opt/bin:$PATH"}, {"PATH", "/usr/local/go/bin:${PATH}"}}, nil},
+		{"similar variable name is not a reference", [][2]string{{"PATH", "/a"}, {"PATH", "$PATHS"}}, []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var instructions []ast.Instruction
+			for i, kv := range tt.envs {
+				instructions = append(instructions, &ast.EnvInstruction{LineNum: i + 1, Key: kv[0], Value: kv[1]})
+			}
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{{Index: 0, Instructions: instructions}},
+			}
+
+			findings := rule.Check(dockerfile)
+			if len(findings) != len(tt.expectedLines) {
+				t.Fatalf("expected %d findings, got %d", len(tt.expectedLines), len(findings))
+			}
+			for i, line := range tt.expectedLines {
+				if findings[i].Line != line {
+					t.Errorf("finding %d on line %d, want %d", i, findings[i].Line, line)
+				}
+			}
+		})
+	}
+
+	t.Run("message names the conflicting line", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{{Index: 0, Instructions: []ast.Instruction{
+				&ast.EnvInstruction{LineNum: 2, Key: "MODE", Value: "dev"},
+				&ast.EnvInstruction{LineNum: 7, Key: "MODE", Value: "prod"},
+			}}},
+		}
+		findings := rule.Check(dockerfile)
+		if len(findings) != 1 || !strings.Contains(findings[0].Message, "line 7") {
+			t.Errorf("expected message mentioning line 7, got %+v", findings)
+		}
+	})
+
+	t.Run("key repeated in a multi-value ENV", func(t *testing.T) {
+		findings := countFindings(t, rule, "FROM alpine:3.18\nENV MODE=dev PORT=80\nENV PORT=8080\n")
+		if findings != 1 {
+			t.Errorf("expected %d findings, got %d", 1, findings)
+		}
+	})
+
+	t.Run("same key in different stages", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{
+				{Index: 0, Instructions: []ast.Instruction{&ast.EnvInstruction{LineNum: 2, Key: "A", Value: "1"}}},
+				{Index: 1, Instructions: []ast.Instruction{&ast.EnvInstruction{LineNum: 4, Key: "A", Value: "2"}}},
+			},
+		}
+		if findings := rule.Check(dockerfile); len(findings) != 0 {
+			t.Errorf("expected 0 findings, got %d", len(findings))
+		}
+	})
+}
+
+func TestUnusedArgRule(t *testing.T) {
+	rule := &UnusedArgRule{}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedLines []int
+	}{
+		{"referenced with braces", "FROM alpine:3.18\nARG VERSION=1.0\nRUN echo ${VERSION}\n", nil},
+		{"referenced without braces", "FROM alpine:3.18\nARG VERSION\nLABEL version=$VERSION\n", nil},
+		{"referenced with default", "FROM alpine:3.18\nARG VERSION\nRUN echo ${VERSION:-dev}\n", nil},
+		{"unreferenced", "FROM alpine:3.18\nARG VERSION=1.0\nRUN echo hello\n", []int{2}},
+		{"similar name is not a reference", "FROM alpine:3.18\nARG VERSION=1.0\nRUN echo $VERSIONS\n", []int{2}},
+		{"referenced before declaration", "FROM alpine:3.18\nRUN echo $VERSION\nARG VERSION=1.0\n", []int{3}},
+		{"used only in FROM", "ARG GO_VERSION=1.22\nFROM golang:${GO_VERSION}\nRUN go build\n", nil},
+		{"global unused", "ARG GO_VERSION=1.22\nFROM golang:1.22\nRUN go build\n", []int{1}},
+		{"global used by later global default", "ARG BASE=alpine\nARG IMAGE=${BASE}:3.18\nFROM ${IMAGE}\n", nil},
+		{"global redeclared in stage", "ARG VERSION=1.0\nFROM alpine:3.18\nARG VERSION\nRUN echo $VERSION\n", nil},
+		{"global redeclared but unused in stage", "ARG VERSION=1.0\nFROM alpine:3.18\nARG VERSION\nRUN echo hello\n", []int{3}},
+		{"used in a different stage", "FROM alpine:3.18 AS build\nARG VERSION=1.0\nFROM alpine:3.18\nRUN echo $VERSION\n", []int{2}},
+		{"proxy argument", "FROM alpine:3.18\nARG HTTP_PROXY\nRUN apk add curl\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			findings := rule.Check(df)
+			if len(findings) != len(tt.expectedLines) {
+				t.Fatalf("expected %d findings, got %d", len(tt.expectedLines), len(findings))
+			}
+			for i, line := range tt.expectedLines {
+				if findings[i].Line != line {
+					t.Errorf("finding %d on line %d, want %d", i, findings[i].Line, line)
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateLabelRule(t *testing.T) {
+	rule := &DuplicateLabelRule{}
+
+	tests := []struct {
+		name          string
+		stages        [][]*ast.LabelInstruction
+		expectedLines []int
+	}{
+		{
+			name: "distinct keys",
+			stages: [][]*ast.LabelInstruction{{
+				{LineNum: 2, Keys: []string{"version", "maintainer"}},
+				{LineNum: 3, Keys: []string{"description"}},
+			}},
+		},
+		{
+			name: "duplicate key in one instruction",
+			stages: [][]*ast.LabelInstruction{{
+				{LineNum: 2, Keys: []string{"version", "maintainer", "version"}},
+			}},
+			expectedLines: []int{2},
+		},
+		{
+			name: "duplicate key across instructions",
+			stages: [][]*ast.LabelInstruction{{
+				{LineNum: 2, Keys: []string{"version"}},
+				{LineNum: 5, Keys: []string{"maintainer"}},
+				{LineNum: 7, Keys: []string{"version"}},
+			}},
+			expectedLines: []int{7},
+		},
+		{
+			name: "key set three times",
+			stages: [][]*ast.LabelInstruction{{
+				{LineNum: 2, Keys: []string{"version", "version"}},
+				{LineNum: 3, Keys: []string{"version"}},
+			}},
+			expectedLines: []int{2, 3},
+		},
+		{
+			name: "keys are case-sensitive",
+			stages: [][]*ast.LabelInstruction{{
+				{LineNum: 2, Keys: []string{"version", "Version"}},
+			}},
+		},
+		{
+			name: "same key in different stages",
+			stages: [][]*ast.LabelInstruction{
+				{{LineNum: 2, Keys: []string{"version"}}},
+				{{LineNum: 5, Keys: []string{"version"}}},
+			},
+		},
+		{
+			name: "labels map without keys",
+			stages: [][]*ast.LabelInstruction{{
+				{LineNum: 2, Labels: map[string]string{"version": "1.0"}},
+				{LineNum: 3, Labels: map[string]string{"version": "2.0"}},
+			}},
+			expectedLines: []int{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{}
+			for i, labels := range tt.stages {
+				stage := ast.Stage{Index: i}
+				for _, label := range labels {
+					stage.Instructions = append(stage.Instructions, label)
+				}
+				dockerfile.Stages = append(dockerfile.Stages, stage)
+			}
+
+			findings := rule.Check(dockerfile)
+			if len(findings) != len(tt.expectedLines) {
+				t.Fatalf("expected %d findings, got %d", len(tt.expectedLines), len(findings))
+			}
+			for i, line := range tt.expectedLines {
+				if findings[i].Line != line {
+					t.Errorf("finding %d on line %d, want %d", i, findings[i].Line, line)
+				}
+			}
+		})
+	}
+
+	t.Run("message names the key and earlier line", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{{Index: 0, Instructions: []ast.Instruction{
+				&ast.LabelInstruction{LineNum: 2, Keys: []string{"version"}},
+				&ast.LabelInstruction{LineNum: 4, Keys: []string{"version"}},
+			}}},
+		}
+		findings := rule.Check(dockerfile)
+		if len(findings) != 1 || !strings.Contains(findings[0].Message, "'version'") || !strings.Contains(findings[0].Message, "line 2") {
+			t.Errorf("expected message naming 'version' and line 2, got %+v", findings)
+		}
+	})
+}
+
+func TestDeprecatedMaintainerRule(t *testing.T) {
+	rule := &DeprecatedMaintainerRule{}
+
+	tests := []struct {
+		name          string
+		instructions  []ast.Instruction
+		expectedCount int
+	}{
+		{
+			name: "MAINTAINER instruction",
+			instructions: []ast.Instruction{
+				&ast.FromInstruction{LineNum: 1, Image: "alpine", Tag: "3.19"},
+				&ast.MaintainerInstruction{LineNum: 2, Maintainer: "dev@example.com"},
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "maintainer label",
+			instructions: []ast.Instruction{
+				&ast.FromInstruction{LineNum: 1, Image: "alpine", Tag: "3.19"},
+				&ast.LabelInstruction{LineNum: 2, Labels: map[string]string{"maintainer": "dev@example.com"}},
+			},
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{Instructions: tt.instructions}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.expectedCount > 0 && !strings.Contains(findings[0].Suggestion, `LABEL maintainer="dev@example.com"`) {
+				t.Errorf("unexpected suggestion: %q", findings[0].Suggestion)
+			}
+		})
+	}
+}
+
+func TestInvalidStopSignalRule(t *testing.T) {
+	rule := &InvalidStopSignalRule{}
+
+	tests := []struct {
+		name          string
+		signal        string
+		expectedCount int
+	}{
+		{"SIGTERM", "SIGTERM", 0},
+		{"signal number", "15", 0},
+		{"name without SIG prefix", "TERM", 0},
+		{"lowercase name", "sigquit", 0},
+		{"real-time signal", "SIGRTMIN+3", 0},
+		{"variable", "${STOP_SIGNAL}", 0},
+		{"unknown name", "SIGMADEUP", 1},
+		{"unknown name without prefix", "FOO", 1},
+		{"signal number zero", "0", 1},
+		{"signal number above range", "65", 1},
+		{"negative number", "-9", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.FromInstruction{LineNum: 1, Image: "alpine", Tag: "3.18"},
+					&ast.StopsignalInstruction{LineNum: 2, Signal: tt.signal},
+				},
+			}
+
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			for _, f := range findings {
+				if f.Severity != ast.SeverityError || f.Line != 2 {
+					t.Errorf("finding Severity = %s, Line = %d, want error on line 2", f.Severity, f.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyChownRule(t *testing.T) {
+	rule := &CopyChownRule{}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedLines []int
+	}{
+		{"COPY then USER", "FROM node:20-alpine\nCOPY . /app\nUSER node\n", []int{2}},
+		{"ADD then USER", "FROM alpine:3.18\nADD app.tar.gz /app/\nUSER app:app\n", []int{2}},
+		{"COPY --chown then USER", "FROM node:20-alpine\nCOPY --chown=node:node . /app\nUSER node\n", nil},
+		{"COPY with no USER", "FROM node:20-alpine\nCOPY . /app\n", nil},
+		{"COPY after USER", "FROM node:20-alpine\nUSER node\nCOPY . /app\n", nil},
+		{"COPY then USER root", "FROM alpine:3.18\nCOPY . /app\nUSER root\n", nil},
+		{"COPY then USER 0", "FROM alpine:3.18\nCOPY . /app\nUSER 0:0\n", nil},
+		{"COPY then USER from a variable", "FROM alpine:3.18\nARG APP_USER=app\nCOPY . /app\nUSER $APP_USER\n", nil},
+		{"COPY --from then USER", "FROM golang:1.22 AS build\nFROM alpine:3.18\nCOPY --from=build /out/app /app\nUSER nobody\n", nil},
+		{"USER in a later stage", "FROM alpine:3.18 AS build\nCOPY . /src\nFROM alpine:3.18\nUSER nobody\n", nil},
+		{"USER root then non-root", "FROM alpine:3.18\nCOPY a /a\nUSER root\nCOPY b /b\nUSER app\n", []int{2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			findings := rule.Check(df)
+			if len(findings) != len(tt.expectedLines) {
+				t.Fatalf("expected %d findings, got %d", len(tt.expectedLines), len(findings))
+			}
+			for i, line := range tt.expectedLines {
+				if findings[i].Line != line {
+					t.Errorf("finding %d on line %d, want %d", i, findings[i].Line, line)
+				}
+			}
+		})
+	}
+
+	t.Run("suggests the user", func(t *testing.T) {
+		df, err := parser.ParseString("FROM node:20-alpine\nCOPY . /app\nUSER node:node\n")
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+		findings := rule.Check(df)
+		if len(findings) != 1 || !strings.Contains(findings[0].Suggestion, "--chown=node:node") || !strings.Contains(findings[0].Message, "line 3") {
+			t.Errorf("unexpected findings: %+v", findings)
+		}
+	})
+}
+
+func TestCopyChownMissingRule(t *testing.T) {
+	rule := &CopyChownMissingRule{}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedLines []int
+	}{
+		{"COPY after USER nobody", "FROM alpine:3.18\nUSER nobody\nCOPY . /app\n", []int{3}},
+		{"ADD after USER", "FROM alpine:3.18\nUSER app:app\nADD app.tar.gz /app/\n", []int{3}},
+		{"COPY before USER", "FROM alpine:3.18\nCOPY . /app\nUSER nobody\n", nil},
+		{"COPY with --chown", "FROM alpine:3.18\nUSER nobody\nCOPY --chown=nobody:nobody . /app\n", nil},
+		{"COPY --from", "FROM golang:1.22 AS build\nFROM alpine:3.18\nUSER nobody\nCOPY --from=build /out/app /app\n", nil},
+		{"COPY after USER root", "FROM alpine:3.18\nUSER app\nUSER root\nCOPY . /app\n", nil},
+		{"COPY after USER from a variable", "FROM alpine:3.18\nARG APP_USER=app\nUSER $APP_USER\nCOPY . /app\n", nil},
+		{"USER does not cross stages", "FROM alpine:3.18 AS build\nUSER nobody\nFROM alpine:3.18\nCOPY . /app\n", nil},
+		{"every COPY is reported", "FROM alpine:3.18\nUSER 1000\nCOPY a /a\nCOPY b /b\n", []int{3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			findings := rule.Check(df)
+			if len(findings) != len(tt.expectedLines) {
+				t.Fatalf("expected %d findings, got %d", len(tt.expectedLines), len(findings))
+			}
+			for i, line := range tt.expectedLines {
+				if findings[i].Line != line {
+					t.Errorf("finding %d on line %d, want %d", i, findings[i].Line, line)
+				}
+				if findings[i].Severity != ast.SeverityWarning {
+					t.Errorf("finding %d severity = %v, want warning", i, findings[i].Severity)
+				}
+			}
+		})
+	}
+
+	t.Run("suggests user and group", func(t *testing.T) {
+		df, err := parser.ParseString("FROM alpine:3.18\nUSER app:staff\nCOPY . /app\n")
+		if err != nil {
+			t.Fatalf("ParseString() error = %v", err)
+		}
+		findings := rule.Check(df)
+		if len(findings) != 1 || !strings.Contains(findings[0].Suggestion, "--chown=app:staff") {
+			t.Errorf("unexpected findings: %+v", findings)
+		}
+	})
+}
+
+func TestInvalidHealthcheckDurationRule(t *testing.T) {
+	rule := &InvalidHealthcheckDurationRule{}
+
+	tests := []struct {
+		name        string
+		healthcheck string
+		want        []ast.Severity
+		wantMessage string
+	}{
+		{name: "valid durations", healthcheck: `HEALTHCHECK --interval=1m30s --timeout=10s --start-period=0s --retries=3 CMD ["true"]`},
+		{name: "no options", healthcheck: `HEALTHCHECK CMD ["true"]`},
+		{name: "NONE", healthcheck: "HEALTHCHECK NONE"},
+		{name: "zero retries uses the default", healthcheck: `HEALTHCHECK --retries=0 CMD ["true"]`},
+		{name: "timeout equal to interval", healthcheck: `HEALTHCHECK --interval=10s --timeout=10s CMD ["true"]`},
+		{name: "unit-less interval", healthcheck: `HEALTHCHECK --interval=30 CMD ["true"]`, want: []ast.Severity{ast.SeverityError}, wantMessage: "--interval=30 is not a valid duration"},
+		{name: "unit-less timeout", healthcheck: `HEALTHCHECK --timeout=5 CMD ["true"]`, want: []ast.Severity{ast.SeverityError}, wantMessage: "--timeout=5 is not a valid duration"},
+		{name: "unknown unit", healthcheck: `HEALTHCHECK --start-period=2w CMD ["true"]`, want: []ast.Severity{ast.SeverityError}, wantMessage: "--start-period=2w"},
+		{name: "retries not a number", healthcheck: `HEALTHCHECK --retries=abc CMD ["true"]`, want: []ast.Severity{ast.SeverityError}, wantMessage: "--retries=abc is not a whole number"},
+		{name: "negative retries", healthcheck: `HEALTHCHECK --retries=-1 CMD ["true"]`, want: []ast.Severity{ast.SeverityError}},
+		{name: "timeout longer than interval", healthcheck: `HEALTHCHECK --interval=10s --timeout=1m CMD ["true"]`, want: []ast.Severity{ast.SeverityWarning}, wantMessage: "timeout 1m0s is longer than its interval 10s"},
+		{name: "timeout longer than default interval", healthcheck: `HEALTHCHECK --timeout=45s CMD ["true"]`, want: []ast.Severity{ast.SeverityWarning}},
+		{name: "invalid interval is not compared", healthcheck: `HEALTHCHECK --interval=30 --timeout=1m CMD ["true"]`, want: []ast.Severity{ast.SeverityError}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString("FROM nginx:1.25-alpine\n" + tt.healthcheck + "\n")
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			findings := rule.Check(df)
+			if len(findings) != len(tt.want) {
+				t.Fatalf("expected %d findings, got %d: %+v", len(tt.want), len(findings), findings)
+			}
+			for i, severity := range tt.want {
+				if findings[i].Severity != severity || findings[i].Line != 2 {
+					t.Errorf("finding %d = %+v, want %v on line 2", i, findings[i], severity)
+				}
+			}
+			if tt.wantMessage != "" && !strings.Contains(findings[0].Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", findings[0].Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRelativeCopyDestRule(t *testing.T) {
+	rule := &RelativeCopyDestRule{}
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedLines []int
+	}{
+		{"COPY . . without WORKDIR", "FROM node:20-alpine\nCOPY . .\n", []int{2}},
+		{"ADD to relative directory", "FROM alpine:3.18\nADD app.tar.gz app/\n", []int{2}},
+		{"COPY after WORKDIR /app", "FROM node:20-alpine\nWORKDIR /app\nCOPY . .\n", nil},
+		{"COPY to an absolute destination", "FROM node:20-alpine\nCOPY . /app\n", nil},
+		{"COPY before WORKDIR", "FROM node:20-alpine\nCOPY package.json ./\nWORKDIR /app\nCOPY . .\n", []int{2}},
+		{"destination from a variable", "FROM alpine:3.18\nARG DEST=/app\nCOPY . $DEST\n", nil},
+		{"Windows destination", "FROM mcr.microsoft.com/windows/servercore:ltsc2022\nCOPY app c:/app/\n", nil},
+		{"WORKDIR does not carry to an unrelated stage", "FROM alpine:3.18 AS build\nWORKDIR /src\nFROM alpine:3.18\nCOPY --from=build /src/app app\n", []int{4}},
+		{"WORKDIR inherited from the base stage", "FROM alpine:3.18 AS base\nWORKDIR /app\nFROM base\nCOPY . .\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+
+			findings := rule.Check(df)
+			if len(findings) != len(tt.expectedLines) {
+				t.Fatalf("expected %d findings, got %d", len(tt.expectedLines), len(findings))
+			}
+			for i, line := range tt.expectedLines {
+				if findings[i].Line != line {
+					t.Errorf("finding %d on line %d, want %d", i, findings[i].Line, line)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@
 package rules
 
 import (
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/devblac/docker-lint/internal/ast"
@@ -30,6 +32,15 @@ var (
 	// pipNoCachePattern matches pip install --no-cache-dir
 	pipNoCachePattern = regexp.MustCompile(`pip[3]?\s+install\s+[^\n]*--no-cache-dir`)
 
+	// aptNoRecommendsPattern matches options that disable installing recommended packages
+	aptNoRecommendsPattern = regexp.MustCompile(`--no-install-recommends|APT::Install-Recommends=(false|0)`)
+	// aptRecommendsConfigPattern matches apt.conf entries that disable recommended packages
+	aptRecommendsConfigPattern = regexp.MustCompile(`APT::Install-Recommends\s+\\?"?(false|0)`)
+
+	// packageUpgradePattern matches apt-get upgrade, apt-get dist-upgrade and
+	// apk upgrade commands, but not package names such as upgrade-tool
+	packageUpgradePattern = regexp.MustCompile(`\b(apt-get|apk)\s+(?:-\S+\s+)*(dist-upgrade|upgrade)(?:[\s;&|)]|$)`)
+
 	// Package update patterns (without install in same command)
 	aptGetUpdatePattern = regexp.MustCompile(`apt-get\s+update`)
 	yumUpdatePattern    = regexp.MustCompile(`(yum|dnf)\s+(update|upgrade)`)
@@ -41,11 +52,39 @@ type CacheNotCleanedRule struct{}
 func (r *CacheNotCleanedRule) ID() string             { return RuleCacheNotCleaned }
 func (r *CacheNotCleanedRule) Name() string           { return "Package manager cache not cleaned" }
 func (r *CacheNotCleanedRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *CacheNotCleanedRule) Category() string       { return CategoryPerformance }
 
 func (r *CacheNotCleanedRule) Description() string {
 	return "Clean package manager cache in the same RUN instruction to reduce image size"
 }
 
+func (r *CacheNotCleanedRule) LongDescription() string {
+	return "Package managers keep downloaded package lists and archives in a cache. Files left there in a RUN layer stay in the image, even if a later RUN deletes them.\n\n" +
+		"Clean the cache in the same RUN instruction that installs the packages, or use the options that disable the cache (apk add --no-cache, pip install --no-cache-dir). This rule can be fixed automatically with --fix."
+}
+
+func (r *CacheNotCleanedRule) BadExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends curl"
+}
+
+func (r *CacheNotCleanedRule) GoodExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends curl \\\n" +
+		"    && rm -rf /var/lib/apt/lists/*"
+}
+
+func (r *CacheNotCleanedRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+		"https://docs.docker.com/build/cache/",
+	}
+}
+
+func (r *CacheNotCleanedRule) DocURL() string {
+	return "https://github.com/hadolint/hadolint/wiki/DL3009"
+}
+
 func (r *CacheNotCleanedRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
@@ -112,24 +151,74 @@ func (r *CacheNotCleanedRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	return findings
 }
 
+// Fix cleans the package manager cache at the end of the RUN instruction, or
+// disables the pip cache. Heredoc RUNs are left alone.
+func (r *CacheNotCleanedRule) Fix(finding ast.Finding, dockerfile *ast.Dockerfile) ([]Fix, error) {
+	run, ok := instructionAt(dockerfile, finding.Line).(*ast.RunInstruction)
+	if !ok || run.IsHeredoc {
+		return nil, nil
+	}
+
+	cmd := run.Command
+	line := run.Line()
+	switch {
+	case aptGetInstallPattern.MatchString(cmd) && !aptGetCleanPattern.MatchString(cmd):
+		return []Fix{{Line: line, NewText: " && rm -rf /var/lib/apt/lists/*"}}, nil
+	case yumInstallPattern.MatchString(cmd) && !yumCleanPattern.MatchString(cmd):
+		manager := yumInstallPattern.FindStringSubmatch(cmd)[1]
+		return []Fix{{Line: line, NewText: " && " + manager + " clean all"}}, nil
+	case apkAddPattern.MatchString(cmd) && !apkNoCachePattern.MatchString(cmd):
+		return []Fix{{Line: line, NewText: " && rm -rf /var/cache/apk/*"}}, nil
+	case pipInstallPattern.MatchString(cmd) && !pipNoCachePattern.MatchString(cmd):
+		install := pipInstallPattern.FindString(cmd)
+		return []Fix{{Line: line, OldText: install, NewText: install + " --no-cache-dir"}}, nil
+	}
+	return nil, nil
+}
+
 // ConsecutiveRunRule checks for consecutive RUN instructions that could be combined (DL3010).
 type ConsecutiveRunRule struct{}
 
 func (r *ConsecutiveRunRule) ID() string             { return RuleConsecutiveRun }
 func (r *ConsecutiveRunRule) Name() string           { return "Consecutive RUN instructions" }
 func (r *ConsecutiveRunRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *ConsecutiveRunRule) Category() string       { return CategoryPerformance }
 
 func (r *ConsecutiveRunRule) Description() string {
 	return "Combine consecutive RUN instructions to reduce the number of layers"
 }
 
+func (r *ConsecutiveRunRule) LongDescription() string {
+	return "Each RUN instruction creates a new layer. Consecutive RUN instructions add layers and metadata, and files deleted in a later RUN still take space in the earlier layer.\n\n" +
+		"Chain related commands with && in a single RUN instruction."
+}
+
+func (r *ConsecutiveRunRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"RUN apk add --no-cache git\n" +
+		"RUN git config --global advice.detachedHead false"
+}
+
+func (r *ConsecutiveRunRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"RUN apk add --no-cache git \\\n" +
+		"    && git config --global advice.detachedHead false"
+}
+
+func (r *ConsecutiveRunRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+		"https://docs.docker.com/build/cache/",
+	}
+}
+
 func (r *ConsecutiveRunRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
 	// Track consecutive RUN instructions
 	var consecutiveRuns []*ast.RunInstruction
 
-	for _, instr := range dockerfile.Instructions {
+	ast.WalkFunc(dockerfile, func(instr ast.Instruction) bool {
 		run, ok := instr.(*ast.RunInstruction)
 		if ok {
 			consecutiveRuns = append(consecutiveRuns, run)
@@ -148,7 +237,8 @@ func (r *ConsecutiveRunRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 			}
 			consecutiveRuns = nil
 		}
-	}
+		return true
+	})
 
 	// Check for trailing consecutive RUNs
 	if len(consecutiveRuns) >= 2 {
@@ -169,6 +259,121 @@ func formatConsecutiveRunMessage(count int) string {
 	return "Found " + intToString(count) + " consecutive RUN instructions that could be combined"
 }
 
+// ConsecutiveEnvRule checks for consecutive ENV instructions that could be combined (DL3036).
+type ConsecutiveEnvRule struct{}
+
+func (r *ConsecutiveEnvRule) ID() string             { return RuleConsecutiveEnv }
+func (r *ConsecutiveEnvRule) Name() string           { return "Consecutive ENV instructions" }
+func (r *ConsecutiveEnvRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *ConsecutiveEnvRule) Category() string       { return CategoryBestPractice }
+
+func (r *ConsecutiveEnvRule) Description() string {
+	return "Combine consecutive ENV instructions into a single instruction"
+}
+
+func (r *ConsecutiveEnvRule) LongDescription() string {
+	return "Each ENV instruction adds an entry to the image history. Back-to-back ENV instructions clutter the Dockerfile and the history without any benefit.\n\n" +
+		"Set several variables in one instruction with ENV k1=v1 k2=v2. ENV instructions whose values reference a variable set by the previous ones are not reported, since a single ENV expands references using the values from before the instruction."
+}
+
+func (r *ConsecutiveEnvRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"ENV APP_HOME=/app\n" +
+		"ENV APP_PORT=8080"
+}
+
+func (r *ConsecutiveEnvRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"ENV APP_HOME=/app APP_PORT=8080"
+}
+
+func (r *ConsecutiveEnvRule) References() []string {
+	return []string{
+		"https://docs.docker.com/reference/dockerfile/#env",
+	}
+}
+
+func (r *ConsecutiveEnvRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Track consecutive ENV instructions and the keys they set
+	var consecutiveEnvs []*ast.EnvInstruction
+	keys := make(map[string]bool)
+
+	flush := func() {
+		if len(consecutiveEnvs) >= 2 {
+			// Report finding at the first ENV of the consecutive sequence
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       consecutiveEnvs[0].Line(),
+				Column:     1,
+				Message:    "Found " + intToString(len(consecutiveEnvs)) + " consecutive ENV instructions that could be combined",
+				Suggestion: "Combine them into a single instruction: " + combinedEnv(consecutiveEnvs),
+			})
+		}
+		consecutiveEnvs = nil
+		keys = make(map[string]bool)
+	}
+
+	ast.WalkFunc(dockerfile, func(instr ast.Instruction) bool {
+		env, ok := instr.(*ast.EnvInstruction)
+		if !ok {
+			// Non-ENV instruction breaks the sequence
+			flush()
+			return true
+		}
+
+		// An ENV that uses a variable set earlier in the sequence would see
+		// the old value once merged, so it starts a new sequence
+		if referencesKeys(env, keys) {
+			flush()
+		}
+		consecutiveEnvs = append(consecutiveEnvs, env)
+		for _, pair := range env.AllPairs() {
+			keys[pair.Key] = true
+		}
+		return true
+	})
+
+	// Check for trailing consecutive ENVs
+	flush()
+
+	return findings
+}
+
+// referencesKeys reports whether any value of an ENV instruction references
+// one of the given variables.
+func referencesKeys(env *ast.EnvInstruction, keys map[string]bool) bool {
+	for _, pair := range env.AllPairs() {
+		for _, match := range variablePattern.FindAllStringSubmatch(pair.Value, -1) {
+			if keys[match[1]] || keys[match[3]] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// combinedEnv returns a single ENV instruction setting all pairs of the given
+// instructions.
+func combinedEnv(envs []*ast.EnvInstruction) string {
+	parts := []string{"ENV"}
+	for _, env := range envs {
+		for _, pair := range env.AllPairs() {
+			// Values keep their quotes from the source, so only unquoted
+			// values need quoting
+			value := pair.Value
+			quoted := strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "'")
+			if !quoted && (value == "" || strings.ContainsAny(value, " \t")) {
+				value = strconv.Quote(value)
+			}
+			parts = append(parts, pair.Key+"="+value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // intToString converts an int to string without importing strconv
 func intToString(n int) string {
 	if n == 0 {
@@ -191,11 +396,38 @@ type SuboptimalOrderingRule struct{}
 func (r *SuboptimalOrderingRule) ID() string             { return RuleSuboptimalOrdering }
 func (r *SuboptimalOrderingRule) Name() string           { return "Suboptimal layer ordering" }
 func (r *SuboptimalOrderingRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *SuboptimalOrderingRule) Category() string       { return CategoryPerformance }
 
 func (r *SuboptimalOrderingRule) Description() string {
 	return "Place instructions that change less frequently earlier to optimize layer caching"
 }
 
+func (r *SuboptimalOrderingRule) LongDescription() string {
+	return "Docker reuses cached layers until the first instruction whose inputs changed. Copying source files before installing packages means every source change invalidates the install layer, and the packages are downloaded again.\n\n" +
+		"Install packages before copying files that change often, and copy dependency manifests on their own first."
+}
+
+func (r *SuboptimalOrderingRule) BadExample() string {
+	return "FROM python:3.12-slim\n" +
+		"COPY app.py /app/\n" +
+		"RUN pip install --no-cache-dir flask==3.0.0\n" +
+		"COPY static /app/static"
+}
+
+func (r *SuboptimalOrderingRule) GoodExample() string {
+	return "FROM python:3.12-slim\n" +
+		"RUN pip install --no-cache-dir flask==3.0.0\n" +
+		"COPY app.py /app/\n" +
+		"COPY static /app/static"
+}
+
+func (r *SuboptimalOrderingRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/cache/",
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
 func (r *SuboptimalOrderingRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
@@ -307,17 +539,140 @@ func isPackageFile(dest string) bool {
 	return false
 }
 
+// CopyAllBeforeInstallRule checks for COPY of the whole build context before a
+// package install in the same stage (DL3017).
+type CopyAllBeforeInstallRule struct{}
+
+func (r *CopyAllBeforeInstallRule) ID() string             { return RuleCopyAllBeforeInstall }
+func (r *CopyAllBeforeInstallRule) Name() string           { return "COPY . before package install" }
+func (r *CopyAllBeforeInstallRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *CopyAllBeforeInstallRule) Category() string       { return CategoryPerformance }
+
+func (r *CopyAllBeforeInstallRule) Description() string {
+	return "Copy dependency manifests and install packages before COPY . . so source changes do not invalidate the install layer"
+}
+
+func (r *CopyAllBeforeInstallRule) LongDescription() string {
+	return "COPY . . copies the whole build context, so any source change invalidates its layer and every layer after it. A package install that follows it is repeated on every build.\n\n" +
+		"Copy the dependency manifests first, install the packages, then copy the rest of the source."
+}
+
+func (r *CopyAllBeforeInstallRule) BadExample() string {
+	return "FROM python:3.12-slim\n" +
+		"WORKDIR /app\n" +
+		"COPY . .\n" +
+		"RUN pip install --no-cache-dir -r requirements.txt"
+}
+
+func (r *CopyAllBeforeInstallRule) GoodExample() string {
+	return "FROM python:3.12-slim\n" +
+		"WORKDIR /app\n" +
+		"COPY requirements.txt ./\n" +
+		"RUN pip install --no-cache-dir -r requirements.txt\n" +
+		"COPY . ."
+}
+
+func (r *CopyAllBeforeInstallRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/cache/",
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *CopyAllBeforeInstallRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, stage := range dockerfile.Stages {
+		var copyAll *ast.CopyInstruction
+
+		for _, instr := range stage.Instructions {
+			switch v := instr.(type) {
+			case *ast.CopyInstruction:
+				if copyAll == nil && v.From == "" && copiesBuildContext(v.Sources) {
+					copyAll = v
+				}
+			case *ast.RunInstruction:
+				if copyAll == nil || !isPackageInstallCommand(v.Command) {
+					continue
+				}
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       copyAll.Line(),
+					Column:     1,
+					Message:    "COPY of the build context before the package install on line " + strconv.Itoa(v.Line()) + " invalidates the install cache on every source change",
+					Suggestion: installOrderingSuggestion(v.Command),
+				})
+				copyAll = nil // Report each COPY once
+			}
+		}
+	}
+
+	return findings
+}
+
+// copiesBuildContext checks if COPY sources include the whole build context.
+func copiesBuildContext(sources []string) bool {
+	for _, source := range sources {
+		if source == "." || source == "./" {
+			return true
+		}
+	}
+	return false
+}
+
+// installOrderingSuggestion returns the cache-friendly instruction order for
+// the package manager used in an install command.
+func installOrderingSuggestion(cmd string) string {
+	switch {
+	case strings.Contains(cmd, "npm install"):
+		return "COPY package.json package-lock.json ./, then RUN npm ci, then COPY . ."
+	case strings.Contains(cmd, "yarn install"):
+		return "COPY package.json yarn.lock ./, then RUN yarn install --frozen-lockfile, then COPY . ."
+	case pipInstallPattern.MatchString(cmd):
+		return "COPY requirements.txt ./, then RUN pip install -r requirements.txt, then COPY . ."
+	case strings.Contains(cmd, "go mod download"):
+		return "COPY go.mod go.sum ./, then RUN go mod download, then COPY . ."
+	default:
+		return "Install system packages before COPY . . so they are cached independently of the source"
+	}
+}
+
 // UpdateWithoutInstallRule checks for package update without install in same command (DL3012).
 type UpdateWithoutInstallRule struct{}
 
 func (r *UpdateWithoutInstallRule) ID() string             { return RuleUpdateWithoutInstall }
 func (r *UpdateWithoutInstallRule) Name() string           { return "Package update without install" }
 func (r *UpdateWithoutInstallRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *UpdateWithoutInstallRule) Category() string       { return CategoryCorrectness }
 
 func (r *UpdateWithoutInstallRule) Description() string {
 	return "Combine package update with install in the same RUN instruction to avoid cache issues"
 }
 
+func (r *UpdateWithoutInstallRule) LongDescription() string {
+	return "A RUN that only updates the package lists is cached on its own. Later builds reuse the stale lists from the cache and then fail, or install outdated packages, when the install RUN changes.\n\n" +
+		"Update and install in the same RUN instruction."
+}
+
+func (r *UpdateWithoutInstallRule) BadExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update\n" +
+		"RUN apt-get install -y --no-install-recommends curl"
+}
+
+func (r *UpdateWithoutInstallRule) GoodExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends curl \\\n" +
+		"    && rm -rf /var/lib/apt/lists/*"
+}
+
+func (r *UpdateWithoutInstallRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
 func (r *UpdateWithoutInstallRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
 	var findings []ast.Finding
 
@@ -362,10 +717,1076 @@ func (r *UpdateWithoutInstallRule) Check(dockerfile *ast.Dockerfile) []ast.Findi
 	return findings
 }
 
+// AptGetNoRecommendsRule checks for apt-get install without --no-install-recommends (DL3013).
+type AptGetNoRecommendsRule struct{}
+
+func (r *AptGetNoRecommendsRule) ID() string             { return RuleAptNoRecommends }
+func (r *AptGetNoRecommendsRule) Name() string           { return "Missing --no-install-recommends" }
+func (r *AptGetNoRecommendsRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *AptGetNoRecommendsRule) Category() string       { return CategoryPerformance }
+
+func (r *AptGetNoRecommendsRule) Description() string {
+	return "Use --no-install-recommends with apt-get to avoid installing unnecessary packages"
+}
+
+func (r *AptGetNoRecommendsRule) LongDescription() string {
+	return "By default apt-get install also installs the recommended packages of everything it installs. They are rarely needed in a container and can add hundreds of megabytes to the image.\n\n" +
+		"Pass --no-install-recommends and list the packages you need explicitly."
+}
+
+func (r *AptGetNoRecommendsRule) BadExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y curl && rm -rf /var/lib/apt/lists/*"
+}
+
+func (r *AptGetNoRecommendsRule) GoodExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends curl \\\n" +
+		"    && rm -rf /var/lib/apt/lists/*"
+}
+
+func (r *AptGetNoRecommendsRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *AptGetNoRecommendsRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Check each stage separately since apt configuration does not carry across stages
+	for _, stage := range dockerfile.Stages {
+		recommendsDisabled := false
+
+		for _, instr := range stage.Instructions {
+			run, ok := instr.(*ast.RunInstruction)
+			if !ok {
+				continue
+			}
+
+			// Recommends disabled globally via apt.conf apply to all later installs
+			if aptRecommendsConfigPattern.MatchString(run.Command) {
+				recommendsDisabled = true
+			}
+			if recommendsDisabled {
+				continue
+			}
+
+			for _, segment := range splitShellCommands(run.Command) {
+				if !aptGetInstallPattern.MatchString(segment) || aptNoRecommendsPattern.MatchString(segment) {
+					continue
+				}
+
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       run.Line(),
+					Column:     1,
+					Message:    "apt-get install without --no-install-recommends installs unnecessary packages",
+					Suggestion: "Add '--no-install-recommends' to the apt-get install command",
+				})
+				break // Only report once per RUN instruction
+			}
+		}
+	}
+
+	return findings
+}
+
+// AptGetUpgradeRule checks for apt-get upgrade, apt-get dist-upgrade or apk
+// upgrade in RUN instructions (DL3014).
+type AptGetUpgradeRule struct{}
+
+func (r *AptGetUpgradeRule) ID() string             { return RuleAptGetUpgrade }
+func (r *AptGetUpgradeRule) Name() string           { return "Package upgrade in RUN" }
+func (r *AptGetUpgradeRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *AptGetUpgradeRule) Category() string       { return CategoryBestPractice }
+
+func (r *AptGetUpgradeRule) Description() string {
+	return "Avoid apt-get upgrade and apk upgrade; the upgraded packages depend on the package index at build time"
+}
+
+func (r *AptGetUpgradeRule) LongDescription() string {
+	return "apt-get upgrade, apt-get dist-upgrade and apk upgrade update every package in the base image to whatever is current when the build runs. The result depends on the day of the build rather than on the Dockerfile, and upgrades inside an unprivileged container can fail.\n\n" +
+		"Rely on an up-to-date base image to get updates, and upgrade only the specific packages you need."
+}
+
+func (r *AptGetUpgradeRule) BadExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get upgrade -y"
+}
+
+func (r *AptGetUpgradeRule) GoodExample() string {
+	return "FROM debian:12.5-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends --only-upgrade openssl \\\n" +
+		"    && rm -rf /var/lib/apt/lists/*"
+}
+
+func (r *AptGetUpgradeRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *AptGetUpgradeRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		run, ok := instr.(*ast.RunInstruction)
+		if !ok {
+			continue
+		}
+
+		// apt-get update alone is handled by DL3012
+		match := packageUpgradePattern.FindStringSubmatch(run.Command)
+		if match == nil {
+			continue
+		}
+
+		findings = append(findings, ast.Finding{
+			RuleID:     r.ID(),
+			Severity:   r.Severity(),
+			Line:       run.Line(),
+			Column:     1,
+			Message:    match[1] + " " + match[2] + " makes builds non-reproducible",
+			Suggestion: "Rely on an up-to-date, pinned base image and install only the packages you need instead of upgrading",
+		})
+	}
+
+	return findings
+}
+
+// PinnedAptVersionRule checks for apt-get install of packages without pinned versions (DL3023).
+type PinnedAptVersionRule struct{}
+
+func (r *PinnedAptVersionRule) ID() string             { return RuleAptPinVersion }
+func (r *PinnedAptVersionRule) Name() string           { return "apt-get install without pinned versions" }
+func (r *PinnedAptVersionRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *PinnedAptVersionRule) Category() string       { return CategoryBestPractice }
+
+func (r *PinnedAptVersionRule) Description() string {
+	return "Pin package versions in apt-get install to ensure reproducible builds"
+}
+
+func (r *PinnedAptVersionRule) LongDescription() string {
+	return "apt-get install without a version installs whatever version the repository offers at build time, so rebuilds can silently pick up different packages.\n\n" +
+		"Pin versions with package=version for packages whose version matters."
+}
+
+func (r *PinnedAptVersionRule) BadExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends curl \\\n" +
+		"    && rm -rf /var/lib/apt/lists/*"
+}
+
+func (r *PinnedAptVersionRule) GoodExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends curl=7.88.1-10+deb12u5 \\\n" +
+		"    && rm -rf /var/lib/apt/lists/*"
+}
+
+func (r *PinnedAptVersionRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *PinnedAptVersionRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		run, ok := instr.(*ast.RunInstruction)
+		if !ok {
+			continue
+		}
+
+		for _, segment := range splitShellCommands(run.Command) {
+			args, ok := aptGetInstallArgs(segment)
+			if !ok {
+				continue
+			}
+
+			for _, pkg := range aptGetPackages(args) {
+				// Skip shell variables to avoid false positives
+				if strings.Contains(pkg, "$") {
+					continue
+				}
+				// Accept version pins (pkg=1.0) and release pins (pkg/bookworm)
+				if strings.Contains(pkg, "=") || strings.Contains(pkg, "/") {
+					continue
+				}
+
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       run.Line(),
+					Column:     1,
+					Message:    "Package '" + pkg + "' in apt-get install is not pinned to a version",
+					Suggestion: "Pin the package version like '" + pkg + "=<version>' for reproducible builds",
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// PinnedPipVersionRule checks for pip install of packages without pinned versions (DL3020).
+type PinnedPipVersionRule struct{}
+
+func (r *PinnedPipVersionRule) ID() string             { return RulePipPinVersion }
+func (r *PinnedPipVersionRule) Name() string           { return "pip install without pinned versions" }
+func (r *PinnedPipVersionRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *PinnedPipVersionRule) Category() string       { return CategoryBestPractice }
+
+func (r *PinnedPipVersionRule) Description() string {
+	return "Pin package versions in pip install, or install from a pinned requirements file, to ensure reproducible builds"
+}
+
+func (r *PinnedPipVersionRule) LongDescription() string {
+	return "pip install without a version installs the newest release available at build time. A new release of a dependency can then break a rebuild of an unchanged Dockerfile.\n\n" +
+		"Pin versions with ==, or install from a requirements file or constraints file that pins them."
+}
+
+func (r *PinnedPipVersionRule) BadExample() string {
+	return "FROM python:3.12-slim\n" +
+		"RUN pip install --no-cache-dir flask"
+}
+
+func (r *PinnedPipVersionRule) GoodExample() string {
+	return "FROM python:3.12-slim\n" +
+		"RUN pip install --no-cache-dir flask==3.0.0"
+}
+
+func (r *PinnedPipVersionRule) References() []string {
+	return []string{
+		"https://pip.pypa.io/en/stable/topics/repeatable-installs/",
+	}
+}
+
+func (r *PinnedPipVersionRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		run, ok := instr.(*ast.RunInstruction)
+		if !ok {
+			continue
+		}
+
+		for _, segment := range splitShellCommands(run.Command) {
+			args, ok := pipInstallArgs(segment)
+			if !ok {
+				continue
+			}
+
+			for _, pkg := range pipPackages(args) {
+				if isPinnedPipPackage(pkg) {
+					continue
+				}
+
+				findings = append(findings, ast.Finding{
+					RuleID:     r.ID(),
+					Severity:   r.Severity(),
+					Line:       run.Line(),
+					Column:     1,
+					Message:    "Package '" + pkg + "' in pip install is not pinned to a version",
+					Suggestion: "Pin the package version like '" + pkg + "==<version>' (see pip freeze) or install from a pinned requirements file",
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// AptGetMissingYesRule checks for apt-get install without non-interactive confirmation (DL3024).
+type AptGetMissingYesRule struct{}
+
+func (r *AptGetMissingYesRule) ID() string             { return RuleAptGetMissingYes }
+func (r *AptGetMissingYesRule) Name() string           { return "apt-get install without -y" }
+func (r *AptGetMissingYesRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *AptGetMissingYesRule) Category() string       { return CategoryCorrectness }
+
+func (r *AptGetMissingYesRule) Description() string {
+	return "Use apt-get install -y to avoid the build waiting for interactive confirmation"
+}
+
+func (r *AptGetMissingYesRule) LongDescription() string {
+	return "apt-get install asks for confirmation before installing packages with dependencies. A build has no terminal to answer, so the install is aborted and the build fails.\n\n" +
+		"Pass -y (or --yes, --assume-yes) to answer the prompt."
+}
+
+func (r *AptGetMissingYesRule) BadExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install --no-install-recommends curl"
+}
+
+func (r *AptGetMissingYesRule) GoodExample() string {
+	return "FROM debian:12-slim\n" +
+		"RUN apt-get update && apt-get install -y --no-install-recommends curl"
+}
+
+func (r *AptGetMissingYesRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/best-practices/",
+	}
+}
+
+func (r *AptGetMissingYesRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, instr := range dockerfile.Instructions {
+		run, ok := instr.(*ast.RunInstruction)
+		if !ok {
+			continue
+		}
+
+		// Only the install command itself is inspected, so a '-y' belonging to
+		// another command in the same RUN does not count
+		for _, segment := range splitShellCommands(run.Command) {
+			args, ok := aptGetInstallArgs(segment)
+			if !ok || hasAptYesFlag(args) {
+				continue
+			}
+
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       run.Line(),
+				Column:     1,
+				Message:    "apt-get install without -y may hang waiting for confirmation",
+				Suggestion: "Use 'apt-get install -y' to run non-interactively",
+			})
+			break // Only report once per RUN instruction
+		}
+	}
+
+	return findings
+}
+
+// hasAptYesFlag checks if apt-get arguments include an assume-yes option.
+func hasAptYesFlag(args []string) bool {
+	for _, arg := range args {
+		switch {
+		case arg == "--yes" || arg == "--assume-yes" || arg == "-qq":
+			// Quiet level 2 implies -y
+			return true
+		case strings.HasPrefix(arg, "--"):
+			continue
+		case strings.HasPrefix(arg, "-") && strings.Contains(arg, "y"):
+			// Short flags may be combined, e.g. -yq
+			return true
+		}
+	}
+	return false
+}
+
+// splitShellCommands splits a shell command line into its individual commands,
+// breaking on '&&', '||', ';' and '|' outside of quotes.
+func splitShellCommands(cmd string) []string {
+	var segments []string
+	var current strings.Builder
+	inDoubleQuote := false
+	inSingleQuote := false
+
+	flush := func() {
+		segment := strings.TrimSpace(current.String())
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(cmd); i++ {
+		ch := cmd[i]
+
+		switch {
+		case ch == '"' && !inSingleQuote:
+			inDoubleQuote = !inDoubleQuote
+		case ch == '\'' && !inDoubleQuote:
+			inSingleQuote = !inSingleQuote
+		case inDoubleQuote || inSingleQuote:
+			// Quoted content is kept as-is
+		case ch == ';':
+			flush()
+			continue
+		case ch == '&' && i+1 < len(cmd) && cmd[i+1] == '&':
+			flush()
+			i++
+			continue
+		case ch == '|':
+			flush()
+			if i+1 < len(cmd) && cmd[i+1] == '|' {
+				i++
+			}
+			continue
+		}
+
+		current.WriteByte(ch)
+	}
+	flush()
+
+	return segments
+}
+
+// aptGetInstallArgs returns the arguments following 'apt-get install' in a single
+// shell command. Options given between apt-get and install are included.
+func aptGetInstallArgs(segment string) ([]string, bool) {
+	fields := strings.Fields(segment)
+
+	for i, field := range fields {
+		if field != "apt-get" {
+			continue
+		}
+
+		var options []string
+		for j := i + 1; j < len(fields); j++ {
+			if fields[j] == "install" {
+				return append(options, fields[j+1:]...), true
+			}
+			if !strings.HasPrefix(fields[j], "-") {
+				break
+			}
+			options = append(options, fields[j])
+			// -o and -t take a separate value argument
+			if (fields[j] == "-o" || fields[j] == "-t") && j+1 < len(fields) {
+				j++
+				options = append(options, fields[j])
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// aptGetPackages extracts package names from apt-get install arguments,
+// skipping options and shell redirections.
+func aptGetPackages(args []string) []string {
+	var packages []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if strings.ContainsAny(arg, "<>") {
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			// -o and -t take a separate value argument
+			if (arg == "-o" || arg == "-t") && i+1 < len(args) {
+				i++
+			}
+			continue
+		}
+
+		packages = append(packages, arg)
+	}
+
+	return packages
+}
+
+// pipCommandPattern matches pip executables such as pip, pip3 and /usr/bin/pip3.11.
+var pipCommandPattern = regexp.MustCompile(`^(?:\S*/)?pip(?:[0-9.]*)$`)
+
+// pipValueFlags are the pip install options that take a separate value argument.
+var pipValueFlags = map[string]bool{
+	"-r":                true,
+	"--requirement":     true,
+	"-c":                true,
+	"--constraint":      true,
+	"-e":                true,
+	"--editable":        true,
+	"-i":                true,
+	"--index-url":       true,
+	"--extra-index-url": true,
+	"-f":                true,
+	"--find-links":      true,
+	"-t":                true,
+	"--target":          true,
+	"--prefix":          true,
+	"--root":            true,
+	"--src":             true,
+	"--trusted-host":    true,
+	"--platform":        true,
+	"--python-version":  true,
+	"--implementation":  true,
+	"--abi":             true,
+}
+
+// pipInstallArgs returns the arguments following 'pip install' in a single shell
+// command. Both 'pip install' and 'python -m pip install' are recognized.
+func pipInstallArgs(segment string) ([]string, bool) {
+	fields := strings.Fields(segment)
+
+	for i, field := range fields {
+		isPip := pipCommandPattern.MatchString(field) ||
+			(field == "pip" && i > 0 && fields[i-1] == "-m")
+		if isPip && i+1 < len(fields) && fields[i+1] == "install" {
+			return fields[i+2:], true
+		}
+	}
+
+	return nil, false
+}
+
+// pipPackages extracts the package requirements from pip install arguments.
+// Options, requirement and constraint files, local paths, URLs, archives and
+// shell variables are skipped.
+func pipPackages(args []string) []string {
+	var packages []string
+
+	for i := 0; i < len(args); i++ {
+		arg := strings.Trim(args[i], `"'`)
+
+		if strings.HasPrefix(arg, "-") {
+			if pipValueFlags[arg] && i+1 < len(args) {
+				i++
+			}
+			continue
+		}
+		if arg == "" || strings.Contains(arg, "$") || strings.ContainsAny(arg, "/\\") ||
+			strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "~") || isArchiveFile(arg) ||
+			strings.HasSuffix(arg, ".whl") {
+			continue
+		}
+
+		packages = append(packages, arg)
+	}
+
+	return packages
+}
+
+// isPinnedPipPackage checks if a pip requirement carries a version specifier
+// or a direct reference.
+func isPinnedPipPackage(pkg string) bool {
+	return strings.ContainsAny(pkg, "=<>@")
+}
+
+// npmLockFiles are the lock files that make npm ci usable.
+var npmLockFiles = []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock"}
+
+// NpmCiRule checks for npm install after a lock file has been copied (DL3016).
+type NpmCiRule struct{}
+
+func (r *NpmCiRule) ID() string             { return RuleNpmCi }
+func (r *NpmCiRule) Name() string           { return "npm install instead of npm ci" }
+func (r *NpmCiRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *NpmCiRule) Category() string       { return CategoryBestPractice }
+
+func (r *NpmCiRule) Description() string {
+	return "Use npm ci when a lock file is present; npm install may update the lock file and produce different dependencies"
+}
+
+func (r *NpmCiRule) LongDescription() string {
+	return "npm install resolves dependency ranges and may update package-lock.json, so the installed versions can differ from the ones you tested. npm ci installs exactly what the lock file specifies and fails if it is out of date."
+}
+
+func (r *NpmCiRule) BadExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY package.json package-lock.json ./\n" +
+		"RUN npm install"
+}
+
+func (r *NpmCiRule) GoodExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY package.json package-lock.json ./\n" +
+		"RUN npm ci"
+}
+
+func (r *NpmCiRule) References() []string {
+	return []string{
+		"https://docs.npmjs.com/cli/commands/npm-ci",
+	}
+}
+
+func (r *NpmCiRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	// Check each stage separately; copied files do not carry over
+	for _, stage := range dockerfile.Stages {
+		lockFileCopied := false
+
+		for _, instr := range stage.Instructions {
+			switch v := instr.(type) {
+			case *ast.CopyInstruction:
+				if copiesLockFile(v.Sources) {
+					lockFileCopied = true
+				}
+
+			case *ast.RunInstruction:
+				if !lockFileCopied {
+					continue
+				}
+				for _, segment := range splitShellCommands(v.Command) {
+					if isBareNpmInstall(segment) {
+						findings = append(findings, ast.Finding{
+							RuleID:     r.ID(),
+							Severity:   r.Severity(),
+							Line:       v.Line(),
+							Column:     1,
+							Message:    "npm install used although a lock file was copied",
+							Suggestion: "Use 'npm ci' to install exactly the versions in the lock file for reproducible builds",
+						})
+						break // Only report once per RUN instruction
+					}
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// copiesLockFile reports whether any COPY source names or matches a lock file.
+func copiesLockFile(sources []string) bool {
+	for _, source := range sources {
+		base := filepath.Base(source)
+		for _, lockFile := range npmLockFiles {
+			if matched, _ := filepath.Match(base, lockFile); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isBareNpmInstall reports whether a shell command runs npm install without
+// naming packages, i.e. installs from package.json. Installing specific
+// packages (npm install --save-dev jest) is not affected by npm ci.
+func isBareNpmInstall(segment string) bool {
+	fields := strings.Fields(segment)
+
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] != "npm" || (fields[i+1] != "install" && fields[i+1] != "i") {
+			continue
+		}
+		for _, arg := range fields[i+2:] {
+			if !strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, ">") && !strings.HasPrefix(arg, "2>") {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// NpmProductionRule checks for npm install of devDependencies in the final stage (DL3031).
+type NpmProductionRule struct{}
+
+func (r *NpmProductionRule) ID() string             { return RuleNpmProduction }
+func (r *NpmProductionRule) Name() string           { return "npm install with devDependencies" }
+func (r *NpmProductionRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *NpmProductionRule) Category() string       { return CategoryPerformance }
+
+func (r *NpmProductionRule) Description() string {
+	return "npm install in the final stage installs devDependencies unless --omit=dev, --production " +
+		"or NODE_ENV=production is used; builder stages are not checked"
+}
+
+func (r *NpmProductionRule) LongDescription() string {
+	return "npm install installs devDependencies unless it is told otherwise. Test frameworks, bundlers and linters then ship in the final image, making it larger and adding packages that can have vulnerabilities.\n\n" +
+		"Use --omit=dev or set NODE_ENV=production in the final stage, and build with devDependencies in a separate stage."
+}
+
+func (r *NpmProductionRule) BadExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY package.json ./\n" +
+		"RUN npm install"
+}
+
+func (r *NpmProductionRule) GoodExample() string {
+	return "FROM node:20-alpine\n" +
+		"COPY package.json ./\n" +
+		"RUN npm install --omit=dev"
+}
+
+func (r *NpmProductionRule) References() []string {
+	return []string{
+		"https://docs.npmjs.com/cli/commands/npm-install",
+		"https://docs.docker.com/build/building/multi-stage/",
+	}
+}
+
+func (r *NpmProductionRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	if len(dockerfile.Stages) == 0 {
+		return findings
+	}
+	stage := dockerfile.Stages[len(dockerfile.Stages)-1]
+	production := false
+
+	for _, instr := range stage.Instructions {
+		switch v := instr.(type) {
+		case *ast.EnvInstruction:
+			if value, ok := envValue(v, "NODE_ENV"); ok {
+				production = value == "production"
+			}
+
+		case *ast.RunInstruction:
+			if production {
+				continue
+			}
+			for _, segment := range splitShellCommands(v.Command) {
+				if installsNpmDevDependencies(segment) {
+					findings = append(findings, ast.Finding{
+						RuleID:     r.ID(),
+						Severity:   r.Severity(),
+						Line:       v.Line(),
+						Column:     1,
+						Message:    "npm install in the final stage also installs devDependencies",
+						Suggestion: "Use 'npm ci --omit=dev', or build in a separate stage and copy only the output",
+						StageIndex: stage.Index,
+						StageName:  stage.Name,
+					})
+					break // Only report once per RUN instruction
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// envValue returns the value an ENV instruction assigns to key.
+func envValue(env *ast.EnvInstruction, key string) (string, bool) {
+	value, found := "", false
+	for _, pair := range env.AllPairs() {
+		if pair.Key == key {
+			value, found = strings.Trim(pair.Value, `"'`), true
+		}
+	}
+	return value, found
+}
+
+// installsNpmDevDependencies reports whether a shell command runs npm install
+// without omitting devDependencies. Global installs are not affected.
+func installsNpmDevDependencies(segment string) bool {
+	fields := strings.Fields(segment)
+
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] != "npm" || (fields[i+1] != "install" && fields[i+1] != "i") {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if fields[j] == "NODE_ENV=production" {
+				return false
+			}
+		}
+		for j, arg := range fields[i+2:] {
+			switch {
+			case arg == "-g" || arg == "--global" || arg == "--production" ||
+				arg == "--omit=dev" || arg == "--only=prod" || arg == "--only=production":
+				return false
+			case arg == "--omit" && i+3+j < len(fields) && fields[i+3+j] == "dev":
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// CopyFromUndefinedStageRule checks for COPY --from references to stages that are
+// not defined, or that are not defined before the stage containing the COPY (DL3027).
+type CopyFromUndefinedStageRule struct{}
+
+func (r *CopyFromUndefinedStageRule) ID() string             { return RuleCopyFromUndefined }
+func (r *CopyFromUndefinedStageRule) Name() string           { return "COPY --from undefined stage" }
+func (r *CopyFromUndefinedStageRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *CopyFromUndefinedStageRule) Category() string       { return CategoryCorrectness }
+
+func (r *CopyFromUndefinedStageRule) Description() string {
+	return "COPY --from must reference a stage defined earlier in the Dockerfile or an external image"
+}
+
+func (r *CopyFromUndefinedStageRule) LongDescription() string {
+	return "COPY --from must name a stage defined earlier in the Dockerfile, or an image. A misspelled stage name is treated as an image name and the build fails when the image cannot be pulled; a reference to the current or a later stage is an error."
+}
+
+func (r *CopyFromUndefinedStageRule) BadExample() string {
+	return "FROM golang:1.22-alpine AS build\n" +
+		"RUN go build -o /out/app .\n" +
+		"FROM alpine:3.18\n" +
+		"COPY --from=builder /out/app /app"
+}
+
+func (r *CopyFromUndefinedStageRule) GoodExample() string {
+	return "FROM golang:1.22-alpine AS build\n" +
+		"RUN go build -o /out/app .\n" +
+		"FROM alpine:3.18\n" +
+		"COPY --from=build /out/app /app"
+}
+
+func (r *CopyFromUndefinedStageRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/multi-stage/",
+	}
+}
+
+func (r *CopyFromUndefinedStageRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, stage := range dockerfile.Stages {
+		for _, instr := range stage.Instructions {
+			cp, ok := instr.(*ast.CopyInstruction)
+			if !ok || cp.From == "" || isExternalImageRef(cp.From) {
+				continue
+			}
+
+			target, err := strconv.Atoi(cp.From)
+			if err != nil {
+				named, found := dockerfile.StageByName(cp.From)
+				if !found && isKnownImageName(cp.From) {
+					continue // External image such as --from=nginx
+				}
+				if !found {
+					findings = append(findings, ast.Finding{
+						RuleID:     r.ID(),
+						Severity:   r.Severity(),
+						Line:       cp.Line(),
+						Column:     1,
+						Message:    "COPY --from=" + cp.From + " references an undefined stage",
+						Suggestion: "Check the stage name for typos; for an external image use a full reference like '" + cp.From + ":<tag>'",
+					})
+					continue
+				}
+				target = named.Index
+			}
+
+			var message string
+			switch {
+			case target >= 0 && target < stage.Index:
+				continue
+			case target == stage.Index:
+				message = "COPY --from=" + cp.From + " refers to the current stage " + stageLabel(stage)
+			case target > stage.Index && target < len(dockerfile.Stages):
+				message = "COPY --from=" + cp.From + " refers to later stage " + stageLabel(dockerfile.Stages[target])
+			default:
+				message = "COPY --from=" + cp.From + " does not refer to an existing stage"
+			}
+
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       cp.Line(),
+				Column:     1,
+				Message:    message,
+				Suggestion: "Use the index or name of a stage defined before this one",
+			})
+		}
+	}
+
+	return findings
+}
+
+// CopyFromUntaggedImageRule checks for COPY --from external images without a
+// tag or digest (DL3032).
+type CopyFromUntaggedImageRule struct{}
+
+func (r *CopyFromUntaggedImageRule) ID() string             { return RuleCopyFromUntagged }
+func (r *CopyFromUntaggedImageRule) Name() string           { return "COPY --from image without tag" }
+func (r *CopyFromUntaggedImageRule) Severity() ast.Severity { return ast.SeverityWarning }
+func (r *CopyFromUntaggedImageRule) Category() string       { return CategoryBestPractice }
+
+func (r *CopyFromUntaggedImageRule) Description() string {
+	return "Tag images used in COPY --from explicitly; without a tag they default to 'latest'"
+}
+
+func (r *CopyFromUntaggedImageRule) LongDescription() string {
+	return "COPY --from can copy files out of an image. Without a tag the image resolves to 'latest', so the copied files change whenever the publisher pushes a new version."
+}
+
+func (r *CopyFromUntaggedImageRule) BadExample() string {
+	return "FROM alpine:3.18\n" +
+		"COPY --from=nginx /etc/nginx/nginx.conf /etc/nginx/nginx.conf"
+}
+
+func (r *CopyFromUntaggedImageRule) GoodExample() string {
+	return "FROM alpine:3.18\n" +
+		"COPY --from=nginx:1.25 /etc/nginx/nginx.conf /etc/nginx/nginx.conf"
+}
+
+func (r *CopyFromUntaggedImageRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/building/multi-stage/",
+		"https://docs.docker.com/reference/dockerfile/#copy---from",
+	}
+}
+
+func (r *CopyFromUntaggedImageRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	stageNames := make(map[string]bool)
+	for _, stage := range dockerfile.Stages {
+		stageNames[strings.ToLower(stage.Name)] = true
+	}
+
+	for _, cp := range ast.FindCopy(dockerfile) {
+		if cp.From == "" || strings.Contains(cp.From, "$") {
+			continue
+		}
+
+		// Stage names and indexes are handled by DL3027
+		external := isExternalImageRef(cp.From) ||
+			(!stageNames[strings.ToLower(cp.From)] && isKnownImageName(cp.From))
+		if !external {
+			continue
+		}
+
+		image, tag, digest := splitImageRef(cp.From)
+		if !isUntaggedImage(image, tag, digest) {
+			continue
+		}
+
+		findings = append(findings, ast.Finding{
+			RuleID:     r.ID(),
+			Severity:   r.Severity(),
+			Line:       cp.Line(),
+			Column:     1,
+			Message:    "COPY --from image '" + image + "' does not have an explicit tag, defaulting to 'latest'",
+			Suggestion: "Use explicit tag like '" + image + ":<version>' for reproducible builds",
+		})
+	}
+
+	return findings
+}
+
+// stageLabel returns the quoted name of a build stage, or its index if unnamed.
+func stageLabel(stage ast.Stage) string {
+	if stage.Name != "" {
+		return "'" + stage.Name + "'"
+	}
+	return intToString(stage.Index)
+}
+
+// isExternalImageRef reports whether a COPY --from value refers to an image
+// rather than a build stage. Values containing variables cannot be resolved and
+// are treated as external.
+func isExternalImageRef(from string) bool {
+	return strings.ContainsAny(from, ":/$")
+}
+
+// cacheMountManagers lists the package managers checked by DL3033. A cache
+// mount counts for a manager when its target ends with one of targets; the
+// first target is the one suggested. Commands matching noCache disable or
+// remove the manager's cache, as DL3009 asks, and are not reported.
+var cacheMountManagers = []struct {
+	name    string
+	pattern *regexp.Regexp
+	targets []string
+	noCache *regexp.Regexp
+}{
+	{"apt-get", regexp.MustCompile(`\bapt(?:-get)?\s+(?:-\S+\s+)*install\b`), []string{"/var/cache/apt", "/var/lib/apt"}, aptGetCleanPattern},
+	{"apk", regexp.MustCompile(`\bapk\s+(?:-\S+\s+)*add\b`), []string{"/var/cache/apk", "/etc/apk/cache"}, apkNoCachePattern},
+	{"pip", regexp.MustCompile(`\bpip[0-9.]*\s+(?:-\S+\s+)*install\b`), []string{"/root/.cache/pip", "/.cache/pip", "/.cache"}, regexp.MustCompile(`--no-cache-dir\b|\bPIP_NO_CACHE_DIR=`)},
+	{"npm", regexp.MustCompile(`\bnpm\s+(?:ci|install|i)\b`), []string{"/root/.npm", "/.npm"}, nil},
+	{"go build", regexp.MustCompile(`\bgo\s+(?:build|install)\b`), []string{"/root/.cache/go-build", "/.cache/go-build", "/.cache"}, nil},
+	{"go mod download", regexp.MustCompile(`\bgo\s+mod\s+download\b`), []string{"/go/pkg/mod", "/pkg/mod"}, nil},
+}
+
+// CacheMountRule suggests BuildKit cache mounts for package manager commands (DL3033).
+type CacheMountRule struct{}
+
+func (r *CacheMountRule) ID() string             { return RuleCacheMount }
+func (r *CacheMountRule) Name() string           { return "Package manager without cache mount" }
+func (r *CacheMountRule) Severity() ast.Severity { return ast.SeverityInfo }
+func (r *CacheMountRule) Category() string       { return CategoryPerformance }
+
+func (r *CacheMountRule) Description() string {
+	return "Use a BuildKit cache mount (RUN --mount=type=cache) for package managers so downloads are reused across builds without being stored in the image"
+}
+
+func (r *CacheMountRule) LongDescription() string {
+	return "When a RUN instruction changes, Docker runs it from scratch and the package manager downloads everything again. A BuildKit cache mount keeps the package manager's cache between builds, outside the image, so only new packages are downloaded.\n\n" +
+		"Cache mounts require BuildKit, which is the default builder since Docker 23.0. The rule is informational because the classic builder does not support them.\n\n" +
+		"Commands that disable or remove the cache, such as apk add --no-cache, pip install --no-cache-dir or rm -rf /var/lib/apt/lists as DL3009 recommends, are not reported."
+}
+
+func (r *CacheMountRule) BadExample() string {
+	return "FROM golang:1.22-alpine\n" +
+		"WORKDIR /src\n" +
+		"COPY . .\n" +
+		"RUN go build -o /out/app ."
+}
+
+func (r *CacheMountRule) GoodExample() string {
+	return "FROM golang:1.22-alpine\n" +
+		"WORKDIR /src\n" +
+		"COPY . .\n" +
+		"RUN --mount=type=cache,target=/root/.cache/go-build go build -o /out/app ."
+}
+
+func (r *CacheMountRule) References() []string {
+	return []string{
+		"https://docs.docker.com/build/cache/optimize/#use-cache-mounts",
+		"https://docs.docker.com/reference/dockerfile/#run---mounttypecache",
+	}
+}
+
+func (r *CacheMountRule) Check(dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+
+	for _, run := range ast.FindRun(dockerfile) {
+		for _, manager := range cacheMountManagers {
+			if !manager.pattern.MatchString(run.Command) || hasCacheMount(run.Mounts, manager.targets) {
+				continue
+			}
+			if manager.noCache != nil && manager.noCache.MatchString(run.Command) {
+				continue
+			}
+			findings = append(findings, ast.Finding{
+				RuleID:     r.ID(),
+				Severity:   r.Severity(),
+				Line:       run.Line(),
+				Column:     1,
+				Message:    manager.name + " runs without a cache mount; its downloads are repeated whenever this layer is rebuilt",
+				Suggestion: "Use 'RUN --mount=type=cache,target=" + manager.targets[0] + " ...' to reuse the " + manager.name + " cache across builds",
+			})
+		}
+	}
+
+	return findings
+}
+
+// hasCacheMount reports whether mounts include a cache mount whose target ends
+// with one of targets. Targets containing variables cannot be resolved and are
+// assumed to match.
+func hasCacheMount(mounts []ast.RunMount, targets []string) bool {
+	for _, mount := range mounts {
+		if mount.Type != "cache" {
+			continue
+		}
+		if strings.Contains(mount.Target, "$") {
+			return true
+		}
+		target := strings.TrimSuffix(mount.Target, "/")
+		for _, want := range targets {
+			if strings.HasSuffix(target, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // init registers the layer optimization rules with the default registry.
 func init() {
 	RegisterDefault(&CacheNotCleanedRule{})
 	RegisterDefault(&ConsecutiveRunRule{})
+	RegisterDefault(&ConsecutiveEnvRule{})
 	RegisterDefault(&SuboptimalOrderingRule{})
+	RegisterDefault(&CopyAllBeforeInstallRule{})
 	RegisterDefault(&UpdateWithoutInstallRule{})
+	RegisterDefault(&AptGetNoRecommendsRule{})
+	RegisterDefault(&AptGetUpgradeRule{})
+	RegisterDefault(&NpmCiRule{})
+	RegisterDefault(&NpmProductionRule{})
+	RegisterDefault(&PinnedAptVersionRule{})
+	RegisterDefault(&PinnedPipVersionRule{})
+	RegisterDefault(&AptGetMissingYesRule{})
+	RegisterDefault(&CopyFromUndefinedStageRule{})
+	RegisterDefault(&CopyFromUntaggedImageRule{})
+	RegisterDefault(&CacheMountRule{})
 }
@@ -1,9 +1,12 @@
 package rules
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
 )
 
 func TestLayerRulesRegistered(t *testing.T) {
@@ -13,6 +16,18 @@ func TestLayerRulesRegistered(t *testing.T) {
 		RuleConsecutiveRun,       // DL3010
 		RuleSuboptimalOrdering,   // DL3011
 		RuleUpdateWithoutInstall, // DL3012
+		RuleAptNoRecommends,      // DL3013
+		RuleAptGetUpgrade,        // DL3014
+		RuleNpmCi,                // DL3016
+		RuleCopyAllBeforeInstall, // DL3017
+		RulePipPinVersion,        // DL3020
+		RuleAptPinVersion,        // DL3023
+		RuleAptGetMissingYes,     // DL3024
+		RuleCopyFromUndefined,    // DL3027
+		RuleNpmProduction,        // DL3031
+		RuleCopyFromUntagged,     // DL3032
+		RuleCacheMount,           // DL3033
+		RuleConsecutiveEnv,       // DL3036
 	}
 
 	for _, ruleID := range expectedRules {
@@ -125,6 +140,57 @@ func TestCacheNotCleanedRule(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("RUN flags are not part of the command", func(t *testing.T) {
+		flags := "RUN --mount=type=secret,id=token --network=default --security=sandbox "
+		if got := countFindings(t, rule, "FROM alpine:3.18\n"+flags+"apk add --no-cache curl"); got != 0 {
+			t.Errorf("expected 0 findings, got %d", got)
+		}
+		if got := countFindings(t, rule, "FROM alpine:3.18\n"+flags+"apk add curl"); got != 1 {
+			t.Errorf("expected 1 findings, got %d", got)
+		}
+	})
+}
+
+func TestCacheNotCleanedRule_Fix(t *testing.T) {
+	rule := &CacheNotCleanedRule{}
+
+	tests := []struct {
+		name     string
+		run      string
+		expected string
+	}{
+		{"apt-get", "RUN apt-get update && apt-get install -y curl", "RUN apt-get update && apt-get install -y curl && rm -rf /var/lib/apt/lists/*"},
+		{"apt-get continued", "RUN apt-get update \\\n    && apt-get install -y curl", "RUN apt-get update \\\n    && apt-get install -y curl && rm -rf /var/lib/apt/lists/*"},
+		{"dnf", "RUN dnf install -y httpd", "RUN dnf install -y httpd && dnf clean all"},
+		{"apk", "RUN apk add curl", "RUN apk add curl && rm -rf /var/cache/apk/*"},
+		{"pip", "RUN pip3 install flask", "RUN pip3 install --no-cache-dir flask"},
+		{"already clean", "RUN apk add --no-cache curl", "RUN apk add --no-cache curl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "FROM alpine:3.18\n" + tt.run + "\nUSER app\n"
+			result := fixContent(t, rule, content)
+			if want := "FROM alpine:3.18\n" + tt.expected + "\nUSER app\n"; string(result.Source) != want {
+				t.Errorf("Fix() =\n%s\nwant\n%s", result.Source, want)
+			}
+
+			// The fixed Dockerfile is clean
+			df, err := parser.ParseString(string(result.Source))
+			if err != nil {
+				t.Fatalf("Failed to parse fixed Dockerfile: %v", err)
+			}
+			if findings := rule.Check(df); len(findings) != 0 {
+				t.Errorf("expected %d findings, got %d", 0, len(findings))
+			}
+		})
+	}
+
+	heredoc := "FROM debian:12-slim\nRUN <<EOF\napt-get install -y curl\nEOF\n"
+	if result := fixContent(t, rule, heredoc); result.Fixed != 0 || string(result.Source) != heredoc {
+		t.Errorf("heredoc RUN should not be fixed, got:\n%s", result.Source)
+	}
 }
 
 func TestConsecutiveRunRule(t *testing.T) {
@@ -198,6 +264,84 @@ func TestConsecutiveRunRule(t *testing.T) {
 	}
 }
 
+func TestConsecutiveEnvRule(t *testing.T) {
+	rule := &ConsecutiveEnvRule{}
+
+	tests := []struct {
+		name           string
+		content        string
+		expectedCount  int
+		expectedLine   int
+		wantSuggestion string
+	}{
+		{
+			name:          "single ENV - no finding",
+			content:       "FROM alpine:3.18\nENV APP_HOME=/app\n",
+			expectedCount: 0,
+		},
+		{
+			name:           "two adjacent ENVs - finding",
+			content:        "FROM alpine:3.18\nENV APP_HOME=/app\nENV APP_PORT=8080\n",
+			expectedCount:  1,
+			expectedLine:   2,
+			wantSuggestion: "ENV APP_HOME=/app APP_PORT=8080",
+		},
+		{
+			name:          "ENVs separated by RUN - no finding",
+			content:       "FROM alpine:3.18\nENV APP_HOME=/app\nRUN mkdir /app\nENV APP_PORT=8080\n",
+			expectedCount: 0,
+		},
+		{
+			name:           "three adjacent ENVs - one finding",
+			content:        "FROM alpine:3.18\nRUN true\nENV A=1 B=2\nENV C=\"hello world\"\nENV D=4\n",
+			expectedCount:  1,
+			expectedLine:   3,
+			wantSuggestion: "ENV A=1 B=2 C=\"hello world\" D=4",
+		},
+		{
+			name:          "ENVs in different stages - no finding",
+			content:       "FROM alpine:3.18 AS build\nENV A=1\nFROM alpine:3.18\nENV B=2\n",
+			expectedCount: 0,
+		},
+		{
+			name:          "ENV referencing a previous key - no finding",
+			content:       "FROM alpine:3.18\nENV APP_HOME=/app\nENV APP_BIN=${APP_HOME}/bin\n",
+			expectedCount: 0,
+		},
+		{
+			name:          "ENV referencing an earlier key starts a new sequence",
+			content:       "FROM alpine:3.18\nENV A=1\nENV B=$A\nENV C=3\n",
+			expectedCount: 1,
+			expectedLine:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("failed to parse Dockerfile: %v", err)
+			}
+			findings := rule.Check(df)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.expectedCount == 0 {
+				return
+			}
+			if findings[0].RuleID != RuleConsecutiveEnv {
+				t.Errorf("expected rule ID %s, got %s", RuleConsecutiveEnv, findings[0].RuleID)
+			}
+			if findings[0].Line != tt.expectedLine {
+				t.Errorf("expected finding on line %d, got %d", tt.expectedLine, findings[0].Line)
+			}
+			if tt.wantSuggestion != "" && !strings.Contains(findings[0].Suggestion, tt.wantSuggestion) {
+				t.Errorf("suggestion %q does not contain %q", findings[0].Suggestion, tt.wantSuggestion)
+			}
+		})
+	}
+}
+
 func TestUpdateWithoutInstallRule(t *testing.T) {
 	rule := &UpdateWithoutInstallRule{}
 
@@ -266,6 +410,88 @@ func TestUpdateWithoutInstallRule(t *testing.T) {
 	}
 }
 
+func TestCopyAllBeforeInstallRule(t *testing.T) {
+	rule := &CopyAllBeforeInstallRule{}
+
+	tests := []struct {
+		name               string
+		instructions       []ast.Instruction
+		expectedCount      int
+		expectedSuggestion string
+	}{
+		{
+			name: "COPY . . before npm install - warning",
+			instructions: []ast.Instruction{
+				&ast.CopyInstruction{LineNum: 2, Sources: []string{"."}, Dest: "."},
+				&ast.RunInstruction{LineNum: 3, Command: "npm install"},
+			},
+			expectedCount:      1,
+			expectedSuggestion: "RUN npm ci",
+		},
+		{
+			name: "COPY ./ before pip install - warning",
+			instructions: []ast.Instruction{
+				&ast.CopyInstruction{LineNum: 2, Sources: []string{"./"}, Dest: "/app/"},
+				&ast.RunInstruction{LineNum: 3, Command: "pip install -r requirements.txt"},
+			},
+			expectedCount:      1,
+			expectedSuggestion: "COPY requirements.txt",
+		},
+		{
+			name: "COPY . before apt-get install - warning",
+			instructions: []ast.Instruction{
+				&ast.CopyInstruction{LineNum: 2, Sources: []string{"."}, Dest: "/src"},
+				&ast.RunInstruction{LineNum: 3, Command: "apt-get install -y make"},
+			},
+			expectedCount:      1,
+			expectedSuggestion: "system packages",
+		},
+		{
+			name: "manifest first, COPY . . after install - no warning",
+			instructions: []ast.Instruction{
+				&ast.CopyInstruction{LineNum: 2, Sources: []string{"go.mod", "go.sum"}, Dest: "./"},
+				&ast.RunInstruction{LineNum: 3, Command: "go mod download"},
+				&ast.CopyInstruction{LineNum: 4, Sources: []string{"."}, Dest: "."},
+				&ast.RunInstruction{LineNum: 5, Command: "go build ./..."},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "COPY --from . before install - no warning",
+			instructions: []ast.Instruction{
+				&ast.CopyInstruction{LineNum: 2, From: "build", Sources: []string{"."}, Dest: "."},
+				&ast.RunInstruction{LineNum: 3, Command: "npm install"},
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "several installs after one COPY . - one warning",
+			instructions: []ast.Instruction{
+				&ast.CopyInstruction{LineNum: 2, Sources: []string{"."}, Dest: "."},
+				&ast.RunInstruction{LineNum: 3, Command: "npm install"},
+				&ast.RunInstruction{LineNum: 4, Command: "pip install awscli"},
+			},
+			expectedCount:      1,
+			expectedSuggestion: "RUN npm ci",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{{Index: 0, Instructions: tt.instructions}},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.expectedCount > 0 && !strings.Contains(findings[0].Suggestion, tt.expectedSuggestion) {
+				t.Errorf("expected suggestion to contain %q, got %q", tt.expectedSuggestion, findings[0].Suggestion)
+			}
+		})
+	}
+}
+
 func TestSuboptimalOrderingRule(t *testing.T) {
 	rule := &SuboptimalOrderingRule{}
 
@@ -313,64 +539,889 @@ func TestSuboptimalOrderingRule(t *testing.T) {
 	})
 }
 
-func TestIsPackageInstallCommand(t *testing.T) {
+func TestAptGetNoRecommendsRule(t *testing.T) {
+	rule := &AptGetNoRecommendsRule{}
+
 	tests := []struct {
-		cmd      string
-		expected bool
+		name          string
+		commands      []string
+		expectedCount int
 	}{
-		{"apt-get install -y curl", true},
-		{"yum install -y curl", true},
-		{"apk add --no-cache bash", true},
-		{"pip install flask", true},
-		{"npm install", true},
-		{"yarn install", true},
-		{"go mod download", true},
-		{"echo hello", false},
+		{
+			name:          "install with --no-install-recommends - no warning",
+			commands:      []string{"apt-get update && apt-get install -y --no-install-recommends curl"},
+			expectedCount: 0,
+		},
+		{
+			name:          "install with Install-Recommends option - no warning",
+			commands:      []string{"apt-get install -y -o APT::Install-Recommends=false curl"},
+			expectedCount: 0,
+		},
+		{
+			name:          "install without flag - warning",
+			commands:      []string{"apt-get update && apt-get install -y curl"},
+			expectedCount: 1,
+		},
+		{
+			name:          "upgrade without flag - warning",
+			commands:      []string{"apt-get update && apt-get upgrade -y"},
+			expectedCount: 1,
+		},
+		{
+			name:          "flag on a different apt-get command - warning",
+			commands:      []string{"apt-get install -y --no-install-recommends curl && apt-get install -y git"},
+			expectedCount: 1,
+		},
+		{
+			name: "recommends disabled in apt.conf earlier in stage - no warning",
+			commands: []string{
+				`echo 'APT::Install-Recommends "false";' > /etc/apt/apt.conf.d/99norecommends`,
+				"apt-get update && apt-get install -y curl",
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "install before apt.conf change - warning",
+			commands: []string{
+				"apt-get update && apt-get install -y curl",
+				`echo 'APT::Install-Recommends "0";' > /etc/apt/apt.conf.d/99norecommends`,
+				"apt-get install -y git",
+			},
+			expectedCount: 1,
+		},
+		{
+			name:          "apk add - no warning",
+			commands:      []string{"apk add --no-cache curl"},
+			expectedCount: 0,
+		},
 	}
 
 	for _, tt := range tests {
-		if got := isPackageInstallCommand(tt.cmd); got != tt.expected {
-			t.Errorf("isPackageInstallCommand(%q) = %v, want %v", tt.cmd, got, tt.expected)
-		}
+		t.Run(tt.name, func(t *testing.T) {
+			var instrs []ast.Instruction
+			for i, cmd := range tt.commands {
+				instrs = append(instrs, &ast.RunInstruction{LineNum: i + 2, Command: cmd})
+			}
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{{Instructions: instrs}},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
 	}
 }
 
-func TestIsPackageFile(t *testing.T) {
+func TestAptGetUpgradeRule(t *testing.T) {
+	rule := &AptGetUpgradeRule{}
+
 	tests := []struct {
-		dest     string
-		expected bool
+		name          string
+		command       string
+		expectedCount int
 	}{
-		{"/app/requirements.txt", true},
-		{"/app/package.json", true},
-		{"/app/go.mod", true},
-		{"/app/Gemfile", true},
-		{"/app/src/main.go", false},
-		{"/data/readme.md", false},
+		{
+			name:          "apt-get upgrade - warning",
+			command:       "apt-get update && apt-get upgrade -y",
+			expectedCount: 1,
+		},
+		{
+			name:          "apt-get dist-upgrade - warning",
+			command:       "apt-get update && apt-get dist-upgrade -y",
+			expectedCount: 1,
+		},
+		{
+			name:          "apt-get upgrade with leading options - warning",
+			command:       "apt-get -y upgrade",
+			expectedCount: 1,
+		},
+		{
+			name:          "apt-get update only - no warning",
+			command:       "apt-get update && apt-get install -y curl",
+			expectedCount: 0,
+		},
+		{
+			name:          "pip upgrade flag - no warning",
+			command:       "pip install --upgrade pip",
+			expectedCount: 0,
+		},
+		{
+			name:          "apk upgrade - warning",
+			command:       "apk update && apk upgrade --no-cache",
+			expectedCount: 1,
+		},
+		{
+			name:          "apk upgrade with leading options - warning",
+			command:       "apk --no-cache upgrade",
+			expectedCount: 1,
+		},
+		{
+			name:          "apt-get upgrade at end of command - warning",
+			command:       "apt-get update && apt-get upgrade",
+			expectedCount: 1,
+		},
+		{
+			name:          "package named upgrade-tool - no warning",
+			command:       "apt-get install -y upgrade-tool",
+			expectedCount: 0,
+		},
+		{
+			name:          "apk add of upgrade package - no warning",
+			command:       "apk add --no-cache upgrade-helper",
+			expectedCount: 0,
+		},
 	}
 
 	for _, tt := range tests {
-		if got := isPackageFile(tt.dest); got != tt.expected {
-			t.Errorf("isPackageFile(%q) = %v, want %v", tt.dest, got, tt.expected)
-		}
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 1, Command: tt.command},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
 	}
 }
 
-func TestIntToString(t *testing.T) {
+func TestPinnedAptVersionRule(t *testing.T) {
+	rule := &PinnedAptVersionRule{}
+
 	tests := []struct {
-		input    int
-		expected string
+		name          string
+		command       string
+		expectedCount int
 	}{
-		{0, "0"},
-		{1, "1"},
-		{10, "10"},
-		{123, "123"},
-		{-5, "-5"},
+		{
+			name:          "all packages pinned - no warning",
+			command:       "apt-get update && apt-get install -y curl=7.88.1-10 git=1:2.39.2-1",
+			expectedCount: 0,
+		},
+		{
+			name:          "unpinned package - warning",
+			command:       "apt-get install -y curl",
+			expectedCount: 1,
+		},
+		{
+			name:          "flags skipped and only unpinned package flagged",
+			command:       "apt-get install -y --no-install-recommends pkg1=1.0 pkg2",
+			expectedCount: 1,
+		},
+		{
+			name:          "shell variable ignored",
+			command:       "apt-get install -y $PKG ${EXTRA_PACKAGES}",
+			expectedCount: 0,
+		},
+		{
+			name:          "collapsed line continuation with multiple packages",
+			command:       "apt-get update &&     apt-get install -y     curl     wget=1.21-1 &&     rm -rf /var/lib/apt/lists/*",
+			expectedCount: 1,
+		},
+		{
+			name:          "options before install subcommand",
+			command:       "apt-get -y -o Dpkg::Options::=--force-confold install vim",
+			expectedCount: 1,
+		},
+		{
+			name:          "release pin accepted",
+			command:       "apt-get install -y -t bookworm-backports golang/bookworm-backports",
+			expectedCount: 0,
+		},
+		{
+			name:          "apt-get update only - no warning",
+			command:       "apt-get update",
+			expectedCount: 0,
+		},
+		{
+			name:          "non-apt command - no warning",
+			command:       "echo install curl",
+			expectedCount: 0,
+		},
 	}
 
 	for _, tt := range tests {
-		result := intToString(tt.input)
-		if result != tt.expected {
-			t.Errorf("intToString(%d) = %s, expected %s", tt.input, result, tt.expected)
-		}
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 1, Command: tt.command},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+}
+
+func TestPinnedPipVersionRule(t *testing.T) {
+	rule := &PinnedPipVersionRule{}
+
+	tests := []struct {
+		name            string
+		command         string
+		expectedCount   int
+		messageContains string
+	}{
+		{
+			name:            "bare package name - warning",
+			command:         "pip install flask",
+			expectedCount:   1,
+			messageContains: "'flask'",
+		},
+		{
+			name:          "pinned package - no warning",
+			command:       "pip install flask==3.0.0",
+			expectedCount: 0,
+		},
+		{
+			name:          "minimum version - no warning",
+			command:       "pip3 install 'requests>=2.31,<3'",
+			expectedCount: 0,
+		},
+		{
+			name:          "requirements file - no warning",
+			command:       "pip install --no-cache-dir -r requirements.txt",
+			expectedCount: 0,
+		},
+		{
+			name:          "flags skipped and one warning per unpinned package",
+			command:       "pip install --no-cache-dir --upgrade gunicorn flask==3.0.0 celery",
+			expectedCount: 2,
+		},
+		{
+			name:          "index url value skipped",
+			command:       "pip install -i https://pypi.example.com/simple uvicorn==0.29.0",
+			expectedCount: 0,
+		},
+		{
+			name:          "local paths and archives ignored",
+			command:       "pip install . ./libs/common /tmp/pkg.whl dist/app-1.0.tar.gz -e ./plugin",
+			expectedCount: 0,
+		},
+		{
+			name:          "shell variable ignored",
+			command:       "pip install $PACKAGES",
+			expectedCount: 0,
+		},
+		{
+			name:          "python -m pip install - warning",
+			command:       "python3 -m pip install numpy",
+			expectedCount: 1,
+		},
+		{
+			name:          "pip in a later command - warning",
+			command:       "apt-get update && /usr/local/bin/pip3.11 install boto3",
+			expectedCount: 1,
+		},
+		{
+			name:          "pip freeze - no warning",
+			command:       "pip freeze > requirements.txt",
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 1, Command: tt.command},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			if tt.messageContains != "" && len(findings) > 0 && !strings.Contains(findings[0].Message, tt.messageContains) {
+				t.Errorf("expected message to contain %q, got %q", tt.messageContains, findings[0].Message)
+			}
+		})
+	}
+}
+
+func TestAptGetMissingYesRule(t *testing.T) {
+	rule := &AptGetMissingYesRule{}
+
+	tests := []struct {
+		name          string
+		command       string
+		expectedCount int
+	}{
+		{
+			name:          "install with -y - no warning",
+			command:       "apt-get update && apt-get install -y curl",
+			expectedCount: 0,
+		},
+		{
+			name:          "install with --yes - no warning",
+			command:       "apt-get install --yes curl",
+			expectedCount: 0,
+		},
+		{
+			name:          "install with --assume-yes - no warning",
+			command:       "apt-get install --assume-yes curl",
+			expectedCount: 0,
+		},
+		{
+			name:          "combined short flags - no warning",
+			command:       "apt-get install -qy curl",
+			expectedCount: 0,
+		},
+		{
+			name:          "-y before install subcommand - no warning",
+			command:       "DEBIAN_FRONTEND=noninteractive apt-get -y install curl",
+			expectedCount: 0,
+		},
+		{
+			name:          "install without -y - warning",
+			command:       "apt-get install curl",
+			expectedCount: 1,
+		},
+		{
+			name:          "noninteractive frontend alone - warning",
+			command:       "DEBIAN_FRONTEND=noninteractive apt-get install curl",
+			expectedCount: 1,
+		},
+		{
+			name:          "-y in unrelated piped command - warning",
+			command:       "apt-get install curl | tee -y install.log",
+			expectedCount: 1,
+		},
+		{
+			name:          "-y in a different apt-get command - warning",
+			command:       "apt-get update -y && apt-get install curl",
+			expectedCount: 1,
+		},
+		{
+			name:          "no apt-get install - no warning",
+			command:       "apt-get update",
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 1, Command: tt.command},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+}
+
+func TestSplitShellCommands(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected []string
+	}{
+		{"echo hello", []string{"echo hello"}},
+		{"a && b || c; d | e", []string{"a", "b", "c", "d", "e"}},
+		{`sh -c "a && b" && c`, []string{`sh -c "a && b"`, "c"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got := splitShellCommands(tt.cmd)
+		if len(got) != len(tt.expected) {
+			t.Errorf("splitShellCommands(%q) = %q, want %q", tt.cmd, got, tt.expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("splitShellCommands(%q) = %q, want %q", tt.cmd, got, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestShellPipelines(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected [][]string
+	}{
+		{"echo hello", [][]string{{"echo hello"}}},
+		{"a | b && c || d; e | f | g", [][]string{{"a", "b"}, {"c"}, {"d"}, {"e", "f", "g"}}},
+		{`echo "a | b; c" | tee x`, [][]string{{`echo "a | b; c"`, "tee x"}}},
+		{`echo 'a && b' \| c`, [][]string{{`echo 'a && b' \| c`}}},
+		{"echo |", [][]string{{"echo", ""}}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := shellPipelines(tt.cmd); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("shellPipelines(%q) = %q, want %q", tt.cmd, got, tt.expected)
+		}
+	}
+}
+
+func TestIsPackageInstallCommand(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected bool
+	}{
+		{"apt-get install -y curl", true},
+		{"yum install -y curl", true},
+		{"apk add --no-cache bash", true},
+		{"pip install flask", true},
+		{"npm install", true},
+		{"yarn install", true},
+		{"go mod download", true},
+		{"echo hello", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPackageInstallCommand(tt.cmd); got != tt.expected {
+			t.Errorf("isPackageInstallCommand(%q) = %v, want %v", tt.cmd, got, tt.expected)
+		}
+	}
+}
+
+func TestIsPackageFile(t *testing.T) {
+	tests := []struct {
+		dest     string
+		expected bool
+	}{
+		{"/app/requirements.txt", true},
+		{"/app/package.json", true},
+		{"/app/go.mod", true},
+		{"/app/Gemfile", true},
+		{"/app/src/main.go", false},
+		{"/data/readme.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPackageFile(tt.dest); got != tt.expected {
+			t.Errorf("isPackageFile(%q) = %v, want %v", tt.dest, got, tt.expected)
+		}
+	}
+}
+
+func TestIntToString(t *testing.T) {
+	tests := []struct {
+		input    int
+		expected string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{10, "10"},
+		{123, "123"},
+		{-5, "-5"},
+	}
+
+	for _, tt := range tests {
+		result := intToString(tt.input)
+		if result != tt.expected {
+			t.Errorf("intToString(%d) = %s, expected %s", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestCopyFromUndefinedStageRule(t *testing.T) {
+	rule := &CopyFromUndefinedStageRule{}
+
+	tests := []struct {
+		name          string
+		from          string
+		expectedCount int
+	}{
+		{"defined alias", "builder", 0},
+		{"defined alias different case", "Builder", 0},
+		{"typo in alias", "bulder", 1},
+		{"alias of current stage", "final", 1},
+		{"preceding stage index", "0", 0},
+		{"current stage index", "1", 1},
+		{"out of range index", "5", 1},
+		{"external image with tag", "nginx:latest", 0},
+		{"external image with registry", "ghcr.io/org/tool", 0},
+		{"well-known external image", "nginx", 0},
+		{"variable reference", "${BUILDER}", 0},
+		{"later stage alias", "test", 1},
+		{"later stage index", "2", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{
+					{
+						Name:  "builder",
+						Index: 0,
+						Instructions: []ast.Instruction{
+							&ast.RunInstruction{LineNum: 2, Command: "go build -o /app"},
+						},
+					},
+					{
+						Name:  "final",
+						Index: 1,
+						Instructions: []ast.Instruction{
+							&ast.CopyInstruction{LineNum: 4, From: tt.from, Sources: []string{"/app"}, Dest: "/app"},
+						},
+					},
+					{
+						Name:  "test",
+						Index: 2,
+					},
+				},
+			}
+
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+}
+
+func TestCopyFromUntaggedImageRule(t *testing.T) {
+	rule := &CopyFromUntaggedImageRule{}
+
+	tests := []struct {
+		name          string
+		from          string
+		expectedCount int
+	}{
+		{"stage name", "builder", 0},
+		{"stage index", "0", 0},
+		{"undefined stage", "bulder", 0},
+		{"well-known image without tag", "nginx", 1},
+		{"well-known image with tag", "nginx:1.25", 0},
+		{"registry image without tag", "ghcr.io/org/tool", 1},
+		{"registry with port and tag", "registry:5000/org/tool:2.1", 0},
+		{"registry with port without tag", "registry:5000/org/tool", 1},
+		{"image with digest", "busybox@sha256:abc123", 0},
+		{"variable reference", "${TOOLS_IMAGE}", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{
+					{Name: "builder", Index: 0},
+					{Index: 1},
+				},
+				Instructions: []ast.Instruction{
+					&ast.CopyInstruction{LineNum: 4, From: tt.from, Sources: []string{"/app"}, Dest: "/app"},
+				},
+			}
+
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+
+	t.Run("stage named like an image", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{{Name: "node", Index: 0}, {Index: 1}},
+			Instructions: []ast.Instruction{
+				&ast.CopyInstruction{LineNum: 4, From: "node", Sources: []string{"/app"}, Dest: "/app"},
+			},
+		}
+		if findings := rule.Check(dockerfile); len(findings) != 0 {
+			t.Errorf("expected 0 findings, got %d", len(findings))
+		}
+	})
+}
+
+func TestCopyFromUndefinedStageRule_Messages(t *testing.T) {
+	rule := &CopyFromUndefinedStageRule{}
+
+	dockerfile := &ast.Dockerfile{
+		Stages: []ast.Stage{
+			{
+				Index: 0,
+				Instructions: []ast.Instruction{
+					&ast.CopyInstruction{LineNum: 2, From: "0", Sources: []string{"/a"}, Dest: "/a"},
+					&ast.CopyInstruction{LineNum: 3, From: "assets", Sources: []string{"/b"}, Dest: "/b"},
+				},
+			},
+			{Name: "assets", Index: 1},
+		},
+	}
+
+	findings := rule.Check(dockerfile)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+
+	expected := []string{
+		"COPY --from=0 refers to the current stage 0",
+		"COPY --from=assets refers to later stage 'assets'",
+	}
+	for i, msg := range expected {
+		if findings[i].Message != msg {
+			t.Errorf("finding %d message = %q, want %q", i, findings[i].Message, msg)
+		}
+	}
+}
+
+func TestNpmCiRule(t *testing.T) {
+	rule := &NpmCiRule{}
+
+	tests := []struct {
+		name          string
+		copySources   []string
+		command       string
+		expectedCount int
+	}{
+		{"npm install after package-lock.json", []string{"package.json", "package-lock.json"}, "npm install", 1},
+		{"npm i after yarn.lock", []string{"yarn.lock"}, "npm i", 1},
+		{"npm install with flags only", []string{"package-lock.json"}, "npm install --production --no-audit", 1},
+		{"lock file copied by glob", []string{"package*.json"}, "npm install", 1},
+		{"npm install in chained command", []string{"package-lock.json"}, "cd /app && npm install && npm run build", 1},
+		{"npm ci", []string{"package-lock.json"}, "npm ci", 0},
+		{"npm install with package", []string{"package-lock.json"}, "npm install --save-dev somepackage", 0},
+		{"global package install", []string{"package-lock.json"}, "npm install -g npm@10", 0},
+		{"no lock file copied", []string{"package.json"}, "npm install", 0},
+		{"npm run install script", []string{"package-lock.json"}, "npm run install", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{
+					{
+						Index: 0,
+						Instructions: []ast.Instruction{
+							&ast.CopyInstruction{LineNum: 2, Sources: tt.copySources, Dest: "./"},
+							&ast.RunInstruction{LineNum: 3, Command: tt.command},
+						},
+					},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+
+	t.Run("npm install before lock file copy", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{
+				{
+					Index: 0,
+					Instructions: []ast.Instruction{
+						&ast.RunInstruction{LineNum: 2, Command: "npm install"},
+						&ast.CopyInstruction{LineNum: 3, Sources: []string{"package-lock.json"}, Dest: "./"},
+					},
+				},
+			},
+		}
+		if findings := rule.Check(dockerfile); len(findings) != 0 {
+			t.Errorf("expected 0 findings, got %d", len(findings))
+		}
+	})
+
+	t.Run("lock file copied in another stage", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{
+				{Index: 0, Instructions: []ast.Instruction{
+					&ast.CopyInstruction{LineNum: 2, Sources: []string{"package-lock.json"}, Dest: "./"},
+				}},
+				{Index: 1, Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 4, Command: "npm install"},
+				}},
+			},
+		}
+		if findings := rule.Check(dockerfile); len(findings) != 0 {
+			t.Errorf("expected 0 findings, got %d", len(findings))
+		}
+	})
+}
+
+func TestNpmProductionRule(t *testing.T) {
+	rule := &NpmProductionRule{}
+
+	tests := []struct {
+		name          string
+		env           *ast.EnvInstruction
+		command       string
+		expectedCount int
+	}{
+		{"plain npm install", nil, "npm install", 1},
+		{"npm i in chained command", nil, "cd /app && npm i && npm run build", 1},
+		{"npm install with package", nil, "npm install express", 1},
+		{"npm ci", nil, "npm ci", 0},
+		{"npm install --production", nil, "npm install --production", 0},
+		{"npm install --omit=dev", nil, "npm install --omit=dev", 0},
+		{"npm install --omit dev", nil, "npm install --omit dev", 0},
+		{"global install", nil, "npm install -g pm2", 0},
+		{"inline NODE_ENV=production", nil, "NODE_ENV=production npm install", 0},
+		{"ENV NODE_ENV=production", &ast.EnvInstruction{LineNum: 2, Key: "NODE_ENV", Value: "production"}, "npm install", 0},
+		{"ENV NODE_ENV in later pair", &ast.EnvInstruction{LineNum: 2, Key: "PORT", Value: "3000", Pairs: []ast.EnvPair{{Key: "PORT", Value: "3000"}, {Key: "NODE_ENV", Value: "production"}}}, "npm install", 0},
+		{"ENV NODE_ENV=development", &ast.EnvInstruction{LineNum: 2, Key: "NODE_ENV", Value: "development"}, "npm install", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var instructions []ast.Instruction
+			if tt.env != nil {
+				instructions = append(instructions, tt.env)
+			}
+			instructions = append(instructions, &ast.RunInstruction{LineNum: 3, Command: tt.command})
+
+			dockerfile := &ast.Dockerfile{
+				Stages: []ast.Stage{
+					{
+						Index:        0,
+						FromInstr:    &ast.FromInstruction{LineNum: 1, Image: "node"},
+						Instructions: instructions,
+					},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Errorf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+		})
+	}
+
+	t.Run("builder stage not checked", func(t *testing.T) {
+		dockerfile := &ast.Dockerfile{
+			Stages: []ast.Stage{
+				{
+					Index:     0,
+					Name:      "build",
+					FromInstr: &ast.FromInstruction{LineNum: 1, Image: "node"},
+					Instructions: []ast.Instruction{
+						&ast.RunInstruction{LineNum: 2, Command: "npm install && npm run build"},
+					},
+				},
+				{
+					Index:     1,
+					FromInstr: &ast.FromInstruction{LineNum: 3, Image: "node"},
+					Instructions: []ast.Instruction{
+						&ast.CopyInstruction{LineNum: 4, From: "build", Sources: []string{"/app/dist"}, Dest: "/app"},
+						&ast.RunInstruction{LineNum: 5, Command: "npm ci --omit=dev"},
+					},
+				},
+			},
+		}
+		if findings := rule.Check(dockerfile); len(findings) != 0 {
+			t.Errorf("expected 0 findings, got %d", len(findings))
+		}
+	})
+}
+
+func TestCacheMountRule(t *testing.T) {
+	rule := &CacheMountRule{}
+
+	tests := []struct {
+		name          string
+		command       string
+		mounts        []ast.RunMount
+		expectedCount int
+	}{
+		{"apt-get without mount", "apt-get update && apt-get install -y curl", nil, 1},
+		{"apt-get with cache mount", "apt-get update && apt-get install -y curl", []ast.RunMount{{Type: "cache", Target: "/var/cache/apt"}}, 0},
+		{"apt-get lists removed", "apt-get update && apt-get install -y curl && rm -rf /var/lib/apt/lists/*", nil, 0},
+		{"apt-get clean", "apt-get update && apt-get install -y curl && apt-get clean", nil, 0},
+		{"apt-get cleanup does not cover pip", "apt-get install -y python3-pip && rm -rf /var/lib/apt/lists/* && pip install flask", nil, 1},
+		{"apk without mount", "apk add curl", nil, 1},
+		{"apk with --no-cache", "apk add --no-cache curl", nil, 0},
+		{"apk cache removed", "apk add curl && rm -rf /var/cache/apk/*", nil, 0},
+		{"pip with --no-cache-dir", "pip install --no-cache-dir flask", nil, 0},
+		{"pip with PIP_NO_CACHE_DIR", "PIP_NO_CACHE_DIR=1 pip install flask", nil, 0},
+		{"apk --no-cache does not cover pip", "apk add --no-cache py3-pip && pip install flask", nil, 1},
+		{"apk with cache mount", "apk add curl", []ast.RunMount{{Type: "cache", Target: "/etc/apk/cache"}}, 0},
+		{"pip without mount", "pip install -r requirements.txt", nil, 1},
+		{"pip with cache mount", "pip install -r requirements.txt", []ast.RunMount{{Type: "cache", Target: "/root/.cache/pip"}}, 0},
+		{"pip with parent cache mount", "pip3 install flask", []ast.RunMount{{Type: "cache", Target: "/home/app/.cache/"}}, 0},
+		{"npm ci without mount", "npm ci", nil, 1},
+		{"npm with cache mount", "npm ci", []ast.RunMount{{Type: "cache", Target: "/root/.npm"}}, 0},
+		{"go build without mount", "go build -o /out/app .", nil, 1},
+		{"go install without mount", "go install ./cmd/app", nil, 1},
+		{"go build with build cache mount", "go build ./...", []ast.RunMount{{Type: "cache", Target: "/root/.cache/go-build"}}, 0},
+		{"go build with only module cache mount", "go build ./...", []ast.RunMount{{Type: "cache", Target: "/go/pkg/mod"}}, 1},
+		{"go mod download without mount", "go mod download", nil, 1},
+		{"go mod download with module cache mount", "go mod download", []ast.RunMount{{Type: "cache", Target: "/go/pkg/mod"}}, 0},
+		{"go mod download with only build cache mount", "go mod download", []ast.RunMount{{Type: "cache", Target: "/root/.cache/go-build"}}, 1},
+		{"go mod download and build", "go mod download && go build ./...", []ast.RunMount{{Type: "cache", Target: "/go/pkg/mod"}, {Type: "cache", Target: "/root/.cache/go-build"}}, 0},
+		{"cache mount for another manager", "pip install flask", []ast.RunMount{{Type: "cache", Target: "/root/.npm"}}, 1},
+		{"bind mount is not a cache mount", "npm ci", []ast.RunMount{{Type: "bind", Target: "/root/.npm"}}, 1},
+		{"target with variable", "npm ci", []ast.RunMount{{Type: "cache", Target: "$HOME/.npm"}}, 0},
+		{"two managers without mounts", "apt-get install -y python3-pip && pip install flask", nil, 2},
+		{"no package manager", "make build", nil, 0},
+		{"go version is not a build", "go version", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{
+					&ast.RunInstruction{LineNum: 1, Command: tt.command, Mounts: tt.mounts},
+				},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != tt.expectedCount {
+				t.Fatalf("expected %d findings, got %d", tt.expectedCount, len(findings))
+			}
+			for _, f := range findings {
+				if f.Severity != ast.SeverityInfo {
+					t.Errorf("expected info severity, got %s", f.Severity)
+				}
+			}
+		})
+	}
+
+	t.Run("suggestion names the cache target", func(t *testing.T) {
+		want := map[string]string{
+			"apt-get install -y curl": "--mount=type=cache,target=/var/cache/apt",
+			"apk add curl":            "--mount=type=cache,target=/var/cache/apk",
+			"pip install flask":       "--mount=type=cache,target=/root/.cache/pip",
+			"npm install":             "--mount=type=cache,target=/root/.npm",
+			"go build ./...":          "--mount=type=cache,target=/root/.cache/go-build",
+			"go mod download":         "--mount=type=cache,target=/go/pkg/mod",
+		}
+		for command, suggestion := range want {
+			dockerfile := &ast.Dockerfile{
+				Instructions: []ast.Instruction{&ast.RunInstruction{LineNum: 1, Command: command}},
+			}
+			findings := rule.Check(dockerfile)
+			if len(findings) != 1 || !strings.Contains(findings[0].Suggestion, suggestion) {
+				t.Errorf("%s: expected suggestion containing %q, got %+v", command, suggestion, findings)
+			}
+		}
+	})
+}
+
+func TestCacheMountRule_DL3009Compliant(t *testing.T) {
+	// Commands that follow DL3009, including the output of its fix, must not
+	// be reported by DL3033
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"apk add --no-cache", "FROM alpine:3.18\nRUN apk add --no-cache curl\n"},
+		{"apk add with cache removed", "FROM alpine:3.18\nRUN apk add curl && rm -rf /var/cache/apk/*\n"},
+		{"pip install --no-cache-dir", "FROM python:3.12-slim\nRUN pip install --no-cache-dir flask\n"},
+		{"apt-get with lists removed", "FROM debian:12\nRUN apt-get update && apt-get install -y --no-install-recommends curl=7.88.1-10 && rm -rf /var/lib/apt/lists/*\n"},
+		{"apt-get with cache mount and lists removed", "FROM debian:12\nRUN --mount=type=cache,target=/var/cache/apt apt-get update && apt-get install -y --no-install-recommends curl && rm -rf /var/lib/apt/lists/*\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := parser.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			for _, rule := range []Rule{&CacheNotCleanedRule{}, &CacheMountRule{}} {
+				if findings := rule.Check(df); len(findings) != 0 {
+					t.Errorf("%s: expected 0 findings, got %v", rule.ID(), findings)
+				}
+			}
+		})
 	}
 }
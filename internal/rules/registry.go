@@ -2,6 +2,9 @@
 package rules
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"sort"
 	"sync"
 
@@ -17,6 +20,18 @@ const (
 	RuleConsecutiveRun       = "DL3010" // Consecutive RUN instructions
 	RuleSuboptimalOrdering   = "DL3011" // Suboptimal layer ordering
 	RuleUpdateWithoutInstall = "DL3012" // Package update without install
+	RuleAptNoRecommends      = "DL3013" // apt-get install without --no-install-recommends
+	RuleAptGetUpgrade        = "DL3014" // apt-get upgrade in RUN
+	RuleNpmCi                = "DL3016" // npm install instead of npm ci
+	RuleCopyAllBeforeInstall = "DL3017" // COPY . before package install
+	RulePipPinVersion        = "DL3020" // pip install without pinned versions
+	RuleAptPinVersion        = "DL3023" // apt-get install without pinned versions
+	RuleAptGetMissingYes     = "DL3024" // apt-get install without -y
+	RuleCopyFromUndefined    = "DL3027" // COPY --from references an undefined stage
+	RuleNpmProduction        = "DL3031" // npm install with devDependencies in the final stage
+	RuleCopyFromUntagged     = "DL3032" // COPY --from external image without tag
+	RuleCacheMount           = "DL3033" // Package manager RUN without a BuildKit cache mount
+	RuleConsecutiveEnv       = "DL3036" // Consecutive ENV instructions
 )
 
 // Rule IDs for best practice rules (DL3xxx continued)
@@ -24,23 +39,73 @@ const (
 	RuleMultipleCMD        = "DL3001" // Multiple CMD instructions
 	RuleMultipleEntrypoint = "DL3002" // Multiple ENTRYPOINT instructions
 	RuleRelativeWorkdir    = "DL3003" // WORKDIR with relative path
+	RuleRunCd              = "DL3004" // RUN cd instead of WORKDIR
+	RuleInvalidPort        = "DL3005" // Invalid EXPOSE port
+	RuleDuplicateLabel     = "DL3018" // Duplicate LABEL key within an instruction or stage
+	RuleUnusedArg          = "DL3021" // ARG declared but never referenced
+	RuleExecForm           = "DL3025" // CMD/ENTRYPOINT in shell form
+	RuleCopyMultipleSrc    = "DL3026" // COPY/ADD with multiple sources and non-directory destination
+	RulePipefail           = "DL3028" // RUN with pipe but no pipefail
+	RuleDuplicateEnv       = "DL3029" // Duplicate ENV key within a stage
+	RuleDeprecatedMaint    = "DL3030" // Deprecated MAINTAINER instruction
+	RuleInvalidStopSignal  = "DL3034" // STOPSIGNAL with an unknown signal
+	RuleCopyWholeContext   = "DL3035" // COPY/ADD of the entire build context
+	RuleCopyChown          = "DL3038" // COPY/ADD without --chown before a non-root USER
+	RuleRelativeCopyDest   = "DL3039" // COPY/ADD to a relative destination without WORKDIR
+)
+
+// Rule IDs for security rules (DL3xxx)
+const (
+	RuleChmod777         = "DL3015" // chmod 777 in RUN
+	RuleInsecureDownload = "DL3019" // curl or wget with TLS verification disabled
+	RuleCurlPipeBash     = "DL3022" // curl or wget output piped into a shell
+	RuleRemoteArchive    = "DL3037" // ADD of a remote archive, which is not extracted
 )
 
 // Rule IDs for security rules (DL4xxx)
 const (
-	RuleSecretInEnv = "DL4000" // Potential secret in ENV
-	RuleSecretInArg = "DL4001" // Potential secret in ARG
-	RuleNoUser      = "DL4002" // No USER instruction (running as root)
-	RuleAddWithURL  = "DL4003" // ADD with URL
-	RuleAddOverCopy = "DL4004" // ADD where COPY would suffice
+	RuleSecretInEnv        = "DL4000" // Potential secret in ENV
+	RuleSecretInArg        = "DL4001" // Potential secret in ARG
+	RuleNoUser             = "DL4002" // No USER instruction (running as root)
+	RuleAddWithURL         = "DL4003" // ADD with URL
+	RuleAddOverCopy        = "DL4004" // ADD where COPY would suffice
+	RuleSudoInRun          = "DL4005" // sudo used in RUN
+	RuleCopyGitDir         = "DL4006" // .git directory copied into image
+	RuleCredentialCopy     = "DL4007" // Credential file copied into image
+	RuleSecretInRun        = "DL4008" // Secret embedded in RUN command
+	RuleSensitiveFileWrite = "DL4011" // RUN writes to /etc/passwd, /etc/shadow or /etc/sudoers
+	RuleRootFinalStage     = "DL4012" // Final stage runs as root
+	RuleSecretArgInEnv     = "DL4013" // ENV copies a secret build argument
+	RuleCredentialsInURL   = "DL4014" // URL with embedded credentials in RUN, ADD or COPY
+)
+
+// Rule IDs for base image rules (DL4xxx)
+const (
+	RuleUndeclaredArgInFrom  = "DL4009" // FROM references an ARG not declared before the first FROM
+	RuleFinalStageLargeImage = "DL4010" // Final stage based on a large image
 )
 
 // Rule IDs for best practice rules (DL5xxx)
 const (
 	RuleMissingHealthcheck = "DL5000" // Missing HEALTHCHECK
 	RuleWildcardCopy       = "DL5001" // Wildcard in COPY/ADD source
+	RulePrivilegedPort     = "DL5002" // EXPOSE of privileged port below 1024
+	RuleHealthcheckShell   = "DL5003" // HEALTHCHECK command in shell form
+	RuleCopyChownMissing   = "DL5004" // COPY/ADD without --chown after a non-root USER
+	RuleHealthcheckOptions = "DL5005" // HEALTHCHECK option with an invalid duration or retry count
+)
+
+// Rule categories used to group rules.
+const (
+	CategorySecurity     = "security"      // Issues that weaken the security of the image
+	CategoryPerformance  = "performance"   // Image size and build cache efficiency
+	CategoryBestPractice = "best-practice" // Maintainability and reproducibility
+	CategoryCorrectness  = "correctness"   // Mistakes that break or silently change the build
 )
 
+// Categories lists all rule categories.
+var Categories = []string{CategorySecurity, CategoryPerformance, CategoryBestPractice, CategoryCorrectness}
+
 // Rule defines the interface that all lint rules must implement.
 type Rule interface {
 	// ID returns the unique identifier for this rule (e.g., "DL3006").
@@ -59,6 +124,109 @@ type Rule interface {
 	Check(dockerfile *ast.Dockerfile) []ast.Finding
 }
 
+// Categorizer is implemented by rules that belong to a category. It is kept
+// separate from Rule so that existing custom rules do not need to change.
+type Categorizer interface {
+	// Category returns the category of this rule (e.g., CategorySecurity).
+	Category() string
+}
+
+// Fix is a text edit to a Dockerfile. Line is any line of the instruction to
+// edit, which may span several physical lines. OldText is replaced by NewText
+// at its first whole-word occurrence in the instruction's arguments. An empty
+// OldText appends NewText to the end of the instruction.
+type Fix struct {
+	Line    int
+	OldText string
+	NewText string
+
+	// Keyword makes the edit replace the instruction keyword, which must
+	// match OldText regardless of case, instead of an argument.
+	Keyword bool
+}
+
+// Fixer is implemented by rules whose findings can be fixed automatically.
+type Fixer interface {
+	// Fix returns the edits that fix a finding this rule reported, or none
+	// if there is nothing to fix. It returns an error, explaining what to do
+	// instead, when the finding has to be fixed by hand.
+	Fix(finding ast.Finding, dockerfile *ast.Dockerfile) ([]Fix, error)
+}
+
+// Explainer is implemented by rules that document themselves in more detail
+// than Description, as shown by docker-lint explain.
+type Explainer interface {
+	// LongDescription explains why the rule exists, in one or more paragraphs.
+	LongDescription() string
+
+	// BadExample returns a Dockerfile snippet that the rule reports.
+	BadExample() string
+
+	// GoodExample returns BadExample rewritten so that the rule passes.
+	GoodExample() string
+
+	// References returns links to further documentation.
+	References() []string
+}
+
+// DocumentedRule is implemented by rules with a page documenting them in
+// more depth than Explainer, such as the page of the equivalent hadolint rule.
+type DocumentedRule interface {
+	// DocURL returns the URL of the rule's documentation page.
+	DocURL() string
+}
+
+// LineLocalRule is implemented by rules whose findings for an instruction
+// depend only on that instruction and are reported on its line, such as a
+// check of a single RUN command. Their findings for unchanged instructions can
+// be reused when a Dockerfile is edited; see analyzer.AnalyzeIncremental.
+// Rules that look at stages or at the file as a whole, such as NoUserRule,
+// must not implement it.
+type LineLocalRule interface {
+	Rule
+
+	// CheckInstruction returns the findings for a single instruction. Check
+	// must return the same findings as calling it on each instruction in
+	// order.
+	CheckInstruction(instr ast.Instruction) []ast.Finding
+}
+
+// checkInstructions implements Check for a LineLocalRule by checking each
+// instruction of the Dockerfile in order.
+func checkInstructions(rule LineLocalRule, dockerfile *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+	for _, instr := range dockerfile.Instructions {
+		findings = append(findings, rule.CheckInstruction(instr)...)
+	}
+	return findings
+}
+
+// RuleOption documents an option accepted by a Configurable rule.
+type RuleOption struct {
+	Key         string
+	Description string
+}
+
+// Configurable is implemented by rules that accept options, such as a custom
+// list of images to report. Registered rules are shared, so options are
+// applied to a copy of the rule; see RuleRegistry.Configure.
+type Configurable interface {
+	// Options documents the options the rule accepts.
+	Options() []RuleOption
+
+	// Configure applies options keyed by RuleOption.Key. It returns an error
+	// for unknown keys and invalid values.
+	Configure(options map[string]string) error
+}
+
+// CategoryOf returns the category of a rule, or "" if it has none.
+func CategoryOf(rule Rule) string {
+	if c, ok := rule.(Categorizer); ok {
+		return c.Category()
+	}
+	return ""
+}
+
 // RuleRegistry manages the collection of available lint rules.
 type RuleRegistry struct {
 	mu    sync.RWMutex
@@ -80,6 +248,93 @@ func (r *RuleRegistry) Register(rule Rule) {
 	r.rules[rule.ID()] = rule
 }
 
+// Unregister removes the rule with the given ID from the registry. It
+// reports whether such a rule was registered.
+func (r *RuleRegistry) Unregister(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rules[id]; !ok {
+		return false
+	}
+	delete(r.rules, id)
+	return true
+}
+
+// Replace swaps the registered rule with the same ID as rule for rule. It
+// reports whether such a rule was registered; if not, the registry is left
+// unchanged. Use Register to add a new rule.
+func (r *RuleRegistry) Replace(rule Rule) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rules[rule.ID()]; !ok {
+		return false
+	}
+	r.rules[rule.ID()] = rule
+	return true
+}
+
+// Clone returns a new registry with the same rules. Changes to the clone do
+// not affect r, so DefaultRegistry can be cloned and customized safely.
+func (r *RuleRegistry) Clone() *RuleRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewRegistry()
+	for id, rule := range r.rules {
+		clone.rules[id] = rule
+	}
+	return clone
+}
+
+// Configure returns a clone of the registry in which the rules named in
+// ruleOptions, keyed by rule ID, are replaced by copies configured with their
+// options. The rules in r are not modified. Options for unknown rules, for
+// rules that are not Configurable and options that Configure rejects are
+// reported in the returned error; those rules keep their defaults in the
+// clone, which is returned either way.
+func (r *RuleRegistry) Configure(ruleOptions map[string]map[string]string) (*RuleRegistry, error) {
+	clone := r.Clone()
+
+	ids := make([]string, 0, len(ruleOptions))
+	for id := range ruleOptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var errs []error
+	for _, id := range ids {
+		rule := clone.Get(id)
+		if rule == nil {
+			errs = append(errs, fmt.Errorf("unknown rule: %s", id))
+			continue
+		}
+		configured, ok := copyRule(rule).(Configurable)
+		if !ok {
+			errs = append(errs, fmt.Errorf("rule %s has no options", id))
+			continue
+		}
+		if err := configured.Configure(ruleOptions[id]); err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", id, err))
+			continue
+		}
+		clone.Replace(configured.(Rule))
+	}
+
+	return clone, errors.Join(errs...)
+}
+
+// copyRule returns a shallow copy of a rule implemented as a pointer to a
+// struct, or nil for other rules.
+func copyRule(rule Rule) Rule {
+	v := reflect.ValueOf(rule)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface().(Rule)
+}
+
 // Get retrieves a rule by its ID.
 // Returns nil if the rule is not found.
 func (r *RuleRegistry) Get(id string) Rule {
@@ -111,6 +366,18 @@ func (r *RuleRegistry) All() []Rule {
 	return result
 }
 
+// FilterByCategory returns the registered rules in the given category, sorted by ID.
+// Rules that do not implement Categorizer are never included.
+func (r *RuleRegistry) FilterByCategory(category string) []Rule {
+	var result []Rule
+	for _, rule := range r.All() {
+		if c, ok := rule.(Categorizer); ok && c.Category() == category {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
 // Count returns the number of registered rules.
 func (r *RuleRegistry) Count() int {
 	r.mu.RLock()
@@ -0,0 +1,196 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
+)
+
+// uncategorizedRule is a rule that does not implement Categorizer.
+type uncategorizedRule struct{}
+
+func (r *uncategorizedRule) ID() string                          { return "X0001" }
+func (r *uncategorizedRule) Name() string                        { return "Uncategorized" }
+func (r *uncategorizedRule) Description() string                 { return "A rule without a category" }
+func (r *uncategorizedRule) Severity() ast.Severity              { return ast.SeverityInfo }
+func (r *uncategorizedRule) Check(*ast.Dockerfile) []ast.Finding { return nil }
+
+func TestDefaultRulesHaveCategory(t *testing.T) {
+	for _, rule := range DefaultRegistry.All() {
+		category := CategoryOf(rule)
+		found := false
+		for _, c := range Categories {
+			if c == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Rule %s has unknown category %q", rule.ID(), category)
+		}
+	}
+}
+
+func TestDefaultRulesExplain(t *testing.T) {
+	for _, rule := range DefaultRegistry.All() {
+		t.Run(rule.ID(), func(t *testing.T) {
+			explainer, ok := rule.(Explainer)
+			if !ok {
+				t.Fatalf("Rule %s does not implement Explainer", rule.ID())
+			}
+			if explainer.LongDescription() == "" {
+				t.Error("LongDescription is empty")
+			}
+			if len(explainer.References()) == 0 {
+				t.Error("References is empty")
+			}
+			for _, ref := range explainer.References() {
+				if !strings.HasPrefix(ref, "https://") {
+					t.Errorf("reference %q is not an https URL", ref)
+				}
+			}
+
+			// The bad example must trigger the rule and the good example must not
+			if got := countFindings(t, rule, explainer.BadExample()); got == 0 {
+				t.Errorf("BadExample: expected findings, got 0")
+			}
+			if got := countFindings(t, rule, explainer.GoodExample()); got != 0 {
+				t.Errorf("GoodExample: expected 0 findings, got %d", got)
+			}
+		})
+	}
+}
+
+// countFindings parses content and returns the number of findings of rule.
+func countFindings(t *testing.T, rule Rule, content string) int {
+	t.Helper()
+	dockerfile, err := parser.ParseString(content)
+	if err != nil {
+		t.Fatalf("failed to parse example: %v", err)
+	}
+	return len(rule.Check(dockerfile))
+}
+
+func TestRuleRegistry_FilterByCategory(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&SecretInEnvRule{})
+	registry.Register(&NoUserRule{})
+	registry.Register(&ConsecutiveRunRule{})
+	registry.Register(&uncategorizedRule{})
+
+	tests := []struct {
+		name     string
+		category string
+		expected []string
+	}{
+		{"security", CategorySecurity, []string{RuleSecretInEnv, RuleNoUser}},
+		{"performance", CategoryPerformance, []string{RuleConsecutiveRun}},
+		{"no matching rules", CategoryCorrectness, nil},
+		{"empty category excludes uncategorized rules", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := registry.FilterByCategory(tt.category)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d rules, got %d", len(tt.expected), len(got))
+			}
+			for i, rule := range got {
+				if rule.ID() != tt.expected[i] {
+					t.Errorf("rule %d: expected %s, got %s", i, tt.expected[i], rule.ID())
+				}
+			}
+		})
+	}
+}
+
+// stubMissingTagRule replaces MissingTagRule and reports every FROM.
+type stubMissingTagRule struct{}
+
+func (r *stubMissingTagRule) ID() string             { return RuleMissingTag }
+func (r *stubMissingTagRule) Name() string           { return "Stub" }
+func (r *stubMissingTagRule) Description() string    { return "Reports every FROM" }
+func (r *stubMissingTagRule) Severity() ast.Severity { return ast.SeverityError }
+func (r *stubMissingTagRule) Check(df *ast.Dockerfile) []ast.Finding {
+	var findings []ast.Finding
+	for _, from := range ast.FindFrom(df) {
+		findings = append(findings, ast.Finding{RuleID: r.ID(), Severity: r.Severity(), Line: from.Line(), Column: 1})
+	}
+	return findings
+}
+
+func TestRuleRegistry_UnregisterReplaceClone(t *testing.T) {
+	registry := DefaultRegistry.Clone()
+	count := DefaultRegistry.Count()
+
+	if !registry.Replace(&stubMissingTagRule{}) {
+		t.Fatal("Replace() = false, want true for a registered rule")
+	}
+	if _, ok := registry.Get(RuleMissingTag).(*stubMissingTagRule); !ok {
+		t.Errorf("Get(%s) = %T, want the stub", RuleMissingTag, registry.Get(RuleMissingTag))
+	}
+	if _, ok := DefaultRegistry.Get(RuleMissingTag).(*MissingTagRule); !ok {
+		t.Error("Replace() on a clone changed DefaultRegistry")
+	}
+	if registry.Replace(&uncategorizedRule{}) || registry.Get("X0001") != nil {
+		t.Error("Replace() added a rule that was not registered")
+	}
+
+	if !registry.Unregister(RuleLatestTag) {
+		t.Error("Unregister() = false, want true for a registered rule")
+	}
+	if registry.Unregister(RuleLatestTag) {
+		t.Error("Unregister() = true for a rule already removed")
+	}
+	if registry.Get(RuleLatestTag) != nil || DefaultRegistry.Get(RuleLatestTag) == nil {
+		t.Error("Unregister() should only remove the rule from the clone")
+	}
+	if registry.Count() != count-1 || DefaultRegistry.Count() != count {
+		t.Errorf("Count() = %d and %d, want %d and %d", registry.Count(), DefaultRegistry.Count(), count-1, count)
+	}
+
+	registry.Register(&uncategorizedRule{})
+	all := registry.All()
+	for i := 1; i < len(all); i++ {
+		if all[i-1].ID() >= all[i].ID() {
+			t.Fatalf("All() not sorted by ID: %s before %s", all[i-1].ID(), all[i].ID())
+		}
+	}
+}
+
+func TestRuleRegistry_Configure(t *testing.T) {
+	configured, err := DefaultRegistry.Configure(map[string]map[string]string{
+		RuleLargeBaseImage: {"large_images": "corp-base"},
+	})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	rule, ok := configured.Get(RuleLargeBaseImage).(*LargeBaseImageRule)
+	if !ok || rule.images["corp-base"] == "" {
+		t.Errorf("Get(%s) = %#v, want a configured LargeBaseImageRule", RuleLargeBaseImage, configured.Get(RuleLargeBaseImage))
+	}
+	if DefaultRegistry.Get(RuleLargeBaseImage).(*LargeBaseImageRule).images != nil {
+		t.Error("Configure() modified the rule in DefaultRegistry")
+	}
+	if configured.Count() != DefaultRegistry.Count() {
+		t.Errorf("Count() = %d, want %d", configured.Count(), DefaultRegistry.Count())
+	}
+
+	configured, err = DefaultRegistry.Configure(map[string]map[string]string{
+		"XX9999":           {"key": "value"},
+		RuleMultipleCMD:    {"key": "value"},
+		RuleLargeBaseImage: {"unknown": "value"},
+		RuleSecretInEnv:    {"additional_patterns": "(?i)dsn"},
+	})
+	for _, want := range []string{"unknown rule: XX9999", "rule DL3001 has no options", "rule DL3008: unknown option"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Configure() error = %v, want it to contain %q", err, want)
+		}
+	}
+	if rule := configured.Get(RuleSecretInEnv).(*SecretInEnvRule); len(rule.patterns) != 1 {
+		t.Error("valid options were not applied when other options failed")
+	}
+}
@@ -0,0 +1,29 @@
+package lint_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/devblac/docker-lint/lint"
+)
+
+func ExampleRun() {
+	dockerfile := `FROM ubuntu
+ENV API_KEY=secret123
+`
+
+	findings, err := lint.Run(strings.NewReader(dockerfile), lint.Options{
+		SelectRules: []string{"DL3006", "DL4000"},
+	})
+	if err != nil {
+		fmt.Println("parse error:", err)
+		return
+	}
+
+	for _, f := range findings {
+		fmt.Printf("%d: [%s] %s\n", f.Line, f.Severity, f.RuleID)
+	}
+	// Output:
+	// 1: [warning] DL3006
+	// 2: [warning] DL4000
+}
@@ -0,0 +1,218 @@
+// Package lint provides the public library entry point for docker-lint.
+//
+// It wraps Dockerfile parsing and rule analysis so that other Go programs can
+// embed docker-lint without shelling out to the CLI.
+package lint
+
+import (
+	"io"
+
+	"github.com/devblac/docker-lint/internal/analyzer"
+	"github.com/devblac/docker-lint/internal/ast"
+	"github.com/devblac/docker-lint/internal/parser"
+	"github.com/devblac/docker-lint/internal/rules"
+)
+
+// Finding represents a lint finding from rule analysis.
+type Finding = ast.Finding
+
+// Severity represents the severity level of a lint finding.
+type Severity = ast.Severity
+
+// Severity levels reported in findings.
+const (
+	SeverityInfo    = ast.SeverityInfo
+	SeverityWarning = ast.SeverityWarning
+	SeverityError   = ast.SeverityError
+)
+
+// ParseSeverity converts a severity name (info, warning or error) to a Severity.
+func ParseSeverity(s string) (Severity, error) {
+	return ast.ParseSeverity(s)
+}
+
+// Dockerfile represents a parsed Dockerfile, as passed to Rule.Check.
+type Dockerfile = ast.Dockerfile
+
+// Rule defines the interface that all lint rules must implement.
+type Rule = rules.Rule
+
+// Rule categories used to group rules.
+const (
+	CategorySecurity     = rules.CategorySecurity
+	CategoryPerformance  = rules.CategoryPerformance
+	CategoryBestPractice = rules.CategoryBestPractice
+	CategoryCorrectness  = rules.CategoryCorrectness
+)
+
+// Categories lists all rule categories.
+var Categories = rules.Categories
+
+// Categorizer is implemented by rules that belong to a category.
+type Categorizer = rules.Categorizer
+
+// Fixer is implemented by rules whose findings can be fixed automatically.
+type Fixer = rules.Fixer
+
+// TextFix is a text edit returned by a Fixer.
+type TextFix = rules.Fix
+
+// FixResult is the outcome of Fix.
+type FixResult = rules.FixResult
+
+// Explainer is implemented by rules that provide a long description, examples
+// and references.
+type Explainer = rules.Explainer
+
+// DocumentedRule is implemented by rules with a documentation page.
+type DocumentedRule = rules.DocumentedRule
+
+// LineLocalRule is implemented by rules whose findings for an instruction
+// depend only on that instruction.
+type LineLocalRule = rules.LineLocalRule
+
+// Configurable is implemented by rules that accept options; see
+// Options.RuleOptions.
+type Configurable = rules.Configurable
+
+// RuleOption documents an option accepted by a Configurable rule.
+type RuleOption = rules.RuleOption
+
+// CategoryOf returns the category of a rule, or "" if it has none.
+func CategoryOf(rule Rule) string {
+	return rules.CategoryOf(rule)
+}
+
+// Registry manages the collection of available lint rules.
+type Registry = rules.RuleRegistry
+
+// NewRegistry creates a new empty Registry for custom rule sets.
+func NewRegistry() *Registry {
+	return rules.NewRegistry()
+}
+
+// DefaultRegistry returns the registry containing all built-in rules.
+func DefaultRegistry() *Registry {
+	return rules.DefaultRegistry
+}
+
+// Options holds configuration options for a lint run.
+type Options struct {
+	// IgnoreRules is a list of rule IDs to skip during analysis.
+	IgnoreRules []string
+
+	// SelectRules is a list of rule IDs to run exclusively. When set, all other
+	// rules are skipped and IgnoreRules is applied within this subset.
+	SelectRules []string
+
+	// SelectCategories is a list of rule categories (see Categories) to run
+	// exclusively. It combines with SelectRules: a rule must satisfy both.
+	SelectCategories []string
+
+	// MinSeverity is the lowest severity reported. The zero value
+	// (SeverityInfo) reports all findings.
+	MinSeverity Severity
+
+	// SeverityOverrides maps rule IDs to the severity their findings are
+	// reported with, replacing the rule's default severity.
+	SeverityOverrides map[string]Severity
+
+	// SummarizeUnpinned reports the DL3006 and DL3007 findings of a
+	// Dockerfile as a single finding listing all unpinned base images, when
+	// there is more than one.
+	SummarizeUnpinned bool
+
+	// RuleOptions configures rules that implement Configurable, keyed by rule
+	// ID and then option key, e.g. {"DL3008": {"large_images": "ubuntu,node"}}.
+	// Run and Fix reject invalid options; see Validate.
+	RuleOptions map[string]map[string]string
+
+	// Registry is the set of rules to run. Defaults to DefaultRegistry() when nil.
+	Registry *Registry
+}
+
+// Validate checks RuleOptions against the rules of the registry. It reports
+// options for unknown rules, for rules without options and invalid values.
+func (o Options) Validate() error {
+	return o.config().Validate(o.registry())
+}
+
+// IsEnabled reports whether a rule would run under these options.
+func (o Options) IsEnabled(ruleID string) bool {
+	return o.analyzer().IsEnabled(ruleID)
+}
+
+// SeverityOf returns the severity findings of rule are reported with under
+// these options, taking SeverityOverrides into account.
+func (o Options) SeverityOf(rule Rule) Severity {
+	if severity, ok := o.SeverityOverrides[rule.ID()]; ok {
+		return severity
+	}
+	return rule.Severity()
+}
+
+// Run parses the Dockerfile read from r and returns the findings of all enabled
+// rules, sorted by line number and rule ID.
+func Run(r io.Reader, opts Options) ([]Finding, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	dockerfile, err := parser.ParseReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Analyze(dockerfile, opts), nil
+}
+
+// Analyze returns the findings of all enabled rules for an already parsed
+// Dockerfile, sorted by line number and rule ID.
+func Analyze(dockerfile *Dockerfile, opts Options) []Finding {
+	return opts.analyzer().Analyze(dockerfile)
+}
+
+// Fix reads a Dockerfile from r and applies the fixes of enabled rules that
+// support them. Only the fixed text changes; comments, blank lines and line
+// continuations are kept as they are. Findings that have to be fixed by hand
+// are listed in FixResult.Unfixable.
+func Fix(r io.Reader, opts Options) (*FixResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dockerfile, err := parser.ParseString(string(source))
+	if err != nil {
+		return nil, err
+	}
+
+	return opts.analyzer().Fix(source, dockerfile), nil
+}
+
+// analyzer creates an analyzer configured from the options.
+func (o Options) analyzer() *analyzer.Analyzer {
+	return analyzer.New(o.registry(), o.config())
+}
+
+// registry returns the registry to run, defaulting to DefaultRegistry().
+func (o Options) registry() *Registry {
+	if o.Registry == nil {
+		return rules.DefaultRegistry
+	}
+	return o.Registry
+}
+
+// config converts the options to an analyzer configuration.
+func (o Options) config() analyzer.Config {
+	return analyzer.Config{
+		IgnoreRules:       o.IgnoreRules,
+		SelectRules:       o.SelectRules,
+		SelectCategories:  o.SelectCategories,
+		MinSeverity:       o.MinSeverity,
+		SeverityOverrides: o.SeverityOverrides,
+		SummarizeUnpinned: o.SummarizeUnpinned,
+		RuleOptions:       o.RuleOptions,
+	}
+}
@@ -0,0 +1,238 @@
+package lint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/devblac/docker-lint/internal/formatter"
+	"github.com/devblac/docker-lint/internal/parser"
+	"github.com/devblac/docker-lint/internal/rules"
+)
+
+func TestRun_DefaultRegistry(t *testing.T) {
+	findings, err := Run(strings.NewReader("FROM ubuntu\n"), Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	hasMissingTag := false
+	for _, f := range findings {
+		if f.RuleID == rules.RuleMissingTag {
+			hasMissingTag = true
+		}
+	}
+	if !hasMissingTag {
+		t.Error("Expected DL3006 (missing tag) finding")
+	}
+}
+
+func TestAnalyze_MatchesRun(t *testing.T) {
+	const content = "FROM ubuntu\nRUN apt-get install curl\n"
+
+	dockerfile, err := parser.ParseString(content)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	want, err := Run(strings.NewReader(content), Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := Analyze(dockerfile, Options{})
+	if len(got) != len(want) {
+		t.Fatalf("Analyze() returned %d findings, Run() returned %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("finding %d: Analyze() = %+v, Run() = %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRun_IgnoreRules(t *testing.T) {
+	findings, err := Run(strings.NewReader("FROM ubuntu\n"), Options{
+		IgnoreRules: []string{rules.RuleMissingTag},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, f := range findings {
+		if f.RuleID == rules.RuleMissingTag {
+			t.Error("Expected DL3006 to be ignored but it was reported")
+		}
+	}
+}
+
+func TestRun_CustomRegistry(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&rules.MissingTagRule{})
+
+	findings, err := Run(strings.NewReader("FROM ubuntu\nRUN apt-get update\n"), Options{Registry: registry})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(findings) != 1 || findings[0].RuleID != rules.RuleMissingTag {
+		t.Errorf("Expected only a DL3006 finding from custom registry, got %v", findings)
+	}
+}
+
+func TestRun_ParseError(t *testing.T) {
+	findings, err := Run(strings.NewReader("FROM alpine:3.18\nNOTANINSTRUCTION foo\n"), Options{})
+	if err == nil {
+		t.Fatal("Run() expected parse error, got nil")
+	}
+	if findings != nil {
+		t.Errorf("Run() findings = %v, want nil on error", findings)
+	}
+}
+
+func TestOptions_IsEnabled(t *testing.T) {
+	opts := Options{
+		SelectRules: []string{rules.RuleMissingTag, rules.RuleLatestTag},
+		IgnoreRules: []string{rules.RuleLatestTag},
+	}
+
+	if !opts.IsEnabled(rules.RuleMissingTag) {
+		t.Error("Expected DL3006 to be enabled")
+	}
+	if opts.IsEnabled(rules.RuleLatestTag) {
+		t.Error("Expected DL3007 to be disabled by ignore")
+	}
+	if opts.IsEnabled(rules.RuleNoUser) {
+		t.Error("Expected DL4002 to be disabled by select")
+	}
+}
+
+func TestRun_MinSeverity(t *testing.T) {
+	findings, err := Run(strings.NewReader("FROM ubuntu\nEXPOSE 70000\n"), Options{
+		MinSeverity: SeverityError,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(findings) == 0 {
+		t.Fatal("Expected the invalid port error to be reported")
+	}
+	for _, f := range findings {
+		if f.Severity < SeverityError {
+			t.Errorf("Expected only errors, got %s %s", f.Severity, f.RuleID)
+		}
+	}
+}
+
+func TestRun_SeverityOverrides(t *testing.T) {
+	findings, err := Run(strings.NewReader("FROM ubuntu\n"), Options{
+		SelectRules:       []string{rules.RuleMissingTag},
+		SeverityOverrides: map[string]Severity{rules.RuleMissingTag: SeverityError},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Severity != SeverityError {
+		t.Fatalf("Expected one DL3006 error, got %v", findings)
+	}
+
+	var text bytes.Buffer
+	if err := formatter.NewTextFormatter("Dockerfile", false).Format(findings, &text); err != nil {
+		t.Fatalf("text Format() error = %v", err)
+	}
+	if !strings.Contains(text.String(), "[error] DL3006") {
+		t.Errorf("Expected text output to report DL3006 as error, got:\n%s", text.String())
+	}
+
+	var jsonOut bytes.Buffer
+	if err := formatter.NewJSONFormatter("Dockerfile", false).Format(findings, &jsonOut); err != nil {
+		t.Fatalf("JSON Format() error = %v", err)
+	}
+	if !strings.Contains(jsonOut.String(), `"severity": "error"`) || !strings.Contains(jsonOut.String(), `"errors": 1`) {
+		t.Errorf("Expected JSON output to report DL3006 as error, got:\n%s", jsonOut.String())
+	}
+}
+
+func TestRun_RuleOptions(t *testing.T) {
+	opts := Options{
+		SelectRules: []string{rules.RuleSecretInEnv},
+		RuleOptions: map[string]map[string]string{rules.RuleSecretInEnv: {"additional_patterns": "(?i)_dsn$"}},
+	}
+	findings, err := Run(strings.NewReader("FROM alpine:3.18\nENV SENTRY_DSN=x\n"), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(findings) != 1 {
+		t.Errorf("Expected one DL4000 finding, got %v", findings)
+	}
+
+	opts.RuleOptions = map[string]map[string]string{rules.RuleSecretInEnv: {"additional_patterns": "("}}
+	if _, err := Run(strings.NewReader("FROM alpine:3.18\n"), opts); err == nil {
+		t.Error("Run() with an invalid pattern: expected error")
+	}
+	if err := opts.Validate(); err == nil {
+		t.Error("Validate() with an invalid pattern: expected error")
+	}
+}
+
+func TestOptions_SeverityOf(t *testing.T) {
+	rule := rules.DefaultRegistry.Get(rules.RuleMissingTag)
+	opts := Options{SeverityOverrides: map[string]Severity{rules.RuleMissingTag: SeverityInfo}}
+
+	if got := opts.SeverityOf(rule); got != SeverityInfo {
+		t.Errorf("SeverityOf() = %s, want info", got)
+	}
+	if got := (Options{}).SeverityOf(rule); got != rule.Severity() {
+		t.Errorf("SeverityOf() = %s, want default %s", got, rule.Severity())
+	}
+}
+
+func TestFix_RoundTrip(t *testing.T) {
+	input := `# syntax=docker/dockerfile:1
+FROM golang:1.22 AS build
+WORKDIR src
+ADD . .
+
+FROM alpine:3.18
+ADD app.tar.gz /
+ADD --chown=app bin/app \
+    /usr/local/bin/
+WORKDIR ./data
+`
+	expected := `# syntax=docker/dockerfile:1
+FROM golang:1.22 AS build
+WORKDIR /src
+COPY . .
+
+FROM alpine:3.18
+ADD app.tar.gz /
+COPY --chown=app bin/app \
+    /usr/local/bin/
+WORKDIR /data
+`
+	fixable := []string{rules.RuleRelativeWorkdir, rules.RuleAddOverCopy}
+
+	result, err := Fix(strings.NewReader(input), Options{})
+	if err != nil {
+		t.Fatalf("Fix() error = %v", err)
+	}
+	if result.Fixed != 4 {
+		t.Errorf("Fix() fixed %d findings, want 4", result.Fixed)
+	}
+	if string(result.Source) != expected {
+		t.Errorf("Fix() =\n%s\nwant\n%s", result.Source, expected)
+	}
+
+	findings, err := Run(strings.NewReader(string(result.Source)), Options{SelectRules: fixable})
+	if err != nil {
+		t.Fatalf("Run(fixed) error = %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected fixed Dockerfile to lint clean, got %v", findings)
+	}
+
+	// Fixing again is a no-op
+	if again, _ := Fix(strings.NewReader(string(result.Source)), Options{}); again.Fixed != 0 {
+		t.Errorf("second Fix() fixed %d findings, want 0", again.Fixed)
+	}
+}